@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"errors"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -39,7 +40,9 @@ const (
 	ModelaPhaseInstallingDatabase      = "InstallingSystemDatabase"
 	ModelaPhaseInstallingModela        = "InstallingModela"
 	ModelaPhaseInstallingTenant        = "InstallingTenant"
+	ModelaPhaseMigratingSchema         = "MigratingSchema"
 	ModelaPhaseReady                   = "Ready"
+	ModelaPhaseDegraded                = "Degraded"
 	ModelaPhaseUninstalling            = "UninstallingComponent"
 	ModelaPhaseFailed                  = "Failed"
 )
@@ -79,6 +82,26 @@ type ModelaCondition struct {
 	Message string `json:"message,omitempty"`
 }
 
+const (
+	// VaultSealed indicates if a server of the Vault used by Modela is sealed
+	VaultSealed ModelaConditionType = "VaultSealed"
+	// PlatformConfigured indicates if the platform configuration is valid and applied to the modela-config ConfigMap
+	PlatformConfigured ModelaConditionType = "PlatformConfigured"
+	// TenantsDegraded indicates if a connection of any tenant is missing
+	TenantsDegraded ModelaConditionType = "TenantsDegraded"
+	// ExternalServicesReachable indicates if the preflight checks of the external databases and object storage passed
+	ExternalServicesReachable ModelaConditionType = "ExternalServicesReachable"
+	// PgvectorReady indicates if the pgvector extension is available in Postgres and created in the database of
+	// every tenant with the version provided by the pgvector image
+	PgvectorReady ModelaConditionType = "PgvectorReady"
+	// DatabaseReplicationHealthy indicates if every replica of the highly available databases is replicating from
+	// the primary, with the configured number of synchronous Postgres replicas
+	DatabaseReplicationHealthy ModelaConditionType = "DatabaseReplicationHealthy"
+	// SchemaMigrated indicates if the schemas of the databases were migrated to the distribution of Modela. The
+	// images of a new distribution are only rolled out once its migration has succeeded.
+	SchemaMigrated ModelaConditionType = "SchemaMigrated"
+)
+
 // Unstructured values for rendering Helm Charts
 // +k8s:deepcopy-gen=false
 type ChartValues struct {
@@ -215,25 +238,239 @@ type CertManagerSpec struct {
 	Values ChartValues `json:"values,omitempty"`
 }
 
+// VaultKeyCustodyBackend specifies where the unseal keys of a Vault server will be kept after initialization
+type VaultKeyCustodyBackend string
+
+const (
+	// VaultKeyCustodyKubernetesSecret stores the unseal keys in plaintext inside the vault-keys Secret
+	VaultKeyCustodyKubernetesSecret VaultKeyCustodyBackend = "KubernetesSecret"
+	// VaultKeyCustodySealedFile encrypts the unseal keys with a public key and stores the resulting file in a Secret
+	VaultKeyCustodySealedFile VaultKeyCustodyBackend = "SealedFile"
+	// VaultKeyCustodyTransit delegates unsealing to the transit secret engine of another Vault server
+	VaultKeyCustodyTransit VaultKeyCustodyBackend = "Transit"
+)
+
+// SealedFileEncryption is the encryption format of a sealed key file
+type SealedFileEncryption string
+
+const (
+	SealedFileEncryptionAge SealedFileEncryption = "age"
+	SealedFileEncryptionPGP SealedFileEncryption = "pgp"
+)
+
+// SealedFileCustodySpec defines the configuration to encrypt Vault unseal keys with a public key
+type SealedFileCustodySpec struct {
+	// Encryption specifies the format used to encrypt the unseal keys
+	// +kubebuilder:validation:Enum=age;pgp
+	// +kubebuilder:default:="age"
+	Encryption SealedFileEncryption `json:"encryption,omitempty"`
+
+	// PublicKeys contains the age recipients (age1...) or armored PGP public keys which the unseal keys will be
+	// encrypted to. Any of the corresponding private keys can be used to decrypt the sealed file.
+	// +kubebuilder:validation:MinItems=1
+	PublicKeys []string `json:"publicKeys"`
+
+	// SecretName is the name of the Secret in the modela-system namespace that will contain the sealed file
+	// +kubebuilder:default:="vault-sealed-keys"
+	// +kubebuilder:validation:Optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// TransitSealSpec defines the configuration to auto-unseal Vault through the transit engine of another Vault server
+type TransitSealSpec struct {
+	// Address is the address of the Vault server which hosts the transit secret engine
+	// +kubebuilder:validation:Required
+	Address string `json:"address"`
+
+	// MountPath is the mount path of the transit secret engine
+	// +kubebuilder:default:="transit/"
+	// +kubebuilder:validation:Optional
+	MountPath string `json:"mountPath,omitempty"`
+
+	// KeyName is the name of the transit key used to encrypt the master key
+	// +kubebuilder:default:="modela-autounseal"
+	// +kubebuilder:validation:Optional
+	KeyName string `json:"keyName,omitempty"`
+
+	// TokenSecretRef references the key of a Secret in the modela-system namespace which contains a token with
+	// permissions to encrypt and decrypt with the transit key
+	// +kubebuilder:validation:Required
+	TokenSecretRef v1.SecretKeySelector `json:"tokenSecretRef"`
+
+	// TLSSkipVerify disables verification of the TLS certificate presented by the transit Vault server
+	// +kubebuilder:validation:Optional
+	TLSSkipVerify bool `json:"tlsSkipVerify,omitempty"`
+}
+
+// VaultSnapshotDestination specifies where snapshots of Vault are stored
+type VaultSnapshotDestination string
+
+const (
+	// VaultSnapshotObjectStorage stores snapshots in a bucket of the bundled Minio server
+	VaultSnapshotObjectStorage VaultSnapshotDestination = "ObjectStorage"
+	// VaultSnapshotPersistentVolume stores snapshots in a persistent volume claim in the modela-system namespace
+	VaultSnapshotPersistentVolume VaultSnapshotDestination = "PersistentVolume"
+)
+
+// VaultSnapshotSpec defines the schedule and destination of the snapshots of the Raft storage of Vault
+type VaultSnapshotSpec struct {
+	// Schedule is the cron schedule on which snapshots are taken. Snapshots are disabled when empty.
+	// +kubebuilder:validation:Optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Destination specifies where snapshots are stored
+	// +kubebuilder:validation:Enum=ObjectStorage;PersistentVolume
+	// +kubebuilder:default:="ObjectStorage"
+	// +kubebuilder:validation:Optional
+	Destination VaultSnapshotDestination `json:"destination,omitempty"`
+
+	// Bucket is the name of the Minio bucket which stores snapshots when using the ObjectStorage destination
+	// +kubebuilder:default:="vault-snapshots"
+	// +kubebuilder:validation:Optional
+	Bucket string `json:"bucket,omitempty"`
+
+	// StorageSize is the size of the persistent volume claim which stores snapshots when using the
+	// PersistentVolume destination
+	// +kubebuilder:default:="10Gi"
+	// +kubebuilder:validation:Optional
+	StorageSize resource.Quantity `json:"storageSize,omitempty"`
+
+	// StorageClassName is the storage class of the persistent volume claim which stores snapshots
+	// +kubebuilder:validation:Optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// Retention is the number of snapshots which are retained. Older snapshots are deleted after each snapshot.
+	// +kubebuilder:default:=7
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	Retention int `json:"retention,omitempty"`
+}
+
+// VaultHASpec defines the configuration of Vault in high-availability mode
+type VaultHASpec struct {
+	// Enabled indicates if Vault should be installed in high-availability mode with integrated Raft storage.
+	// The storage of an existing Vault installation cannot be changed, so it must be enabled before Vault is installed.
+	// +kubebuilder:default:=false
+	// +kubebuilder:validation:Optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Replicas is the number of Vault servers. Servers other than the first are joined to the Raft cluster
+	// once the first server has been initialized.
+	// +kubebuilder:default:=3
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	Replicas int `json:"replicas,omitempty"`
+
+	// Snapshots specifies the schedule, destination and retention of the snapshots of the Raft storage
+	// +kubebuilder:validation:Optional
+	Snapshots VaultSnapshotSpec `json:"snapshots,omitempty"`
+
+	// RestoreSnapshot is the file name of a snapshot, stored in the snapshot destination, which will be restored
+	// into Vault. The restore is performed once for each new value, and the restored snapshot is recorded in the
+	// status of the Modela resource. Restoring a snapshot replaces every secret stored in Vault.
+	// +kubebuilder:validation:Optional
+	RestoreSnapshot string `json:"restoreSnapshot,omitempty"`
+}
+
+// VaultTLSSpec defines how the Modela Operator and the Modela workloads connect to Vault over TLS
+type VaultTLSSpec struct {
+	// Enabled indicates if Vault is served over TLS. When Vault is installed by the Modela Operator, a certificate
+	// for the Vault service is issued through cert-manager and the listener of the Vault server is configured to
+	// use it. Enabling TLS on the bundled Vault requires cert-manager to be installed.
+	// +kubebuilder:default:=false
+	// +kubebuilder:validation:Optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// CASecretRef references the key of a Secret in the modela-system namespace which contains the PEM-encoded
+	// CA bundle used to verify the certificate of the Vault server. When Vault is installed by the Modela Operator,
+	// the CA of the certificate issued through cert-manager is used by default.
+	// +kubebuilder:validation:Optional
+	CASecretRef *v1.SecretKeySelector `json:"caSecretRef,omitempty"`
+
+	// ClientCertificateSecretRef references a Secret of type kubernetes.io/tls in the modela-system namespace
+	// which contains the client certificate presented to Vault, for servers which require mutual TLS
+	// +kubebuilder:validation:Optional
+	ClientCertificateSecretRef *v1.LocalObjectReference `json:"clientCertificateSecretRef,omitempty"`
+
+	// ServerName is the name used as the SNI host when connecting to Vault, if it differs from the host
+	// of the Vault address
+	// +kubebuilder:validation:Optional
+	ServerName string `json:"serverName,omitempty"`
+
+	// InsecureSkipVerify disables verification of the certificate presented by Vault. It should only be used for testing.
+	// +kubebuilder:validation:Optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// VaultKeyCustodySpec defines how the unseal keys of Vault are kept once it has been initialized
+type VaultKeyCustodySpec struct {
+	// Backend specifies the key custody backend
+	// +kubebuilder:validation:Enum=KubernetesSecret;SealedFile;Transit
+	// +kubebuilder:default:="KubernetesSecret"
+	// +kubebuilder:validation:Optional
+	Backend VaultKeyCustodyBackend `json:"backend,omitempty"`
+
+	// SealedFile contains the configuration for the SealedFile backend
+	// +kubebuilder:validation:Optional
+	SealedFile *SealedFileCustodySpec `json:"sealedFile,omitempty"`
+
+	// Transit contains the configuration for the Transit backend
+	// +kubebuilder:validation:Optional
+	Transit *TransitSealSpec `json:"transit,omitempty"`
+}
+
 type VaultSpec struct {
 	// Indicates if Vault should be installed. Enabling installation will initialize Vault on the modela-system
-	// namespace and configure it with the appropriate secret engine and policies. This option is not recommended
-	// for production environments as the root token and vault keys will be stored inside Kubernetes secrets.
-	// When installed this way, the Modela Operator will automatically unseal the Vault when necessary.
+	// namespace and configure it with the appropriate secret engine and policies. The root token is revoked once
+	// Vault has been configured, and the custody of the unseal keys is determined by KeyCustody.
+	// When installed this way, the Modela Operator will automatically unseal the Vault when the unseal keys
+	// are retrievable by the operator.
 	// +kubebuilder:default:=true
 	// +kubebuilder:validation:Optional
 	Install bool `json:"install"`
 
+	// SecretShares is the number of key shares the master key will be split into when Vault is initialized.
+	// When using the Transit key custody backend, it determines the number of recovery key shares.
+	// +kubebuilder:default:=1
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	SecretShares int `json:"secretShares,omitempty"`
+
+	// SecretThreshold is the number of key shares required to unseal Vault. It must be less than or equal
+	// to SecretShares.
+	// +kubebuilder:default:=1
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	SecretThreshold int `json:"secretThreshold,omitempty"`
+
+	// KeyCustody specifies how the unseal keys produced by the initialization of Vault will be stored
+	// +kubebuilder:validation:Optional
+	KeyCustody VaultKeyCustodySpec `json:"keyCustody,omitempty"`
+
 	// MountPath specifies the path where secrets consumed by Modela will be stored.
 	// +kubebuilder:default:="modela/secrets"
 	MountPath string `json:"mountPath,omitempty"`
 
 	// VaultAddress specifies the address for an external Vault server. If specified, the Vault server
-	// must be configured with a KVv2 secret engine mounted at MountPath. It must also be configured to
-	// authorize the modela-operator-controller-manager ServiceAccount with read/write permissions
+	// must be configured with a KVv2 secret engine mounted at MountPath. It must also have a Kubernetes auth
+	// role named modela-operator which authorizes the modela-operator-controller-manager ServiceAccount to write
+	// the jwt-secret and tenant/*/accounts, connections and api-key-secret paths of the mount
 	// +kubebuilder:validation:Optional
 	VaultAddress *string `json:"vaultAddress,omitempty"`
 
+	// HA specifies the configuration of Vault in high-availability mode
+	// +kubebuilder:validation:Optional
+	HA VaultHASpec `json:"ha,omitempty"`
+
+	// TLS specifies the TLS configuration used to connect to Vault
+	// +kubebuilder:validation:Optional
+	TLS VaultTLSSpec `json:"tls,omitempty"`
+
+	// Namespace specifies the Vault Enterprise namespace which contains the secret engine and authentication
+	// method used by Modela. It only applies to external Vault servers.
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+
 	// ChartValues is the set of Helm values that are used to render the Vault Chart.
 	// +kubebuilder:pruning:PreserveUnknownFields
 	// +kubebuilder:validation:Optional
@@ -248,6 +485,115 @@ type ObjectStorageSpec struct {
 
 	// ChartValues is the set of Helm values that is used to render the Minio Chart.
 	Values ChartValues `json:"values,omitempty"`
+
+	// External configures an externally managed object storage, such as S3 or GCS, which is used in place of
+	// the bundled MinIO. The bucket of each tenant is created inside the external object storage.
+	// +kubebuilder:validation:Optional
+	External *ExternalObjectStorageSpec `json:"external,omitempty"`
+}
+
+// ExternalTLSSpec defines how the Modela Operator and the Modela workloads connect to an external service over TLS
+type ExternalTLSSpec struct {
+	// Enabled indicates if the external service is accessed over TLS
+	// +kubebuilder:default:=false
+	// +kubebuilder:validation:Optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// CASecretRef references the key of a Secret in the modela-system namespace which contains the PEM-encoded
+	// CA bundle used to verify the certificate of the service. The system CA bundle is used by default.
+	// +kubebuilder:validation:Optional
+	CASecretRef *v1.SecretKeySelector `json:"caSecretRef,omitempty"`
+}
+
+// ExternalServiceSpec defines an externally managed service which is used in place of a bundled chart
+type ExternalServiceSpec struct {
+	// Host is the host name or IP address of the service
+	// +kubebuilder:validation:MinLength=1
+	Host string `json:"host"`
+
+	// Port is the port of the service. The default port of the service is used if unset.
+	// +kubebuilder:validation:Optional
+	Port int32 `json:"port,omitempty"`
+
+	// TLS configures the TLS connection to the service
+	// +kubebuilder:validation:Optional
+	TLS ExternalTLSSpec `json:"tls,omitempty"`
+
+	// CredentialsSecretRef references a Secret in the modela-system namespace which contains the admin credentials
+	// of the service, under the username and password keys for databases, or the accessKey and secretKey keys for
+	// object storage. The credentials are used to create the database, user or bucket of each tenant.
+	CredentialsSecretRef v1.LocalObjectReference `json:"credentialsSecretRef"`
+}
+
+// ExternalDatabaseSpec defines the externally managed databases used in place of the bundled databases
+type ExternalDatabaseSpec struct {
+	// Postgres configures a managed PostgreSQL server, such as Amazon RDS or Cloud SQL
+	// +kubebuilder:validation:Optional
+	Postgres *ExternalServiceSpec `json:"postgres,omitempty"`
+
+	// MongoDB configures a MongoDB-compatible server
+	// +kubebuilder:validation:Optional
+	MongoDB *ExternalServiceSpec `json:"mongoDB,omitempty"`
+}
+
+// ObjectStorageProvider is the provider of an external object storage
+type ObjectStorageProvider string
+
+const (
+	ObjectStorageProviderAWS   ObjectStorageProvider = "aws"
+	ObjectStorageProviderGCP   ObjectStorageProvider = "gcp"
+	ObjectStorageProviderMinio ObjectStorageProvider = "minio"
+)
+
+// ExternalObjectStorageSpec defines an externally managed object storage
+type ExternalObjectStorageSpec struct {
+	ExternalServiceSpec `json:",inline"`
+
+	// Provider is the provider of the object storage. GCS is accessed through its S3-compatible API with HMAC keys.
+	// Only a MinIO object storage supports dedicated access keys for each tenant; the tenants of other providers
+	// share the credentials of the object storage.
+	// +kubebuilder:default:="aws"
+	// +kubebuilder:validation:Enum=aws;gcp;minio
+	// +kubebuilder:validation:Optional
+	Provider ObjectStorageProvider `json:"provider,omitempty"`
+
+	// Region is the region of the buckets
+	// +kubebuilder:validation:Optional
+	Region string `json:"region,omitempty"`
+
+	// BucketPrefix is prepended to the name of the bucket of each tenant, as bucket names are globally unique
+	// with most providers
+	// +kubebuilder:validation:Optional
+	BucketPrefix string `json:"bucketPrefix,omitempty"`
+}
+
+// DatabaseHASpec defines the highly available topologies of the bundled Postgres and MongoDB
+type DatabaseHASpec struct {
+	// Enabled indicates if Postgres should be installed with streaming replication to read replicas, and MongoDB as
+	// a replica set. The topology of an existing database cannot be changed, so it must be enabled before the
+	// databases are installed.
+	// +kubebuilder:default:=false
+	// +kubebuilder:validation:Optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Replicas is the number of servers of each database, including the primary. Defaults to
+	// DefaultDatabaseReplicas.
+	// +kubebuilder:default:=3
+	// +kubebuilder:validation:Minimum=2
+	// +kubebuilder:validation:Optional
+	Replicas int `json:"replicas,omitempty"`
+
+	// SynchronousReplicas is the number of Postgres read replicas which must confirm each transaction before it is
+	// committed. Replication is asynchronous when set to zero. It must be lower than the number of replicas.
+	// +kubebuilder:default:=1
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Optional
+	SynchronousReplicas *int `json:"synchronousReplicas,omitempty"`
+
+	// ReplicaSetName is the name of the MongoDB replica set
+	// +kubebuilder:default:="rs0"
+	// +kubebuilder:validation:Optional
+	ReplicaSetName string `json:"replicaSetName,omitempty"`
 }
 
 type DatabaseSpec struct {
@@ -262,6 +608,13 @@ type DatabaseSpec struct {
 	// +kubebuilder:validation:Optional
 	InstallPgvector bool `json:"installPgvector"`
 
+	// PgvectorImage is the image of Postgres with pgvector installed by the bundled Postgres chart, including its
+	// registry and tag or digest. The version of the extension created in the databases of the tenants is verified
+	// against the version in the tag of the image. Changing the image upgrades the running Postgres release.
+	// +kubebuilder:default:="docker.io/ankane/pgvector:v0.5.1"
+	// +kubebuilder:validation:Optional
+	PgvectorImage string `json:"pgvectorImage,omitempty"`
+
 	// InstallMongoDB indicates if MongoDB will be installed.
 	// MongoDB is a required component of the Modela LLM RAG engine.
 	// +kubebuilder:default:=true
@@ -272,6 +625,16 @@ type DatabaseSpec struct {
 	// +kubebuilder:pruning:PreserveUnknownFields
 	// +kubebuilder:validation:Optional
 	MongoDBValues ChartValues `json:"mongoDBValues,omitempty"`
+
+	// External configures externally managed databases. The bundled chart of each database configured as
+	// external is not installed, and the database of each tenant is created inside the external database.
+	// +kubebuilder:validation:Optional
+	External ExternalDatabaseSpec `json:"external,omitempty"`
+
+	// HA configures the bundled databases in highly available topologies. The tenants connect to the primary
+	// Postgres server and to the MongoDB replica set.
+	// +kubebuilder:validation:Optional
+	HA DatabaseHASpec `json:"ha,omitempty"`
 }
 
 type OnlineStoreSpec struct {
@@ -284,6 +647,27 @@ type OnlineStoreSpec struct {
 	// +kubebuilder:pruning:PreserveUnknownFields
 	// +kubebuilder:validation:Optional
 	Values ChartValues `json:"values,omitempty"`
+
+	// External configures an externally managed Redis, such as Amazon ElastiCache or Azure Cache for Redis, which
+	// is used by the online store in place of the bundled Redis chart
+	// +kubebuilder:validation:Optional
+	External *ExternalRedisSpec `json:"external,omitempty"`
+}
+
+// ExternalRedisSpec defines an externally managed Redis
+type ExternalRedisSpec struct {
+	// Address is the host and port of the Redis server. The port defaults to 6379 when the address only contains
+	// the host.
+	// +kubebuilder:validation:MinLength=1
+	Address string `json:"address"`
+
+	// TLS configures the TLS connection to the Redis server
+	// +kubebuilder:validation:Optional
+	TLS ExternalTLSSpec `json:"tls,omitempty"`
+
+	// CredentialsSecretRef references a Secret in the modela-system namespace which contains the password of the
+	// Redis server under the password key and, for servers with ACL users, the name of the user under the username key
+	CredentialsSecretRef v1.LocalObjectReference `json:"credentialsSecretRef"`
 }
 
 type ObservabilitySpec struct {
@@ -330,10 +714,414 @@ type TenantSpec struct {
 	// +kubebuilder:validation:Required
 	Name string `json:"name,omitempty"`
 
-	// The password for the default admin account (with the username "admin"). If empty, then the
-	// Modela Operator will set the password to "default". Setting a secure password is highly recommended.
+	TenantConfig `json:",inline"`
+}
+
+// TenantConfig defines the configuration of a tenant, which is shared by the tenants of the Modela resource
+// and ModelaTenant resources
+type TenantConfig struct {
+	// The password for the default admin account (with the username "admin"). Deprecated: the password is stored in
+	// plain text inside the resource; use AdminPasswordSecretRef instead.
 	// +kubebuilder:validation:Optional
 	AdminPassword *string `json:"adminPassword,omitempty"`
+
+	// AdminPasswordSecretRef references the key of a Secret, in the namespace of the Modela resource, which contains
+	// the password of the default admin account. If neither AdminPasswordSecretRef nor AdminPassword are set, the
+	// Modela Operator will generate a random password and store it under the "password" key of the
+	// <tenant>-admin-password Secret. The password is hashed again whenever the Secret changes.
+	// +kubebuilder:validation:Optional
+	AdminPasswordSecretRef *v1.SecretKeySelector `json:"adminPasswordSecretRef,omitempty"`
+
+	// PasswordHashCost is the bcrypt cost used to hash the passwords of the tenant
+	// +kubebuilder:default:=10
+	// +kubebuilder:validation:Minimum=4
+	// +kubebuilder:validation:Maximum=31
+	// +kubebuilder:validation:Optional
+	PasswordHashCost int `json:"passwordHashCost,omitempty"`
+
+	// DeletionPolicy determines what happens to the namespace of the tenant when the tenant is removed. By default,
+	// the namespace is retained and orphaned. The namespace is only deleted when the policy is Delete, or when the
+	// deletion is confirmed through an annotation.
+	// +kubebuilder:default:="Retain"
+	// +kubebuilder:validation:Optional
+	DeletionPolicy TenantDeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// Suspended freezes the tenant without removing its namespace. While suspended, the workloads of the tenant
+	// are scaled to zero, new pods are blocked by a ResourceQuota and the Vault role of the tenant is disabled.
+	// Resuming the tenant restores the previous replicas of each workload.
+	// +kubebuilder:validation:Optional
+	Suspended bool `json:"suspended,omitempty"`
+
+	// Accounts contains the user accounts of the tenant, in addition to the default admin account. Accounts which
+	// are removed from the list are deleted from the tenant along with their password.
+	// +kubebuilder:validation:Optional
+	Accounts []TenantAccountSpec `json:"accounts,omitempty"`
+
+	// Quota limits the compute resources, storage and objects which may be consumed by the tenant. When set, the
+	// Modela Operator will maintain a ResourceQuota and LimitRange inside the namespace of the tenant.
+	// +kubebuilder:validation:Optional
+	Quota *TenantQuotaSpec `json:"quota,omitempty"`
+
+	// Labs contains the labs of the tenant, which execute the training workloads of its data products. When empty,
+	// a single lab named <tenant>-lab is created.
+	// +kubebuilder:validation:Optional
+	Labs []TenantLabSpec `json:"labs,omitempty"`
+
+	// ServingSites contains the serving sites of the tenant, which host its predictors and data apps. When empty,
+	// a single serving site named <tenant>-serving-site is created.
+	// +kubebuilder:validation:Optional
+	ServingSites []TenantServingSiteSpec `json:"servingSites,omitempty"`
+}
+
+// TenantDeletionPolicy determines what happens to the namespace of a removed tenant
+// +kubebuilder:validation:Enum=Retain;Delete
+type TenantDeletionPolicy string
+
+const (
+	// TenantDeletionPolicyRetain orphans the namespace and secrets of the tenant, which can be re-adopted during
+	// the grace period of the Modela resource
+	TenantDeletionPolicyRetain TenantDeletionPolicy = "Retain"
+	// TenantDeletionPolicyDelete deletes the namespace and secrets of the tenant
+	TenantDeletionPolicyDelete TenantDeletionPolicy = "Delete"
+)
+
+const (
+	// ConfirmTenantDeletionAnnotation is set on a Modela resource to confirm the deletion of the namespaces of its
+	// removed tenants. The value is a comma-separated list of tenant names.
+	ConfirmTenantDeletionAnnotation = "management.modela.ai/confirm-tenant-deletion"
+	// ConfirmDeletionAnnotation is set to "true" on a ModelaTenant resource to confirm the deletion of its namespace
+	ConfirmDeletionAnnotation = "management.modela.ai/confirm-deletion"
+)
+
+// TenantAccountSpec defines a user account of a tenant
+type TenantAccountSpec struct {
+	// Username is the name of the account, which is used to log in
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern="^[a-z0-9]([-a-z0-9]*[a-z0-9])?$"
+	// +kubebuilder:validation:MaxLength=63
+	Username string `json:"username"`
+
+	// Email is the email address of the account
+	// +kubebuilder:validation:Optional
+	Email string `json:"email,omitempty"`
+
+	// FirstName is the first name of the account holder
+	// +kubebuilder:validation:Optional
+	FirstName string `json:"firstName,omitempty"`
+
+	// LastName is the last name of the account holder
+	// +kubebuilder:validation:Optional
+	LastName string `json:"lastName,omitempty"`
+
+	// RoleClass is the name of the UserRoleClass granted to the account in the tenant
+	// +kubebuilder:default:="administrator"
+	// +kubebuilder:validation:Optional
+	RoleClass string `json:"roleClass,omitempty"`
+
+	// PasswordSecretRef references the key of a Secret, in the namespace of the Modela resource, which contains
+	// the password of the account. The password is hashed again whenever the Secret changes.
+	// +kubebuilder:validation:Optional
+	PasswordSecretRef *v1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+
+	// SSOOnly indicates that the account may only log in through single sign-on, in which case no password is stored
+	// +kubebuilder:validation:Optional
+	SSOOnly bool `json:"ssoOnly,omitempty"`
+}
+
+// TenantQuotaSpec defines the quota profile of a tenant. Fields which are not set are not limited.
+type TenantQuotaSpec struct {
+	// CPU is the total amount of CPU which may be requested and consumed by the pods of the tenant
+	// +kubebuilder:validation:Optional
+	CPU *resource.Quantity `json:"cpu,omitempty"`
+
+	// Memory is the total amount of memory which may be requested and consumed by the pods of the tenant
+	// +kubebuilder:validation:Optional
+	Memory *resource.Quantity `json:"memory,omitempty"`
+
+	// GPU is the total number of NVIDIA GPUs which may be requested by the pods of the tenant
+	// +kubebuilder:validation:Optional
+	GPU *resource.Quantity `json:"gpu,omitempty"`
+
+	// Storage is the total storage which may be requested by the persistent volume claims of the tenant
+	// +kubebuilder:validation:Optional
+	Storage *resource.Quantity `json:"storage,omitempty"`
+
+	// PersistentVolumeClaims is the maximum number of persistent volume claims of the tenant
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	PersistentVolumeClaims *int64 `json:"persistentVolumeClaims,omitempty"`
+
+	// Pods is the maximum number of pods of the tenant
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	Pods *int64 `json:"pods,omitempty"`
+
+	// Services is the maximum number of services of the tenant
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	Services *int64 `json:"services,omitempty"`
+
+	// ConfigMaps is the maximum number of config maps of the tenant
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	ConfigMaps *int64 `json:"configMaps,omitempty"`
+
+	// Secrets is the maximum number of secrets of the tenant
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	Secrets *int64 `json:"secrets,omitempty"`
+
+	// Limits defines the default and maximum resources of each container of the tenant. The defaults are applied
+	// to containers which do not specify resources, which would otherwise be rejected by the CPU and memory quotas.
+	// +kubebuilder:validation:Optional
+	Limits TenantLimitRangeSpec `json:"limits,omitempty"`
+}
+
+// TenantLimitRangeSpec defines the LimitRange of a tenant namespace
+type TenantLimitRangeSpec struct {
+	// DefaultCPU is the CPU limit of containers which do not specify one. Defaults to 1.
+	// +kubebuilder:validation:Optional
+	DefaultCPU *resource.Quantity `json:"defaultCpu,omitempty"`
+
+	// DefaultMemory is the memory limit of containers which do not specify one. Defaults to 1Gi.
+	// +kubebuilder:validation:Optional
+	DefaultMemory *resource.Quantity `json:"defaultMemory,omitempty"`
+
+	// DefaultRequestCPU is the CPU request of containers which do not specify one. Defaults to 100m.
+	// +kubebuilder:validation:Optional
+	DefaultRequestCPU *resource.Quantity `json:"defaultRequestCpu,omitempty"`
+
+	// DefaultRequestMemory is the memory request of containers which do not specify one. Defaults to 128Mi.
+	// +kubebuilder:validation:Optional
+	DefaultRequestMemory *resource.Quantity `json:"defaultRequestMemory,omitempty"`
+
+	// MaxCPU is the maximum CPU limit of a single container
+	// +kubebuilder:validation:Optional
+	MaxCPU *resource.Quantity `json:"maxCpu,omitempty"`
+
+	// MaxMemory is the maximum memory limit of a single container
+	// +kubebuilder:validation:Optional
+	MaxMemory *resource.Quantity `json:"maxMemory,omitempty"`
+}
+
+// TenantLabSpec defines a lab of a tenant
+type TenantLabSpec struct {
+	// Name is the name of the Lab resource
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern="^[a-z0-9]([-a-z0-9]*[a-z0-9])?$"
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// Default indicates if the lab is the default lab of the DataProducts of the tenant. If no lab is marked as the
+	// default, the first lab is used.
+	// +kubebuilder:validation:Optional
+	Default bool `json:"default,omitempty"`
+
+	// WorkloadClasses contains the names of the WorkloadClasses which may be used by the workloads of the lab. The
+	// first class of the default lab is used for the training workloads of the default DataProduct.
+	// +kubebuilder:validation:Optional
+	WorkloadClasses []string `json:"workloadClasses,omitempty"`
+
+	// Limits specifies the hard resource limits of the workloads created under the lab
+	// +kubebuilder:validation:Optional
+	Limits *TenantWorkspaceLimitSpec `json:"limits,omitempty"`
+}
+
+// TenantServingSiteSpec defines a serving site of a tenant
+type TenantServingSiteSpec struct {
+	// Name is the name of the ServingSite resource
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern="^[a-z0-9]([-a-z0-9]*[a-z0-9])?$"
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// Default indicates if the serving site is the default serving site of the DataProducts of the tenant. If no
+	// serving site is marked as the default, the first serving site is used.
+	// +kubebuilder:validation:Optional
+	Default bool `json:"default,omitempty"`
+
+	// WorkloadClasses contains the names of the WorkloadClasses which may be used by the workloads of the serving
+	// site. The first class of the default serving site is used for the serving workloads of the default DataProduct.
+	// +kubebuilder:validation:Optional
+	WorkloadClasses []string `json:"workloadClasses,omitempty"`
+
+	// Limits specifies the hard resource limits of the workloads created under the serving site
+	// +kubebuilder:validation:Optional
+	Limits *TenantWorkspaceLimitSpec `json:"limits,omitempty"`
+
+	// FQDN is the fully-qualified domain name of the ingress of the serving site. Defaults to serving.localhost.
+	// +kubebuilder:validation:Optional
+	FQDN string `json:"fqdn,omitempty"`
+}
+
+// TenantWorkspaceLimitSpec defines the hard resource limits of a lab or serving site
+type TenantWorkspaceLimitSpec struct {
+	// CPU is the maximum amount of CPU which may be consumed by the workloads
+	// +kubebuilder:validation:Optional
+	CPU *resource.Quantity `json:"cpu,omitempty"`
+
+	// Memory is the maximum amount of memory which may be consumed by the workloads
+	// +kubebuilder:validation:Optional
+	Memory *resource.Quantity `json:"memory,omitempty"`
+
+	// Pods is the maximum number of pods which may be created by the workloads
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	Pods *int32 `json:"pods,omitempty"`
+}
+
+// TenantAdminPasswordStatus reports the version of the admin password applied to a tenant
+type TenantAdminPasswordStatus struct {
+	// Tenant is the name of the tenant. It is only set for the tenants of a Modela resource.
+	// +kubebuilder:validation:Optional
+	Tenant string `json:"tenant,omitempty"`
+
+	// Version identifies the source of the applied password and the hash cost, such as the resource version of
+	// the referenced Secret. The password is hashed again when the version changes.
+	Version string `json:"version"`
+
+	// The last time the password hash was written to the secret store
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+}
+
+// TenantQuotaStatus reports the quota and the resources consumed by a tenant
+type TenantQuotaStatus struct {
+	// Tenant is the name of the tenant. It is only set for the tenants of a Modela resource.
+	// +kubebuilder:validation:Optional
+	Tenant string `json:"tenant,omitempty"`
+
+	// Hard contains the limits enforced by the ResourceQuota of the tenant
+	// +kubebuilder:validation:Optional
+	Hard v1.ResourceList `json:"hard,omitempty"`
+
+	// Used contains the resources currently consumed by the tenant
+	// +kubebuilder:validation:Optional
+	Used v1.ResourceList `json:"used,omitempty"`
+}
+
+// TenantConnectionStatus reports the credentials of the system components propagated to the connections of a tenant
+type TenantConnectionStatus struct {
+	// Tenant is the name of the tenant. It is only set for the tenants of a Modela resource.
+	// +kubebuilder:validation:Optional
+	Tenant string `json:"tenant,omitempty"`
+
+	// Version identifies the resource versions of the component Secrets propagated to the tenant. The connections
+	// of the tenant are updated when the version changes.
+	// +kubebuilder:validation:Optional
+	Version string `json:"version,omitempty"`
+
+	// Missing contains the names of the connections whose component Secret does not exist yet
+	// +kubebuilder:validation:Optional
+	Missing []string `json:"missing,omitempty"`
+
+	// The last time the connections of the tenant were updated
+	// +kubebuilder:validation:Optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// SecretStoreType specifies the backend which stores the secrets generated by the Modela Operator
+type SecretStoreType string
+
+const (
+	// SecretStoreVault stores secrets in the KVv2 secret engine of Vault
+	SecretStoreVault SecretStoreType = "Vault"
+	// SecretStoreKubernetes stores secrets as Kubernetes Secrets in the namespace of their tenant
+	SecretStoreKubernetes SecretStoreType = "Kubernetes"
+	// SecretStoreExternalSecrets stores secrets in an external provider through the External Secrets Operator
+	SecretStoreExternalSecrets SecretStoreType = "ExternalSecrets"
+)
+
+// ExternalSecretsStoreSpec defines how secrets are stored through the External Secrets Operator
+type ExternalSecretsStoreSpec struct {
+	// ClusterSecretStoreName is the name of the ClusterSecretStore of the External Secrets Operator which secrets
+	// are pushed to and synchronized from. The provider of the store must support PushSecret.
+	// +kubebuilder:validation:Required
+	ClusterSecretStoreName string `json:"clusterSecretStoreName"`
+
+	// RemotePrefix is the prefix of the keys of the secrets in the external provider
+	// +kubebuilder:default:="modela"
+	// +kubebuilder:validation:Optional
+	RemotePrefix string `json:"remotePrefix,omitempty"`
+
+	// RefreshInterval is the interval at which secrets are synchronized with the external provider
+	// +kubebuilder:default:="1h"
+	// +kubebuilder:validation:Optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+}
+
+// SecretStoreSpec defines where the secrets generated by the Modela Operator, such as the credentials of
+// tenant connections, are stored
+type SecretStoreSpec struct {
+	// Type specifies the secret store. When using the Vault store, Vault must either be installed or configured
+	// through the address of an external server.
+	// +kubebuilder:validation:Enum=Vault;Kubernetes;ExternalSecrets
+	// +kubebuilder:default:="Vault"
+	// +kubebuilder:validation:Optional
+	Type SecretStoreType `json:"type,omitempty"`
+
+	// ExternalSecrets contains the configuration of the ExternalSecrets store
+	// +kubebuilder:validation:Optional
+	ExternalSecrets *ExternalSecretsStoreSpec `json:"externalSecrets,omitempty"`
+}
+
+// PlatformConfigSpec defines the configuration of the Modela platform, which is reconciled into the modela-config
+// ConfigMap read by the control plane, data plane and API gateway. The control plane, data plane and API gateway are
+// restarted when the configuration changes.
+type PlatformConfigSpec struct {
+	// ImagePullPolicy is the pull policy of the images of the workloads created by the platform
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	// +kubebuilder:default:="IfNotPresent"
+	// +kubebuilder:validation:Optional
+	ImagePullPolicy v1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// CachePath is the absolute path of the local data cache of the platform
+	// +kubebuilder:default:="/var/opt/modela/data"
+	// +kubebuilder:validation:Pattern="^/"
+	// +kubebuilder:validation:Optional
+	CachePath string `json:"cachePath,omitempty"`
+
+	// VaultAddress is the address of the Vault server used by the platform. Defaults to the address of the Vault
+	// installed by the operator, or spec.vault.vaultAddress.
+	// +kubebuilder:validation:Optional
+	VaultAddress string `json:"vaultAddress,omitempty"`
+
+	// VaultMountPath is the mount path of the KVv2 secret engine used by the platform. Defaults to
+	// spec.vault.mountPath.
+	// +kubebuilder:validation:Optional
+	VaultMountPath string `json:"vaultMountPath,omitempty"`
+
+	// Extra contains additional keys of the platform configuration, which may not override the keys managed
+	// through the other fields
+	// +kubebuilder:validation:Optional
+	Extra map[string]string `json:"extra,omitempty"`
+}
+
+// SecretRotationSpec defines the intervals at which the credentials managed by the Modela Operator are rotated.
+// The credentials of a component will not be rotated if its interval is not specified.
+type SecretRotationSpec struct {
+	// JwtSecret is the interval at which the secret used to sign authentication tokens is rotated
+	// +kubebuilder:validation:Optional
+	JwtSecret *metav1.Duration `json:"jwtSecret,omitempty"`
+
+	// ApiKeySecret is the interval at which the API key secret of each tenant is rotated
+	// +kubebuilder:validation:Optional
+	ApiKeySecret *metav1.Duration `json:"apiKeySecret,omitempty"`
+
+	// Postgres is the interval at which the password of the Postgres superuser is rotated
+	// +kubebuilder:validation:Optional
+	Postgres *metav1.Duration `json:"postgres,omitempty"`
+
+	// Mongo is the interval at which the password of the MongoDB root user is rotated
+	// +kubebuilder:validation:Optional
+	Mongo *metav1.Duration `json:"mongo,omitempty"`
+
+	// Redis is the interval at which the password of the online store is rotated
+	// +kubebuilder:validation:Optional
+	Redis *metav1.Duration `json:"redis,omitempty"`
+
+	// ObjectStorage is the interval at which the root password of Minio is rotated
+	// +kubebuilder:validation:Optional
+	ObjectStorage *metav1.Duration `json:"objectStorage,omitempty"`
 }
 
 // ModelaSpec defines the desired state of Modela
@@ -358,6 +1146,13 @@ type ModelaSpec struct {
 	//+kubebuilder:validation:Optional
 	Tenants []*TenantSpec `json:"tenants,omitempty"`
 
+	// TenantGracePeriod is the duration for which the namespace and secrets of a retained tenant are kept by the
+	// operator. Re-adding the tenant during the grace period re-adopts its namespace and secrets. Once the grace
+	// period expires, the secrets of the tenant are destroyed and the namespace is left unmanaged.
+	// +kubebuilder:default:="168h"
+	//+kubebuilder:validation:Optional
+	TenantGracePeriod *metav1.Duration `json:"tenantGracePeriod,omitempty"`
+
 	//+kubebuilder:validation:Optional
 	CertManager CertManagerSpec `json:"certManager,omitempty"`
 
@@ -381,6 +1176,81 @@ type ModelaSpec struct {
 
 	//+kubebuilder:validation:Optional
 	Vault VaultSpec `json:"vault,omitempty"`
+
+	// SecretStore specifies the backend which stores the secrets generated by the operator
+	//+kubebuilder:validation:Optional
+	SecretStore SecretStoreSpec `json:"secretStore,omitempty"`
+
+	// SecretRotation specifies the configuration to periodically rotate the credentials managed by the operator
+	//+kubebuilder:validation:Optional
+	SecretRotation SecretRotationSpec `json:"secretRotation,omitempty"`
+
+	// Backup specifies the schedule of backups of the installation. When set, the operator manages a ModelaBackup
+	// named after the Modela resource which takes backups according to the policy.
+	//+kubebuilder:validation:Optional
+	Backup *BackupPolicySpec `json:"backup,omitempty"`
+
+	// PlatformConfig specifies the configuration of the Modela platform, which is stored in the modela-config
+	// ConfigMap
+	//+kubebuilder:validation:Optional
+	PlatformConfig PlatformConfigSpec `json:"platformConfig,omitempty"`
+}
+
+// SecretRotationStatus records the last rotation of a credential
+type SecretRotationStatus struct {
+	// Name of the rotated credential
+	Name string `json:"name"`
+	// The last time the credential was rotated
+	LastRotationTime metav1.Time `json:"lastRotationTime"`
+}
+
+// PgvectorStatus describes the pgvector extension created in the databases of the tenants
+type PgvectorStatus struct {
+	// Image is the pgvector image of the bootstrapped Postgres server. It is not set for an external server.
+	// +kubebuilder:validation:Optional
+	Image string `json:"image,omitempty"`
+
+	// Version is the version of the extension available in the Postgres server
+	// +kubebuilder:validation:Optional
+	Version string `json:"version,omitempty"`
+
+	// Databases contains the names of the databases in which the extension was created
+	// +kubebuilder:validation:Optional
+	Databases []string `json:"databases,omitempty"`
+
+	// The last time the extension was bootstrapped
+	// +kubebuilder:validation:Optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// SchemaMigrationStatus describes the migration of the database schemas to a distribution of Modela
+type SchemaMigrationStatus struct {
+	// Distribution is the distribution to which the schemas are migrated
+	Distribution string `json:"distribution"`
+
+	// FromDistribution is the distribution which was installed when the migration started
+	// +kubebuilder:validation:Optional
+	FromDistribution string `json:"fromDistribution,omitempty"`
+
+	// Image is the migration image, taken from the ManagedImage catalog of the distribution
+	// +kubebuilder:validation:Optional
+	Image string `json:"image,omitempty"`
+
+	// Job is the name of the migration job
+	// +kubebuilder:validation:Optional
+	Job string `json:"job,omitempty"`
+
+	// The time at which the migration job was created
+	// +kubebuilder:validation:Optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// The time at which the migration job succeeded
+	// +kubebuilder:validation:Optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// FailureMessage describes why the migration job failed
+	// +kubebuilder:validation:Optional
+	FailureMessage string `json:"failureMessage,omitempty"`
 }
 
 // ModelaStatus defines the observed state of Modela
@@ -402,6 +1272,55 @@ type ModelaStatus struct {
 
 	Phase ModelaPhase `json:"phase,omitempty"`
 
+	// SecretRotations contains the last rotation time of each credential rotated by the operator
+	//+kubebuilder:validation:Optional
+	SecretRotations []SecretRotationStatus `json:"secretRotations,omitempty"`
+
+	// RestoredVaultSnapshot is the name of the last Vault snapshot restored by the operator
+	//+kubebuilder:validation:Optional
+	RestoredVaultSnapshot string `json:"restoredVaultSnapshot,omitempty"`
+
+	// RetainedTenants contains the names of the removed tenants whose namespace is retained during the grace period
+	//+kubebuilder:validation:Optional
+	RetainedTenants []string `json:"retainedTenants,omitempty"`
+
+	// SuspendedTenants contains the names of the suspended tenants
+	//+kubebuilder:validation:Optional
+	SuspendedTenants []string `json:"suspendedTenants,omitempty"`
+
+	// TenantAdminPasswords contains the version of the admin password applied to each tenant
+	//+kubebuilder:validation:Optional
+	TenantAdminPasswords []TenantAdminPasswordStatus `json:"tenantAdminPasswords,omitempty"`
+
+	// TenantQuotas contains the quota usage of each tenant with a quota profile
+	//+kubebuilder:validation:Optional
+	TenantQuotas []TenantQuotaStatus `json:"tenantQuotas,omitempty"`
+
+	// TenantConnections contains the state of the connections of each tenant
+	//+kubebuilder:validation:Optional
+	TenantConnections []TenantConnectionStatus `json:"tenantConnections,omitempty"`
+
+	// PlatformConfigVersion is the checksum of the platform configuration applied to the modela-config ConfigMap
+	//+kubebuilder:validation:Optional
+	PlatformConfigVersion string `json:"platformConfigVersion,omitempty"`
+
+	// Pgvector describes the pgvector extension bootstrapped in Postgres
+	//+kubebuilder:validation:Optional
+	Pgvector *PgvectorStatus `json:"pgvector,omitempty"`
+
+	// SchemaVersion is the version of the database schemas, as reported by the last successful migration job
+	//+kubebuilder:validation:Optional
+	SchemaVersion string `json:"schemaVersion,omitempty"`
+
+	// SchemaMigration describes the last migration of the database schemas
+	//+kubebuilder:validation:Optional
+	SchemaMigration *SchemaMigrationStatus `json:"schemaMigration,omitempty"`
+
+	// DatabaseHA records if the bundled databases were installed as highly available, which cannot be changed once
+	// they are installed
+	//+kubebuilder:validation:Optional
+	DatabaseHA *bool `json:"databaseHA,omitempty"`
+
 	// The Modela resource controller will update FailureMessage with an error message in the case of a failure
 	FailureMessage *string `json:"failureMessage,omitempty"`
 
@@ -435,6 +1354,33 @@ type ModelaList struct {
 	Items           []Modela `json:"items"`
 }
 
+// GetCondition returns the condition of the given type, or nil if the condition has not been set
+func (m *Modela) GetCondition(conditionType ModelaConditionType) *ModelaCondition {
+	for i := range m.Status.Conditions {
+		if m.Status.Conditions[i].Type == conditionType {
+			return &m.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition creates or updates a condition. The transition time is only updated when the status changes.
+func (m *Modela) SetCondition(conditionType ModelaConditionType, status ConditionStatus, reason string, message string) {
+	condition := m.GetCondition(conditionType)
+	if condition == nil {
+		m.Status.Conditions = append(m.Status.Conditions, ModelaCondition{Type: conditionType})
+		condition = &m.Status.Conditions[len(m.Status.Conditions)-1]
+	}
+
+	if condition.Status != status {
+		now := metav1.Now()
+		condition.LastTransitionTime = &now
+	}
+	condition.Status = status
+	condition.Reason = reason
+	condition.Message = message
+}
+
 func init() {
 	SchemeBuilder.Register(&Modela{}, &ModelaList{})
 }
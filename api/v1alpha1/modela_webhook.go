@@ -17,10 +17,13 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
 	"k8s.io/apimachinery/pkg/runtime"
+	"net"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"strconv"
 )
 
 // log is for logging in this package.
@@ -49,14 +52,16 @@ var _ webhook.Validator = &Modela{}
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (r *Modela) ValidateCreate() error {
 	modelalog.Info("validate create", "name", r.Name)
-
-	return nil
+	return r.validate()
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
-func (r *Modela) ValidateUpdate(_ runtime.Object) error {
+func (r *Modela) ValidateUpdate(old runtime.Object) error {
 	modelalog.Info("validate update", "name", r.Name)
-	return nil
+	if old, ok := old.(*Modela); ok && old.Status.DatabaseHA != nil && *old.Status.DatabaseHA != r.Spec.Database.HA.Enabled {
+		return fmt.Errorf("spec.database.ha.enabled cannot be changed once the databases are installed")
+	}
+	return r.validate()
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -64,3 +69,58 @@ func (r *Modela) ValidateDelete() error {
 	modelalog.Info("validate delete", "name", r.Name)
 	return nil
 }
+
+func (r *Modela) validate() error {
+	if err := r.Spec.Database.HA.Validate(); err != nil {
+		return err
+	}
+	if external := r.Spec.OnlineStore.External; external != nil {
+		if _, _, err := external.HostPort(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DefaultDatabaseReplicas is the number of servers of each highly available database when the spec does not set
+// a valid number
+const DefaultDatabaseReplicas = 3
+
+// ReplicaCount returns the number of servers of each highly available database, including the primary. A
+// database needs at least two servers to replicate, so the count defaults to DefaultDatabaseReplicas otherwise.
+func (s DatabaseHASpec) ReplicaCount() int {
+	if s.Replicas > 1 {
+		return s.Replicas
+	}
+	return DefaultDatabaseReplicas
+}
+
+// Validate checks that the number of synchronous Postgres replicas is lower than the number of servers
+func (s DatabaseHASpec) Validate() error {
+	if !s.Enabled || s.SynchronousReplicas == nil {
+		return nil
+	}
+	replicas := s.ReplicaCount()
+	if *s.SynchronousReplicas >= replicas {
+		return fmt.Errorf("spec.database.ha.synchronousReplicas must be lower than spec.database.ha.replicas (%d)", replicas)
+	}
+	return nil
+}
+
+// DefaultRedisPort is the port of an external Redis whose address does not set one
+const DefaultRedisPort = "6379"
+
+// HostPort returns the host and port of the address of the Redis server. The port defaults to DefaultRedisPort.
+func (s ExternalRedisSpec) HostPort() (string, string, error) {
+	host, port, err := net.SplitHostPort(s.Address)
+	if err != nil {
+		host, port, err = net.SplitHostPort(s.Address + ":" + DefaultRedisPort)
+	}
+	if err != nil || host == "" {
+		return "", "", fmt.Errorf("spec.onlineStore.external.address %s must be a host, optionally followed by a port", s.Address)
+	}
+	if number, err := strconv.Atoi(port); err != nil || number < 1 || number > 65535 {
+		return "", "", fmt.Errorf("spec.onlineStore.external.address %s has an invalid port %s", s.Address, port)
+	}
+	return host, port, nil
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BackupTargetSpec defines the S3-compatible object storage which stores backups
+type BackupTargetSpec struct {
+	// Endpoint is the URL of the object storage, such as https://s3.us-east-1.amazonaws.com. If empty, backups are
+	// stored in the bundled Minio server.
+	// +kubebuilder:validation:Optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Bucket is the name of the bucket which stores backups. The bucket is created if it does not exist.
+	// +kubebuilder:default:="modela-backups"
+	// +kubebuilder:validation:Optional
+	Bucket string `json:"bucket,omitempty"`
+
+	// Region is the region of the bucket
+	// +kubebuilder:validation:Optional
+	Region string `json:"region,omitempty"`
+
+	// CredentialsSecretRef references a Secret in the modela-system namespace with the accessKey and secretKey
+	// keys. If empty, the root credentials of the bundled Minio server are used.
+	// +kubebuilder:validation:Optional
+	CredentialsSecretRef *v1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+
+	// CASecretRef references the key of a Secret in the modela-system namespace which contains the PEM-encoded
+	// CA bundle used to verify the certificate of the object storage
+	// +kubebuilder:validation:Optional
+	CASecretRef *v1.SecretKeySelector `json:"caSecretRef,omitempty"`
+}
+
+// BackupRetentionSpec defines how many backups are retained. Backups are deleted, along with their data in the
+// object storage, when either limit is exceeded.
+type BackupRetentionSpec struct {
+	// MaxBackups is the number of backups which are retained
+	// +kubebuilder:default:=7
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Optional
+	MaxBackups int32 `json:"maxBackups,omitempty"`
+
+	// MaxAge is the duration for which backups are retained. Backups are retained regardless of their age if empty.
+	// +kubebuilder:validation:Optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+}
+
+// BackupPolicySpec defines the schedule, target and retention of backups
+type BackupPolicySpec struct {
+	// Schedule is the cron schedule on which backups are taken
+	// +kubebuilder:validation:Required
+	Schedule string `json:"schedule"`
+
+	// Suspended indicates if scheduled backups are suspended
+	// +kubebuilder:default:=false
+	// +kubebuilder:validation:Optional
+	Suspended bool `json:"suspended,omitempty"`
+
+	// Target specifies the object storage which stores backups
+	// +kubebuilder:validation:Optional
+	Target BackupTargetSpec `json:"target,omitempty"`
+
+	// Retention specifies how many backups are retained
+	// +kubebuilder:validation:Optional
+	Retention BackupRetentionSpec `json:"retention,omitempty"`
+}
+
+// ModelaBackupSpec defines the desired state of a ModelaBackup
+type ModelaBackupSpec struct {
+	// ModelaRef references the Modela installation which is backed up
+	// +kubebuilder:validation:Optional
+	ModelaRef ModelaReference `json:"modelaRef,omitempty"`
+
+	BackupPolicySpec `json:",inline"`
+}
+
+// ModelaBackupStatus defines the observed state of a ModelaBackup
+type ModelaBackupStatus struct {
+	// LastScheduleTime is the last time a backup was scheduled
+	// +kubebuilder:validation:Optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastSuccessfulTime is the last time a backup completed successfully
+	// +kubebuilder:validation:Optional
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+
+	// ActiveRun is the name of the ModelaBackupRun which is in progress, if any
+	// +kubebuilder:validation:Optional
+	ActiveRun string `json:"activeRun,omitempty"`
+
+	// Runs contains the names of the retained ModelaBackupRuns, from the newest to the oldest
+	// +kubebuilder:validation:Optional
+	Runs []string `json:"runs,omitempty"`
+
+	// ObservedGeneration is the last generation of the backup reconciled by the operator
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// The ModelaBackup resource controller will update FailureMessage with an error message in the case of a failure
+	// +kubebuilder:validation:Optional
+	FailureMessage *string `json:"failureMessage,omitempty"`
+}
+
+// ModelaBackup takes scheduled backups of a Modela installation into S3-compatible object storage. Each backup is
+// recorded as a ModelaBackupRun.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=modelabackups,singular=modelabackup,shortName="mb",categories={modela,all}
+// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule"
+// +kubebuilder:printcolumn:name="Suspended",type="boolean",JSONPath=".spec.suspended"
+// +kubebuilder:printcolumn:name="Last Schedule",type="date",JSONPath=".status.lastScheduleTime"
+// +kubebuilder:printcolumn:name="Last Successful",type="date",JSONPath=".status.lastSuccessfulTime"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type ModelaBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ModelaBackupSpec   `json:"spec,omitempty"`
+	Status ModelaBackupStatus `json:"status,omitempty"`
+}
+
+// ModelaBackupList contains a list of ModelaBackup
+// +kubebuilder:object:root=true
+type ModelaBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModelaBackup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ModelaBackup{}, &ModelaBackupList{})
+}
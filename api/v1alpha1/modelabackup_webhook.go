@@ -0,0 +1,74 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var modelabackuplog = logf.Log.WithName("modelabackup-resource")
+
+func (r *ModelaBackup) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-management-modela-ai-v1alpha1-modelabackup,mutating=true,failurePolicy=fail,sideEffects=None,groups=management.modela.ai,resources=modelabackups,verbs=create;update,versions=v1alpha1,name=mmodelabackup.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &ModelaBackup{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *ModelaBackup) Default() {
+	modelabackuplog.Info("default", "name", r.Name)
+}
+
+//+kubebuilder:webhook:path=/validate-management-modela-ai-v1alpha1-modelabackup,mutating=false,failurePolicy=fail,sideEffects=None,groups=management.modela.ai,resources=modelabackups,verbs=create;update,versions=v1alpha1,name=vmodelabackup.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &ModelaBackup{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ModelaBackup) ValidateCreate() error {
+	modelabackuplog.Info("validate create", "name", r.Name)
+	return r.Spec.Validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ModelaBackup) ValidateUpdate(_ runtime.Object) error {
+	modelabackuplog.Info("validate update", "name", r.Name)
+	return r.Spec.Validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ModelaBackup) ValidateDelete() error {
+	modelabackuplog.Info("validate delete", "name", r.Name)
+	return nil
+}
+
+// Validate returns an error if the schedule of the backup policy is not a valid cron schedule
+func (p BackupPolicySpec) Validate() error {
+	if _, err := cron.ParseStandard(p.Schedule); err != nil {
+		return fmt.Errorf("invalid backup schedule %q: %v", p.Schedule, err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The current phase of a ModelaBackupRun, or of a component backed up by it
+type BackupRunPhase string
+
+const (
+	BackupRunPhasePending   BackupRunPhase = "Pending"
+	BackupRunPhaseRunning   BackupRunPhase = "Running"
+	BackupRunPhaseCompleted BackupRunPhase = "Completed"
+	BackupRunPhaseFailed    BackupRunPhase = "Failed"
+)
+
+// ModelaBackupRunSpec defines the desired state of a ModelaBackupRun
+type ModelaBackupRunSpec struct {
+	// BackupRef is the name of the ModelaBackup which scheduled the run, if any
+	// +kubebuilder:validation:Optional
+	BackupRef string `json:"backupRef,omitempty"`
+
+	// ModelaRef references the Modela installation which is backed up
+	// +kubebuilder:validation:Optional
+	ModelaRef ModelaReference `json:"modelaRef,omitempty"`
+
+	// Target specifies the object storage which stores the backup
+	// +kubebuilder:validation:Optional
+	Target BackupTargetSpec `json:"target,omitempty"`
+}
+
+// BackupComponentStatus reports the backup of a single component
+type BackupComponentStatus struct {
+	// Name is the name of the component, such as postgres, mongodb, vault, resources or buckets
+	Name string `json:"name"`
+
+	// Phase is the phase of the backup of the component
+	Phase BackupRunPhase `json:"phase"`
+
+	// Message contains the reason of a failure
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+}
+
+// ModelaBackupRunStatus defines the observed state of a ModelaBackupRun
+type ModelaBackupRunStatus struct {
+	// Phase is the current phase of the backup
+	// +kubebuilder:validation:Optional
+	Phase BackupRunPhase `json:"phase,omitempty"`
+
+	// Folder is the folder of the target bucket which contains the backup
+	// +kubebuilder:validation:Optional
+	Folder string `json:"folder,omitempty"`
+
+	// Distribution is the distribution of Modela installed when the backup was taken
+	// +kubebuilder:validation:Optional
+	Distribution string `json:"distribution,omitempty"`
+
+	// Components reports the backup of each component
+	// +kubebuilder:validation:Optional
+	Components []BackupComponentStatus `json:"components,omitempty"`
+
+	// StartedAt is the time at which the backup started
+	// +kubebuilder:validation:Optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// CompletedAt is the time at which the backup completed or failed
+	// +kubebuilder:validation:Optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+
+	// The ModelaBackupRun resource controller will update FailureMessage with an error message in the case of a failure
+	// +kubebuilder:validation:Optional
+	FailureMessage *string `json:"failureMessage,omitempty"`
+}
+
+// ModelaBackupRun is a single backup of a Modela installation, taken on the schedule of a ModelaBackup or created
+// on demand. Deleting the resource deletes the backup from the object storage.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=modelabackupruns,singular=modelabackuprun,shortName="mbr",categories={modela,all}
+// +kubebuilder:printcolumn:name="Backup",type="string",JSONPath=".spec.backupRef"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Folder",type="string",JSONPath=".status.folder"
+// +kubebuilder:printcolumn:name="Distribution",type="string",JSONPath=".status.distribution"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type ModelaBackupRun struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ModelaBackupRunSpec   `json:"spec,omitempty"`
+	Status ModelaBackupRunStatus `json:"status,omitempty"`
+}
+
+// ModelaBackupRunList contains a list of ModelaBackupRun
+// +kubebuilder:object:root=true
+type ModelaBackupRunList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModelaBackupRun `json:"items"`
+}
+
+// Finished returns true if the backup has completed or failed
+func (r *ModelaBackupRun) Finished() bool {
+	return r.Status.Phase == BackupRunPhaseCompleted || r.Status.Phase == BackupRunPhaseFailed
+}
+
+func init() {
+	SchemeBuilder.Register(&ModelaBackupRun{}, &ModelaBackupRunList{})
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The current phase of a ModelaRestore
+type RestorePhase string
+
+const (
+	RestorePhasePending    RestorePhase = "Pending"
+	RestorePhaseValidating RestorePhase = "Validating"
+	// RestorePhaseValidated is the final phase of a dry-run whose backup passed validation
+	RestorePhaseValidated RestorePhase = "Validated"
+	RestorePhaseRestoring RestorePhase = "Restoring"
+	RestorePhaseCompleted RestorePhase = "Completed"
+	RestorePhaseFailed    RestorePhase = "Failed"
+)
+
+const (
+	// BackupValidated indicates if the checksums of the backup match its contents, and if the backup is compatible
+	// with the distribution of the running installation
+	BackupValidated ModelaConditionType = "BackupValidated"
+	// PrerequisitesInstalled indicates if the installation is ready and its workloads are paused for the restore
+	PrerequisitesInstalled ModelaConditionType = "PrerequisitesInstalled"
+	// VaultRestored indicates if the snapshot of Vault was restored and Vault was unsealed
+	VaultRestored ModelaConditionType = "VaultRestored"
+	// DatabasesRestored indicates if Postgres, MongoDB and the buckets of the object storage were restored
+	DatabasesRestored ModelaConditionType = "DatabasesRestored"
+	// ResourcesRestored indicates if the tenants and their Modela API resources were re-applied
+	ResourcesRestored ModelaConditionType = "ResourcesRestored"
+)
+
+// RestoreInProgressAnnotation is set on a Modela resource while a ModelaRestore restores it. The value is the
+// namespace and name of the ModelaRestore. The Modela resource and its tenants are not reconciled while it is set.
+const RestoreInProgressAnnotation = "management.modela.ai/restore"
+
+// RestoreSourceSpec specifies the backup which is restored
+type RestoreSourceSpec struct {
+	// BackupRunRef is the name of a completed ModelaBackupRun in the namespace of the restore. The folder and target
+	// of the backup are taken from the run.
+	// +kubebuilder:validation:Optional
+	BackupRunRef string `json:"backupRunRef,omitempty"`
+
+	// Target specifies the object storage which stores the backup, when BackupRunRef is not set. This allows a backup
+	// to be restored into another cluster, where its ModelaBackupRun does not exist.
+	// +kubebuilder:validation:Optional
+	Target BackupTargetSpec `json:"target,omitempty"`
+
+	// Folder is the folder of the target bucket which contains the backup, when BackupRunRef is not set
+	// +kubebuilder:validation:Optional
+	Folder string `json:"folder,omitempty"`
+}
+
+// ModelaRestoreSpec defines the desired state of a ModelaRestore
+type ModelaRestoreSpec struct {
+	// ModelaRef references the Modela installation which is restored
+	// +kubebuilder:validation:Optional
+	ModelaRef ModelaReference `json:"modelaRef,omitempty"`
+
+	// Source specifies the backup which is restored
+	// +kubebuilder:validation:Required
+	Source RestoreSourceSpec `json:"source"`
+
+	// DryRun only validates the integrity of the backup and its compatibility with the distribution of the
+	// installation, without restoring it
+	// +kubebuilder:default:=false
+	// +kubebuilder:validation:Optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// Tenants limits the namespaces whose Modela API resources are re-applied to the namespaces of the listed
+	// tenants. All namespaces contained in the backup are restored by default.
+	// +kubebuilder:validation:Optional
+	Tenants []string `json:"tenants,omitempty"`
+
+	// VaultUnsealKeysSecretRef references a Secret in the modela-system namespace which contains the unseal keys
+	// (key-0, key-1, ...) of the Vault which was backed up. The keys are handed to the key custody backend of the
+	// installation after the snapshot is restored. When not set, the restored Vault is expected to be unsealed with
+	// the keys held by the backend, which is the case when a backup is restored into the same installation.
+	// +kubebuilder:validation:Optional
+	VaultUnsealKeysSecretRef *v1.LocalObjectReference `json:"vaultUnsealKeysSecretRef,omitempty"`
+}
+
+// RestoredBackupStatus describes the backup read from the manifest of the restored folder
+type RestoredBackupStatus struct {
+	// Folder is the folder of the target bucket which contains the backup
+	// +kubebuilder:validation:Optional
+	Folder string `json:"folder,omitempty"`
+
+	// Modela is the name of the Modela resource which was backed up
+	// +kubebuilder:validation:Optional
+	Modela string `json:"modela,omitempty"`
+
+	// Distribution is the distribution of Modela installed when the backup was taken
+	// +kubebuilder:validation:Optional
+	Distribution string `json:"distribution,omitempty"`
+
+	// Components contains the names of the components contained in the backup
+	// +kubebuilder:validation:Optional
+	Components []string `json:"components,omitempty"`
+
+	// CreatedAt is the time at which the backup was taken
+	// +kubebuilder:validation:Optional
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+}
+
+// ModelaRestoreStatus defines the observed state of a ModelaRestore
+type ModelaRestoreStatus struct {
+	// Phase is the current phase of the restore
+	// +kubebuilder:validation:Optional
+	Phase RestorePhase `json:"phase,omitempty"`
+
+	// Backup describes the restored backup, once it has been validated
+	// +kubebuilder:validation:Optional
+	Backup *RestoredBackupStatus `json:"backup,omitempty"`
+
+	// RestoredNamespaces contains the namespaces whose Modela API resources were re-applied
+	// +kubebuilder:validation:Optional
+	RestoredNamespaces []string `json:"restoredNamespaces,omitempty"`
+
+	// StartedAt is the time at which the restore started
+	// +kubebuilder:validation:Optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// CompletedAt is the time at which the restore completed or failed
+	// +kubebuilder:validation:Optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+
+	// ObservedGeneration is the last generation that was acted on
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// The ModelaRestore resource controller will update FailureMessage with an error message in the case of a failure
+	// +kubebuilder:validation:Optional
+	FailureMessage *string `json:"failureMessage,omitempty"`
+
+	// Conditions reports each step of the restore
+	// +kubebuilder:validation:Optional
+	Conditions []ModelaCondition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// ModelaRestore restores a Modela installation from a backup taken by a ModelaBackupRun. The restore pauses the
+// installation, restores Vault, the databases and the object storage, re-applies the resources of the tenants and
+// then resumes the installation.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=modelarestores,singular=modelarestore,shortName="mrs",categories={modela,all}
+// +kubebuilder:printcolumn:name="Backup",type="string",JSONPath=".status.backup.folder"
+// +kubebuilder:printcolumn:name="Dry Run",type="boolean",JSONPath=".spec.dryRun"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Distribution",type="string",JSONPath=".status.backup.distribution"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type ModelaRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ModelaRestoreSpec   `json:"spec,omitempty"`
+	Status ModelaRestoreStatus `json:"status,omitempty"`
+}
+
+// ModelaRestoreList contains a list of ModelaRestore
+// +kubebuilder:object:root=true
+type ModelaRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModelaRestore `json:"items"`
+}
+
+// Finished returns true if the restore has completed or failed, or if the backup of a dry-run has been validated
+func (r *ModelaRestore) Finished() bool {
+	return r.Status.Phase == RestorePhaseCompleted || r.Status.Phase == RestorePhaseFailed ||
+		r.Status.Phase == RestorePhaseValidated
+}
+
+// Contains returns true if the backup contains a component
+func (b *RestoredBackupStatus) Contains(component string) bool {
+	if b == nil {
+		return false
+	}
+	for _, name := range b.Components {
+		if name == component {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCondition returns the condition of the given type, or nil if the condition has not been set
+func (r *ModelaRestore) GetCondition(conditionType ModelaConditionType) *ModelaCondition {
+	for i := range r.Status.Conditions {
+		if r.Status.Conditions[i].Type == conditionType {
+			return &r.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition creates or updates a condition. The transition time is only updated when the status changes.
+func (r *ModelaRestore) SetCondition(conditionType ModelaConditionType, status ConditionStatus, reason string, message string) {
+	condition := r.GetCondition(conditionType)
+	if condition == nil {
+		r.Status.Conditions = append(r.Status.Conditions, ModelaCondition{Type: conditionType})
+		condition = &r.Status.Conditions[len(r.Status.Conditions)-1]
+	}
+
+	if condition.Status != status {
+		now := metav1.Now()
+		condition.LastTransitionTime = &now
+	}
+	condition.Status = status
+	condition.Reason = reason
+	condition.Message = message
+}
+
+// IsConditionTrue returns true if the condition of the given type has the status True
+func (r *ModelaRestore) IsConditionTrue(conditionType ModelaConditionType) bool {
+	condition := r.GetCondition(conditionType)
+	return condition != nil && condition.Status == ConditionTrue
+}
+
+func init() {
+	SchemeBuilder.Register(&ModelaRestore{}, &ModelaRestoreList{})
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"reflect"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var modelarestorelog = logf.Log.WithName("modelarestore-resource")
+
+func (r *ModelaRestore) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-management-modela-ai-v1alpha1-modelarestore,mutating=true,failurePolicy=fail,sideEffects=None,groups=management.modela.ai,resources=modelarestores,verbs=create;update,versions=v1alpha1,name=mmodelarestore.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &ModelaRestore{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *ModelaRestore) Default() {
+	modelarestorelog.Info("default", "name", r.Name)
+}
+
+//+kubebuilder:webhook:path=/validate-management-modela-ai-v1alpha1-modelarestore,mutating=false,failurePolicy=fail,sideEffects=None,groups=management.modela.ai,resources=modelarestores,verbs=create;update,versions=v1alpha1,name=vmodelarestore.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &ModelaRestore{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ModelaRestore) ValidateCreate() error {
+	modelarestorelog.Info("validate create", "name", r.Name)
+	return r.Spec.Source.Validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type. The spec of a restore
+// cannot change once the restore has been created.
+func (r *ModelaRestore) ValidateUpdate(old runtime.Object) error {
+	modelarestorelog.Info("validate update", "name", r.Name)
+	if restore, ok := old.(*ModelaRestore); ok && !reflect.DeepEqual(restore.Spec, r.Spec) {
+		return errors.New("the spec of a ModelaRestore is immutable")
+	}
+	return nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ModelaRestore) ValidateDelete() error {
+	modelarestorelog.Info("validate delete", "name", r.Name)
+	return nil
+}
+
+// Validate checks that the source references either a backup run or a folder
+func (s RestoreSourceSpec) Validate() error {
+	if s.BackupRunRef == "" && s.Folder == "" {
+		return errors.New("the source of a restore must reference a backup run or a folder")
+	} else if s.BackupRunRef != "" && s.Folder != "" {
+		return errors.New("the source of a restore cannot reference both a backup run and a folder")
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The current phase of a ModelaTenant
+type ModelaTenantPhase string
+
+const (
+	ModelaTenantPhasePending      ModelaTenantPhase = "Pending"
+	ModelaTenantPhaseInstalling   ModelaTenantPhase = "Installing"
+	ModelaTenantPhaseReady        ModelaTenantPhase = "Ready"
+	ModelaTenantPhaseDegraded     ModelaTenantPhase = "Degraded"
+	ModelaTenantPhaseSuspended    ModelaTenantPhase = "Suspended"
+	ModelaTenantPhaseUninstalling ModelaTenantPhase = "Uninstalling"
+	ModelaTenantPhaseFailed       ModelaTenantPhase = "Failed"
+)
+
+const (
+	// TenantInstalled indicates if the namespace, secrets and manifests of the tenant have been installed
+	TenantInstalled ModelaConditionType = "Installed"
+	// TenantReady indicates if the labs and serving sites of the tenant are ready
+	TenantReady ModelaConditionType = "Ready"
+	// TenantSuspended indicates if the workloads of the tenant are suspended
+	TenantSuspended ModelaConditionType = "Suspended"
+	// TenantDegraded indicates if a connection of the tenant is missing
+	TenantDegraded ModelaConditionType = "Degraded"
+)
+
+// ModelaReference references a Modela resource
+type ModelaReference struct {
+	// Name is the name of the Modela resource. If empty, the tenant belongs to the only Modela resource of the cluster.
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// Namespace is the namespace of the Modela resource
+	// +kubebuilder:default:="modela-system"
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ModelaTenantSpec defines the desired state of a ModelaTenant
+type ModelaTenantSpec struct {
+	// ModelaRef references the Modela installation which hosts the tenant
+	// +kubebuilder:validation:Optional
+	ModelaRef ModelaReference `json:"modelaRef,omitempty"`
+
+	TenantConfig `json:",inline"`
+}
+
+// ModelaTenantStatus defines the observed state of a ModelaTenant
+type ModelaTenantStatus struct {
+	// Phase is the current phase of the tenant
+	// +kubebuilder:validation:Optional
+	Phase ModelaTenantPhase `json:"phase,omitempty"`
+
+	// ReadyLabs contains the names of the labs of the tenant which are ready
+	// +kubebuilder:validation:Optional
+	ReadyLabs []string `json:"readyLabs,omitempty"`
+
+	// ReadyServingSites contains the names of the serving sites of the tenant which are ready
+	// +kubebuilder:validation:Optional
+	ReadyServingSites []string `json:"readyServingSites,omitempty"`
+
+	// SecretPaths contains the locations of the secrets of the tenant inside the secret store of the Modela
+	// installation. When using Vault, these are the paths of the secrets in Vault.
+	// +kubebuilder:validation:Optional
+	SecretPaths []string `json:"secretPaths,omitempty"`
+
+	// AdminPassword reports the version of the admin password applied to the tenant
+	// +kubebuilder:validation:Optional
+	AdminPassword *TenantAdminPasswordStatus `json:"adminPassword,omitempty"`
+
+	// Quota reports the quota of the tenant and the resources it consumes
+	// +kubebuilder:validation:Optional
+	Quota *TenantQuotaStatus `json:"quota,omitempty"`
+
+	// Connections reports the state of the connections of the tenant
+	// +kubebuilder:validation:Optional
+	Connections *TenantConnectionStatus `json:"connections,omitempty"`
+
+	// ObservedGeneration is the last generation of the tenant reconciled by the operator
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// The ModelaTenant resource controller will update FailureMessage with an error message in the case of a failure
+	// +kubebuilder:validation:Optional
+	FailureMessage *string `json:"failureMessage,omitempty"`
+
+	// The last time the ModelaTenant resource was updated
+	// +kubebuilder:validation:Optional
+	LastUpdated *metav1.Time `json:"lastUpdated,omitempty"`
+
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +kubebuilder:validation:Optional
+	Conditions []ModelaCondition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// ModelaTenant defines a tenant of a Modela installation, which is managed independently of the Modela resource
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=modelatenants,singular=modelatenant,shortName="mt",scope=Cluster,categories={modela,all}
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type ModelaTenant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ModelaTenantSpec   `json:"spec,omitempty"`
+	Status ModelaTenantStatus `json:"status,omitempty"`
+}
+
+// ModelaTenantList contains a list of ModelaTenant
+// +kubebuilder:object:root=true
+type ModelaTenantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModelaTenant `json:"items"`
+}
+
+// GetCondition returns the condition of the given type, or nil if the condition has not been set
+func (t *ModelaTenant) GetCondition(conditionType ModelaConditionType) *ModelaCondition {
+	for i := range t.Status.Conditions {
+		if t.Status.Conditions[i].Type == conditionType {
+			return &t.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition creates or updates a condition. The transition time is only updated when the status changes.
+func (t *ModelaTenant) SetCondition(conditionType ModelaConditionType, status ConditionStatus, reason string, message string) {
+	condition := t.GetCondition(conditionType)
+	if condition == nil {
+		t.Status.Conditions = append(t.Status.Conditions, ModelaCondition{Type: conditionType})
+		condition = &t.Status.Conditions[len(t.Status.Conditions)-1]
+	}
+
+	if condition.Status != status {
+		now := metav1.Now()
+		condition.LastTransitionTime = &now
+	}
+	condition.Status = status
+	condition.Reason = reason
+	condition.Message = message
+}
+
+func init() {
+	SchemeBuilder.Register(&ModelaTenant{}, &ModelaTenantList{})
+}
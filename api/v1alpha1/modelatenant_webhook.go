@@ -0,0 +1,107 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"strings"
+)
+
+// log is for logging in this package.
+var modelatenantlog = logf.Log.WithName("modelatenant-resource")
+
+// reservedTenantPrefixes are the prefixes of the namespaces of Kubernetes and of the Modela system components,
+// which cannot be used as the name of a tenant
+var reservedTenantPrefixes = []string{"kube-", "modela-"}
+
+func (r *ModelaTenant) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&modelaTenantValidator{reader: mgr.GetAPIReader()}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-management-modela-ai-v1alpha1-modelatenant,mutating=false,failurePolicy=fail,sideEffects=None,groups=management.modela.ai,resources=modelatenants,verbs=create,versions=v1alpha1,name=vmodelatenant.kb.io,admissionReviewVersions=v1
+
+// modelaTenantValidator validates ModelaTenant resources. A tenant owns the namespace of its name, so the validator
+// looks up the namespace to reject tenants which would take over a namespace not created by the operator.
+type modelaTenantValidator struct {
+	reader client.Reader
+}
+
+var _ admission.CustomValidator = &modelaTenantValidator{}
+
+// ValidateCreate implements admission.CustomValidator so a webhook will be registered for the type
+func (v *modelaTenantValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	tenant, ok := obj.(*ModelaTenant)
+	if !ok {
+		return fmt.Errorf("expected a ModelaTenant but got a %T", obj)
+	}
+	modelatenantlog.Info("validate create", "name", tenant.Name)
+	if err := ValidateTenantName(tenant.Name); err != nil {
+		return err
+	}
+
+	var namespace corev1.Namespace
+	if err := v.reader.Get(ctx, client.ObjectKey{Name: tenant.Name}, &namespace); k8serr.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return ValidateTenantNamespace(&namespace)
+}
+
+// ValidateUpdate implements admission.CustomValidator so a webhook will be registered for the type. The name of a
+// tenant cannot change, so updates are not validated.
+func (v *modelaTenantValidator) ValidateUpdate(_ context.Context, _, _ runtime.Object) error {
+	return nil
+}
+
+// ValidateDelete implements admission.CustomValidator so a webhook will be registered for the type
+func (v *modelaTenantValidator) ValidateDelete(_ context.Context, _ runtime.Object) error {
+	return nil
+}
+
+// ValidateTenantName checks that the name of a tenant is not the name of a namespace reserved by Kubernetes or Modela
+func ValidateTenantName(name string) error {
+	if name == corev1.NamespaceDefault {
+		return fmt.Errorf("the tenant name %s is reserved", name)
+	}
+	for _, prefix := range reservedTenantPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return fmt.Errorf("the tenant name %s is reserved; tenant names cannot start with %s", name, prefix)
+		}
+	}
+	return nil
+}
+
+// ValidateTenantNamespace checks that an existing namespace with the name of a tenant was created by the operator,
+// so that a tenant cannot take over the namespace of another application
+func ValidateTenantNamespace(namespace *corev1.Namespace) error {
+	if _, ok := namespace.Labels["management.modela.ai/operator"]; !ok {
+		return fmt.Errorf("the namespace %s already exists and is not managed by the Modela operator", namespace.Name)
+	}
+	return nil
+}
@@ -22,6 +22,7 @@ package v1alpha1
 
 import (
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -50,6 +51,83 @@ func (in *ApiGatewaySpec) DeepCopy() *ApiGatewaySpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupComponentStatus) DeepCopyInto(out *BackupComponentStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupComponentStatus.
+func (in *BackupComponentStatus) DeepCopy() *BackupComponentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupComponentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupPolicySpec) DeepCopyInto(out *BackupPolicySpec) {
+	*out = *in
+	in.Target.DeepCopyInto(&out.Target)
+	in.Retention.DeepCopyInto(&out.Retention)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupPolicySpec.
+func (in *BackupPolicySpec) DeepCopy() *BackupPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupRetentionSpec) DeepCopyInto(out *BackupRetentionSpec) {
+	*out = *in
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupRetentionSpec.
+func (in *BackupRetentionSpec) DeepCopy() *BackupRetentionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupRetentionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupTargetSpec) DeepCopyInto(out *BackupTargetSpec) {
+	*out = *in
+	if in.CredentialsSecretRef != nil {
+		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.CASecretRef != nil {
+		in, out := &in.CASecretRef, &out.CASecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupTargetSpec.
+func (in *BackupTargetSpec) DeepCopy() *BackupTargetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupTargetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertManagerSpec) DeepCopyInto(out *CertManagerSpec) {
 	*out = *in
@@ -116,11 +194,33 @@ func (in *DataPlaneSpec) DeepCopy() *DataPlaneSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseHASpec) DeepCopyInto(out *DatabaseHASpec) {
+	*out = *in
+	if in.SynchronousReplicas != nil {
+		in, out := &in.SynchronousReplicas, &out.SynchronousReplicas
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseHASpec.
+func (in *DatabaseHASpec) DeepCopy() *DatabaseHASpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseHASpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DatabaseSpec) DeepCopyInto(out *DatabaseSpec) {
 	*out = *in
 	in.PostgresValues.DeepCopyInto(&out.PostgresValues)
 	in.MongoDBValues.DeepCopyInto(&out.MongoDBValues)
+	in.External.DeepCopyInto(&out.External)
+	in.HA.DeepCopyInto(&out.HA)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseSpec.
@@ -128,351 +228,1653 @@ func (in *DatabaseSpec) DeepCopy() *DatabaseSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DatabaseSpec)
+	out := new(DatabaseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalDatabaseSpec) DeepCopyInto(out *ExternalDatabaseSpec) {
+	*out = *in
+	if in.Postgres != nil {
+		in, out := &in.Postgres, &out.Postgres
+		*out = new(ExternalServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MongoDB != nil {
+		in, out := &in.MongoDB, &out.MongoDB
+		*out = new(ExternalServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalDatabaseSpec.
+func (in *ExternalDatabaseSpec) DeepCopy() *ExternalDatabaseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalDatabaseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalObjectStorageSpec) DeepCopyInto(out *ExternalObjectStorageSpec) {
+	*out = *in
+	in.ExternalServiceSpec.DeepCopyInto(&out.ExternalServiceSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalObjectStorageSpec.
+func (in *ExternalObjectStorageSpec) DeepCopy() *ExternalObjectStorageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalObjectStorageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalRedisSpec) DeepCopyInto(out *ExternalRedisSpec) {
+	*out = *in
+	in.TLS.DeepCopyInto(&out.TLS)
+	out.CredentialsSecretRef = in.CredentialsSecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalRedisSpec.
+func (in *ExternalRedisSpec) DeepCopy() *ExternalRedisSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalRedisSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalSecretsStoreSpec) DeepCopyInto(out *ExternalSecretsStoreSpec) {
+	*out = *in
+	if in.RefreshInterval != nil {
+		in, out := &in.RefreshInterval, &out.RefreshInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalSecretsStoreSpec.
+func (in *ExternalSecretsStoreSpec) DeepCopy() *ExternalSecretsStoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalSecretsStoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalServiceSpec) DeepCopyInto(out *ExternalServiceSpec) {
+	*out = *in
+	in.TLS.DeepCopyInto(&out.TLS)
+	out.CredentialsSecretRef = in.CredentialsSecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalServiceSpec.
+func (in *ExternalServiceSpec) DeepCopy() *ExternalServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalTLSSpec) DeepCopyInto(out *ExternalTLSSpec) {
+	*out = *in
+	if in.CASecretRef != nil {
+		in, out := &in.CASecretRef, &out.CASecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalTLSSpec.
+func (in *ExternalTLSSpec) DeepCopy() *ExternalTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressSpec) DeepCopyInto(out *IngressSpec) {
+	*out = *in
+	if in.Hostname != nil {
+		in, out := &in.Hostname, &out.Hostname
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressSpec.
+func (in *IngressSpec) DeepCopy() *IngressSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Modela) DeepCopyInto(out *Modela) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Modela.
+func (in *Modela) DeepCopy() *Modela {
+	if in == nil {
+		return nil
+	}
+	out := new(Modela)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Modela) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaBackup) DeepCopyInto(out *ModelaBackup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaBackup.
+func (in *ModelaBackup) DeepCopy() *ModelaBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ModelaBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaBackupList) DeepCopyInto(out *ModelaBackupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ModelaBackup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaBackupList.
+func (in *ModelaBackupList) DeepCopy() *ModelaBackupList {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaBackupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ModelaBackupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaBackupRun) DeepCopyInto(out *ModelaBackupRun) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaBackupRun.
+func (in *ModelaBackupRun) DeepCopy() *ModelaBackupRun {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaBackupRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ModelaBackupRun) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaBackupRunList) DeepCopyInto(out *ModelaBackupRunList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ModelaBackupRun, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaBackupRunList.
+func (in *ModelaBackupRunList) DeepCopy() *ModelaBackupRunList {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaBackupRunList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ModelaBackupRunList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaBackupRunSpec) DeepCopyInto(out *ModelaBackupRunSpec) {
+	*out = *in
+	out.ModelaRef = in.ModelaRef
+	in.Target.DeepCopyInto(&out.Target)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaBackupRunSpec.
+func (in *ModelaBackupRunSpec) DeepCopy() *ModelaBackupRunSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaBackupRunSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaBackupRunStatus) DeepCopyInto(out *ModelaBackupRunStatus) {
+	*out = *in
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]BackupComponentStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.FailureMessage != nil {
+		in, out := &in.FailureMessage, &out.FailureMessage
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaBackupRunStatus.
+func (in *ModelaBackupRunStatus) DeepCopy() *ModelaBackupRunStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaBackupRunStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaBackupSpec) DeepCopyInto(out *ModelaBackupSpec) {
+	*out = *in
+	out.ModelaRef = in.ModelaRef
+	in.BackupPolicySpec.DeepCopyInto(&out.BackupPolicySpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaBackupSpec.
+func (in *ModelaBackupSpec) DeepCopy() *ModelaBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaBackupStatus) DeepCopyInto(out *ModelaBackupStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulTime != nil {
+		in, out := &in.LastSuccessfulTime, &out.LastSuccessfulTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Runs != nil {
+		in, out := &in.Runs, &out.Runs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FailureMessage != nil {
+		in, out := &in.FailureMessage, &out.FailureMessage
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaBackupStatus.
+func (in *ModelaBackupStatus) DeepCopy() *ModelaBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaCondition) DeepCopyInto(out *ModelaCondition) {
+	*out = *in
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaCondition.
+func (in *ModelaCondition) DeepCopy() *ModelaCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaLicenseSpec) DeepCopyInto(out *ModelaLicenseSpec) {
+	*out = *in
+	if in.LicenseKey != nil {
+		in, out := &in.LicenseKey, &out.LicenseKey
+		*out = new(string)
+		**out = **in
+	}
+	if in.LinkLicense != nil {
+		in, out := &in.LinkLicense, &out.LinkLicense
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaLicenseSpec.
+func (in *ModelaLicenseSpec) DeepCopy() *ModelaLicenseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaLicenseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaList) DeepCopyInto(out *ModelaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Modela, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaList.
+func (in *ModelaList) DeepCopy() *ModelaList {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ModelaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaReference) DeepCopyInto(out *ModelaReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaReference.
+func (in *ModelaReference) DeepCopy() *ModelaReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaRestore) DeepCopyInto(out *ModelaRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaRestore.
+func (in *ModelaRestore) DeepCopy() *ModelaRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ModelaRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaRestoreList) DeepCopyInto(out *ModelaRestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ModelaRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaRestoreList.
+func (in *ModelaRestoreList) DeepCopy() *ModelaRestoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaRestoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ModelaRestoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaRestoreSpec) DeepCopyInto(out *ModelaRestoreSpec) {
+	*out = *in
+	out.ModelaRef = in.ModelaRef
+	in.Source.DeepCopyInto(&out.Source)
+	if in.Tenants != nil {
+		in, out := &in.Tenants, &out.Tenants
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VaultUnsealKeysSecretRef != nil {
+		in, out := &in.VaultUnsealKeysSecretRef, &out.VaultUnsealKeysSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaRestoreSpec.
+func (in *ModelaRestoreSpec) DeepCopy() *ModelaRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaRestoreStatus) DeepCopyInto(out *ModelaRestoreStatus) {
+	*out = *in
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(RestoredBackupStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RestoredNamespaces != nil {
+		in, out := &in.RestoredNamespaces, &out.RestoredNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.FailureMessage != nil {
+		in, out := &in.FailureMessage, &out.FailureMessage
+		*out = new(string)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ModelaCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaRestoreStatus.
+func (in *ModelaRestoreStatus) DeepCopy() *ModelaRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaSpec) DeepCopyInto(out *ModelaSpec) {
+	*out = *in
+	in.Observability.DeepCopyInto(&out.Observability)
+	in.Network.DeepCopyInto(&out.Network)
+	in.License.DeepCopyInto(&out.License)
+	if in.Tenants != nil {
+		in, out := &in.Tenants, &out.Tenants
+		*out = make([]*TenantSpec, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(TenantSpec)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.TenantGracePeriod != nil {
+		in, out := &in.TenantGracePeriod, &out.TenantGracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	in.CertManager.DeepCopyInto(&out.CertManager)
+	in.ObjectStore.DeepCopyInto(&out.ObjectStore)
+	in.Database.DeepCopyInto(&out.Database)
+	in.OnlineStore.DeepCopyInto(&out.OnlineStore)
+	in.ControlPlane.DeepCopyInto(&out.ControlPlane)
+	in.DataPlane.DeepCopyInto(&out.DataPlane)
+	in.ApiGateway.DeepCopyInto(&out.ApiGateway)
+	in.Vault.DeepCopyInto(&out.Vault)
+	in.SecretStore.DeepCopyInto(&out.SecretStore)
+	in.SecretRotation.DeepCopyInto(&out.SecretRotation)
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(BackupPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	in.PlatformConfig.DeepCopyInto(&out.PlatformConfig)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaSpec.
+func (in *ModelaSpec) DeepCopy() *ModelaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaStatus) DeepCopyInto(out *ModelaStatus) {
+	*out = *in
+	if in.Tenants != nil {
+		in, out := &in.Tenants, &out.Tenants
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LicenseToken != nil {
+		in, out := &in.LicenseToken, &out.LicenseToken
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.LinkLicenseUrl != nil {
+		in, out := &in.LinkLicenseUrl, &out.LinkLicenseUrl
+		*out = new(string)
+		**out = **in
+	}
+	if in.SecretRotations != nil {
+		in, out := &in.SecretRotations, &out.SecretRotations
+		*out = make([]SecretRotationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RetainedTenants != nil {
+		in, out := &in.RetainedTenants, &out.RetainedTenants
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SuspendedTenants != nil {
+		in, out := &in.SuspendedTenants, &out.SuspendedTenants
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TenantAdminPasswords != nil {
+		in, out := &in.TenantAdminPasswords, &out.TenantAdminPasswords
+		*out = make([]TenantAdminPasswordStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TenantQuotas != nil {
+		in, out := &in.TenantQuotas, &out.TenantQuotas
+		*out = make([]TenantQuotaStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TenantConnections != nil {
+		in, out := &in.TenantConnections, &out.TenantConnections
+		*out = make([]TenantConnectionStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Pgvector != nil {
+		in, out := &in.Pgvector, &out.Pgvector
+		*out = new(PgvectorStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SchemaMigration != nil {
+		in, out := &in.SchemaMigration, &out.SchemaMigration
+		*out = new(SchemaMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DatabaseHA != nil {
+		in, out := &in.DatabaseHA, &out.DatabaseHA
+		*out = new(bool)
+		**out = **in
+	}
+	if in.FailureMessage != nil {
+		in, out := &in.FailureMessage, &out.FailureMessage
+		*out = new(string)
+		**out = **in
+	}
+	if in.LastUpdated != nil {
+		in, out := &in.LastUpdated, &out.LastUpdated
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ModelaCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaStatus.
+func (in *ModelaStatus) DeepCopy() *ModelaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaTenant) DeepCopyInto(out *ModelaTenant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaTenant.
+func (in *ModelaTenant) DeepCopy() *ModelaTenant {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaTenant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ModelaTenant) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaTenantList) DeepCopyInto(out *ModelaTenantList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ModelaTenant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaTenantList.
+func (in *ModelaTenantList) DeepCopy() *ModelaTenantList {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaTenantList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ModelaTenantList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaTenantSpec) DeepCopyInto(out *ModelaTenantSpec) {
+	*out = *in
+	out.ModelaRef = in.ModelaRef
+	in.TenantConfig.DeepCopyInto(&out.TenantConfig)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaTenantSpec.
+func (in *ModelaTenantSpec) DeepCopy() *ModelaTenantSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaTenantSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelaTenantStatus) DeepCopyInto(out *ModelaTenantStatus) {
+	*out = *in
+	if in.ReadyLabs != nil {
+		in, out := &in.ReadyLabs, &out.ReadyLabs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReadyServingSites != nil {
+		in, out := &in.ReadyServingSites, &out.ReadyServingSites
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretPaths != nil {
+		in, out := &in.SecretPaths, &out.SecretPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AdminPassword != nil {
+		in, out := &in.AdminPassword, &out.AdminPassword
+		*out = new(TenantAdminPasswordStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(TenantQuotaStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Connections != nil {
+		in, out := &in.Connections, &out.Connections
+		*out = new(TenantConnectionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FailureMessage != nil {
+		in, out := &in.FailureMessage, &out.FailureMessage
+		*out = new(string)
+		**out = **in
+	}
+	if in.LastUpdated != nil {
+		in, out := &in.LastUpdated, &out.LastUpdated
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ModelaCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaTenantStatus.
+func (in *ModelaTenantStatus) DeepCopy() *ModelaTenantStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelaTenantStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkSpec) DeepCopyInto(out *NetworkSpec) {
+	*out = *in
+	if in.NodePort != nil {
+		in, out := &in.NodePort, &out.NodePort
+		*out = new(NodePortSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(IngressSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Nginx != nil {
+		in, out := &in.Nginx, &out.Nginx
+		*out = new(NginxSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkSpec.
+func (in *NetworkSpec) DeepCopy() *NetworkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NginxSpec) DeepCopyInto(out *NginxSpec) {
+	*out = *in
+	in.Values.DeepCopyInto(&out.Values)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NginxSpec.
+func (in *NginxSpec) DeepCopy() *NginxSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NginxSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePortSpec) DeepCopyInto(out *NodePortSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePortSpec.
+func (in *NodePortSpec) DeepCopy() *NodePortSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePortSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectStorageSpec) DeepCopyInto(out *ObjectStorageSpec) {
+	*out = *in
+	in.Values.DeepCopyInto(&out.Values)
+	if in.External != nil {
+		in, out := &in.External, &out.External
+		*out = new(ExternalObjectStorageSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStorageSpec.
+func (in *ObjectStorageSpec) DeepCopy() *ObjectStorageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectStorageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObservabilitySpec) DeepCopyInto(out *ObservabilitySpec) {
+	*out = *in
+	in.PrometheusValues.DeepCopyInto(&out.PrometheusValues)
+	in.LokiValues.DeepCopyInto(&out.LokiValues)
+	in.GrafanaValues.DeepCopyInto(&out.GrafanaValues)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObservabilitySpec.
+func (in *ObservabilitySpec) DeepCopy() *ObservabilitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ObservabilitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OnlineStoreSpec) DeepCopyInto(out *OnlineStoreSpec) {
+	*out = *in
+	in.Values.DeepCopyInto(&out.Values)
+	if in.External != nil {
+		in, out := &in.External, &out.External
+		*out = new(ExternalRedisSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OnlineStoreSpec.
+func (in *OnlineStoreSpec) DeepCopy() *OnlineStoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OnlineStoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PgvectorStatus) DeepCopyInto(out *PgvectorStatus) {
+	*out = *in
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PgvectorStatus.
+func (in *PgvectorStatus) DeepCopy() *PgvectorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PgvectorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlatformConfigSpec) DeepCopyInto(out *PlatformConfigSpec) {
+	*out = *in
+	if in.Extra != nil {
+		in, out := &in.Extra, &out.Extra
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlatformConfigSpec.
+func (in *PlatformConfigSpec) DeepCopy() *PlatformConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PlatformConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreSourceSpec) DeepCopyInto(out *RestoreSourceSpec) {
+	*out = *in
+	in.Target.DeepCopyInto(&out.Target)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreSourceSpec.
+func (in *RestoreSourceSpec) DeepCopy() *RestoreSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoredBackupStatus) DeepCopyInto(out *RestoredBackupStatus) {
+	*out = *in
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CreatedAt != nil {
+		in, out := &in.CreatedAt, &out.CreatedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoredBackupStatus.
+func (in *RestoredBackupStatus) DeepCopy() *RestoredBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoredBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchemaMigrationStatus) DeepCopyInto(out *SchemaMigrationStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchemaMigrationStatus.
+func (in *SchemaMigrationStatus) DeepCopy() *SchemaMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SchemaMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SealedFileCustodySpec) DeepCopyInto(out *SealedFileCustodySpec) {
+	*out = *in
+	if in.PublicKeys != nil {
+		in, out := &in.PublicKeys, &out.PublicKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SealedFileCustodySpec.
+func (in *SealedFileCustodySpec) DeepCopy() *SealedFileCustodySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SealedFileCustodySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretRotationSpec) DeepCopyInto(out *SecretRotationSpec) {
+	*out = *in
+	if in.JwtSecret != nil {
+		in, out := &in.JwtSecret, &out.JwtSecret
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ApiKeySecret != nil {
+		in, out := &in.ApiKeySecret, &out.ApiKeySecret
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Postgres != nil {
+		in, out := &in.Postgres, &out.Postgres
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Mongo != nil {
+		in, out := &in.Mongo, &out.Mongo
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Redis != nil {
+		in, out := &in.Redis, &out.Redis
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ObjectStorage != nil {
+		in, out := &in.ObjectStorage, &out.ObjectStorage
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretRotationSpec.
+func (in *SecretRotationSpec) DeepCopy() *SecretRotationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretRotationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretRotationStatus) DeepCopyInto(out *SecretRotationStatus) {
+	*out = *in
+	in.LastRotationTime.DeepCopyInto(&out.LastRotationTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretRotationStatus.
+func (in *SecretRotationStatus) DeepCopy() *SecretRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretRotationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IngressSpec) DeepCopyInto(out *IngressSpec) {
+func (in *SecretStoreSpec) DeepCopyInto(out *SecretStoreSpec) {
 	*out = *in
-	if in.Hostname != nil {
-		in, out := &in.Hostname, &out.Hostname
-		*out = new(string)
-		**out = **in
+	if in.ExternalSecrets != nil {
+		in, out := &in.ExternalSecrets, &out.ExternalSecrets
+		*out = new(ExternalSecretsStoreSpec)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressSpec.
-func (in *IngressSpec) DeepCopy() *IngressSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretStoreSpec.
+func (in *SecretStoreSpec) DeepCopy() *SecretStoreSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(IngressSpec)
+	out := new(SecretStoreSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Modela) DeepCopyInto(out *Modela) {
+func (in *TenantAccountSpec) DeepCopyInto(out *TenantAccountSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.PasswordSecretRef != nil {
+		in, out := &in.PasswordSecretRef, &out.PasswordSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Modela.
-func (in *Modela) DeepCopy() *Modela {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantAccountSpec.
+func (in *TenantAccountSpec) DeepCopy() *TenantAccountSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Modela)
+	out := new(TenantAccountSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Modela) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ModelaCondition) DeepCopyInto(out *ModelaCondition) {
+func (in *TenantAdminPasswordStatus) DeepCopyInto(out *TenantAdminPasswordStatus) {
 	*out = *in
-	if in.LastTransitionTime != nil {
-		in, out := &in.LastTransitionTime, &out.LastTransitionTime
-		*out = (*in).DeepCopy()
-	}
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaCondition.
-func (in *ModelaCondition) DeepCopy() *ModelaCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantAdminPasswordStatus.
+func (in *TenantAdminPasswordStatus) DeepCopy() *TenantAdminPasswordStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ModelaCondition)
+	out := new(TenantAdminPasswordStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ModelaLicenseSpec) DeepCopyInto(out *ModelaLicenseSpec) {
+func (in *TenantConfig) DeepCopyInto(out *TenantConfig) {
 	*out = *in
-	if in.LicenseKey != nil {
-		in, out := &in.LicenseKey, &out.LicenseKey
+	if in.AdminPassword != nil {
+		in, out := &in.AdminPassword, &out.AdminPassword
 		*out = new(string)
 		**out = **in
 	}
-	if in.LinkLicense != nil {
-		in, out := &in.LinkLicense, &out.LinkLicense
-		*out = new(bool)
-		**out = **in
+	if in.AdminPasswordSecretRef != nil {
+		in, out := &in.AdminPasswordSecretRef, &out.AdminPasswordSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Accounts != nil {
+		in, out := &in.Accounts, &out.Accounts
+		*out = make([]TenantAccountSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(TenantQuotaSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Labs != nil {
+		in, out := &in.Labs, &out.Labs
+		*out = make([]TenantLabSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ServingSites != nil {
+		in, out := &in.ServingSites, &out.ServingSites
+		*out = make([]TenantServingSiteSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaLicenseSpec.
-func (in *ModelaLicenseSpec) DeepCopy() *ModelaLicenseSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantConfig.
+func (in *TenantConfig) DeepCopy() *TenantConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(ModelaLicenseSpec)
+	out := new(TenantConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ModelaList) DeepCopyInto(out *ModelaList) {
+func (in *TenantConnectionStatus) DeepCopyInto(out *TenantConnectionStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Modela, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Missing != nil {
+		in, out := &in.Missing, &out.Missing
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaList.
-func (in *ModelaList) DeepCopy() *ModelaList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantConnectionStatus.
+func (in *TenantConnectionStatus) DeepCopy() *TenantConnectionStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ModelaList)
+	out := new(TenantConnectionStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ModelaList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantLabSpec) DeepCopyInto(out *TenantLabSpec) {
+	*out = *in
+	if in.WorkloadClasses != nil {
+		in, out := &in.WorkloadClasses, &out.WorkloadClasses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	return nil
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(TenantWorkspaceLimitSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantLabSpec.
+func (in *TenantLabSpec) DeepCopy() *TenantLabSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantLabSpec)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ModelaSpec) DeepCopyInto(out *ModelaSpec) {
+func (in *TenantLimitRangeSpec) DeepCopyInto(out *TenantLimitRangeSpec) {
 	*out = *in
-	in.Observability.DeepCopyInto(&out.Observability)
-	in.Network.DeepCopyInto(&out.Network)
-	in.License.DeepCopyInto(&out.License)
-	if in.Tenants != nil {
-		in, out := &in.Tenants, &out.Tenants
-		*out = make([]*TenantSpec, len(*in))
-		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = new(TenantSpec)
-				(*in).DeepCopyInto(*out)
-			}
-		}
+	if in.DefaultCPU != nil {
+		in, out := &in.DefaultCPU, &out.DefaultCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.DefaultMemory != nil {
+		in, out := &in.DefaultMemory, &out.DefaultMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.DefaultRequestCPU != nil {
+		in, out := &in.DefaultRequestCPU, &out.DefaultRequestCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.DefaultRequestMemory != nil {
+		in, out := &in.DefaultRequestMemory, &out.DefaultRequestMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxCPU != nil {
+		in, out := &in.MaxCPU, &out.MaxCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxMemory != nil {
+		in, out := &in.MaxMemory, &out.MaxMemory
+		x := (*in).DeepCopy()
+		*out = &x
 	}
-	in.CertManager.DeepCopyInto(&out.CertManager)
-	in.ObjectStore.DeepCopyInto(&out.ObjectStore)
-	in.Database.DeepCopyInto(&out.Database)
-	in.OnlineStore.DeepCopyInto(&out.OnlineStore)
-	in.ControlPlane.DeepCopyInto(&out.ControlPlane)
-	in.DataPlane.DeepCopyInto(&out.DataPlane)
-	in.ApiGateway.DeepCopyInto(&out.ApiGateway)
-	in.Vault.DeepCopyInto(&out.Vault)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaSpec.
-func (in *ModelaSpec) DeepCopy() *ModelaSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantLimitRangeSpec.
+func (in *TenantLimitRangeSpec) DeepCopy() *TenantLimitRangeSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ModelaSpec)
+	out := new(TenantLimitRangeSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ModelaStatus) DeepCopyInto(out *ModelaStatus) {
+func (in *TenantQuotaSpec) DeepCopyInto(out *TenantQuotaSpec) {
 	*out = *in
-	if in.Tenants != nil {
-		in, out := &in.Tenants, &out.Tenants
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.CPU != nil {
+		in, out := &in.CPU, &out.CPU
+		x := (*in).DeepCopy()
+		*out = &x
 	}
-	if in.LicenseToken != nil {
-		in, out := &in.LicenseToken, &out.LicenseToken
-		*out = new(v1.ObjectReference)
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.GPU != nil {
+		in, out := &in.GPU, &out.GPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.PersistentVolumeClaims != nil {
+		in, out := &in.PersistentVolumeClaims, &out.PersistentVolumeClaims
+		*out = new(int64)
 		**out = **in
 	}
-	if in.LinkLicenseUrl != nil {
-		in, out := &in.LinkLicenseUrl, &out.LinkLicenseUrl
-		*out = new(string)
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = new(int64)
 		**out = **in
 	}
-	if in.FailureMessage != nil {
-		in, out := &in.FailureMessage, &out.FailureMessage
-		*out = new(string)
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = new(int64)
 		**out = **in
 	}
-	if in.LastUpdated != nil {
-		in, out := &in.LastUpdated, &out.LastUpdated
-		*out = (*in).DeepCopy()
+	if in.ConfigMaps != nil {
+		in, out := &in.ConfigMaps, &out.ConfigMaps
+		*out = new(int64)
+		**out = **in
 	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]ModelaCondition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = new(int64)
+		**out = **in
 	}
+	in.Limits.DeepCopyInto(&out.Limits)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelaStatus.
-func (in *ModelaStatus) DeepCopy() *ModelaStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantQuotaSpec.
+func (in *TenantQuotaSpec) DeepCopy() *TenantQuotaSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ModelaStatus)
+	out := new(TenantQuotaSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NetworkSpec) DeepCopyInto(out *NetworkSpec) {
+func (in *TenantQuotaStatus) DeepCopyInto(out *TenantQuotaStatus) {
 	*out = *in
-	if in.NodePort != nil {
-		in, out := &in.NodePort, &out.NodePort
-		*out = new(NodePortSpec)
-		(*in).DeepCopyInto(*out)
+	if in.Hard != nil {
+		in, out := &in.Hard, &out.Hard
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
 	}
-	if in.Ingress != nil {
-		in, out := &in.Ingress, &out.Ingress
-		*out = new(IngressSpec)
-		(*in).DeepCopyInto(*out)
+	if in.Used != nil {
+		in, out := &in.Used, &out.Used
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
 	}
-	if in.Nginx != nil {
-		in, out := &in.Nginx, &out.Nginx
-		*out = new(NginxSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantQuotaStatus.
+func (in *TenantQuotaStatus) DeepCopy() *TenantQuotaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantQuotaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantServingSiteSpec) DeepCopyInto(out *TenantServingSiteSpec) {
+	*out = *in
+	if in.WorkloadClasses != nil {
+		in, out := &in.WorkloadClasses, &out.WorkloadClasses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(TenantWorkspaceLimitSpec)
 		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkSpec.
-func (in *NetworkSpec) DeepCopy() *NetworkSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantServingSiteSpec.
+func (in *TenantServingSiteSpec) DeepCopy() *TenantServingSiteSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(NetworkSpec)
+	out := new(TenantServingSiteSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NginxSpec) DeepCopyInto(out *NginxSpec) {
+func (in *TenantSpec) DeepCopyInto(out *TenantSpec) {
 	*out = *in
-	in.Values.DeepCopyInto(&out.Values)
+	in.TenantConfig.DeepCopyInto(&out.TenantConfig)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NginxSpec.
-func (in *NginxSpec) DeepCopy() *NginxSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantSpec.
+func (in *TenantSpec) DeepCopy() *TenantSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(NginxSpec)
+	out := new(TenantSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodePortSpec) DeepCopyInto(out *NodePortSpec) {
+func (in *TenantWorkspaceLimitSpec) DeepCopyInto(out *TenantWorkspaceLimitSpec) {
 	*out = *in
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.CPU != nil {
+		in, out := &in.CPU, &out.CPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = new(int32)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePortSpec.
-func (in *NodePortSpec) DeepCopy() *NodePortSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantWorkspaceLimitSpec.
+func (in *TenantWorkspaceLimitSpec) DeepCopy() *TenantWorkspaceLimitSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(NodePortSpec)
+	out := new(TenantWorkspaceLimitSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectStorageSpec) DeepCopyInto(out *ObjectStorageSpec) {
+func (in *TransitSealSpec) DeepCopyInto(out *TransitSealSpec) {
 	*out = *in
-	in.Values.DeepCopyInto(&out.Values)
+	in.TokenSecretRef.DeepCopyInto(&out.TokenSecretRef)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStorageSpec.
-func (in *ObjectStorageSpec) DeepCopy() *ObjectStorageSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransitSealSpec.
+func (in *TransitSealSpec) DeepCopy() *TransitSealSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectStorageSpec)
+	out := new(TransitSealSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObservabilitySpec) DeepCopyInto(out *ObservabilitySpec) {
+func (in *VaultHASpec) DeepCopyInto(out *VaultHASpec) {
 	*out = *in
-	in.PrometheusValues.DeepCopyInto(&out.PrometheusValues)
-	in.LokiValues.DeepCopyInto(&out.LokiValues)
-	in.GrafanaValues.DeepCopyInto(&out.GrafanaValues)
+	in.Snapshots.DeepCopyInto(&out.Snapshots)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObservabilitySpec.
-func (in *ObservabilitySpec) DeepCopy() *ObservabilitySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultHASpec.
+func (in *VaultHASpec) DeepCopy() *VaultHASpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ObservabilitySpec)
+	out := new(VaultHASpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OnlineStoreSpec) DeepCopyInto(out *OnlineStoreSpec) {
+func (in *VaultKeyCustodySpec) DeepCopyInto(out *VaultKeyCustodySpec) {
 	*out = *in
-	in.Values.DeepCopyInto(&out.Values)
+	if in.SealedFile != nil {
+		in, out := &in.SealedFile, &out.SealedFile
+		*out = new(SealedFileCustodySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Transit != nil {
+		in, out := &in.Transit, &out.Transit
+		*out = new(TransitSealSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OnlineStoreSpec.
-func (in *OnlineStoreSpec) DeepCopy() *OnlineStoreSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultKeyCustodySpec.
+func (in *VaultKeyCustodySpec) DeepCopy() *VaultKeyCustodySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(OnlineStoreSpec)
+	out := new(VaultKeyCustodySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TenantSpec) DeepCopyInto(out *TenantSpec) {
+func (in *VaultSnapshotSpec) DeepCopyInto(out *VaultSnapshotSpec) {
 	*out = *in
-	if in.AdminPassword != nil {
-		in, out := &in.AdminPassword, &out.AdminPassword
+	out.StorageSize = in.StorageSize.DeepCopy()
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
 		*out = new(string)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantSpec.
-func (in *TenantSpec) DeepCopy() *TenantSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSnapshotSpec.
+func (in *VaultSnapshotSpec) DeepCopy() *VaultSnapshotSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(TenantSpec)
+	out := new(VaultSnapshotSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -480,11 +1882,14 @@ func (in *TenantSpec) DeepCopy() *TenantSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VaultSpec) DeepCopyInto(out *VaultSpec) {
 	*out = *in
+	in.KeyCustody.DeepCopyInto(&out.KeyCustody)
 	if in.VaultAddress != nil {
 		in, out := &in.VaultAddress, &out.VaultAddress
 		*out = new(string)
 		**out = **in
 	}
+	in.HA.DeepCopyInto(&out.HA)
+	in.TLS.DeepCopyInto(&out.TLS)
 	in.Values.DeepCopyInto(&out.Values)
 }
 
@@ -497,3 +1902,28 @@ func (in *VaultSpec) DeepCopy() *VaultSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultTLSSpec) DeepCopyInto(out *VaultTLSSpec) {
+	*out = *in
+	if in.CASecretRef != nil {
+		in, out := &in.CASecretRef, &out.CASecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientCertificateSecretRef != nil {
+		in, out := &in.ClientCertificateSecretRef, &out.ClientCertificateSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultTLSSpec.
+func (in *VaultTLSSpec) DeepCopy() *VaultTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,286 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/vault/api"
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+	"github.com/metaprov/modela-operator/pkg/vault"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sort"
+	"strings"
+	"time"
+)
+
+var vaultSealedGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "modela_vault_sealed",
+	Help: "Indicates if a server of the Vault used by a Modela installation is sealed (1) or unsealed (0)",
+}, []string{"modela", "server"})
+
+func init() {
+	metrics.Registry.MustRegister(vaultSealedGauge)
+}
+
+// AutoUnsealer periodically unseals the Vault servers used by every Modela installation, with the key shares held
+// by the key custody backend of the installation. Failures are retried with an exponential back-off, and the
+// sealed state of each installation is reported through the VaultSealed condition and the modela_vault_sealed metric.
+type AutoUnsealer struct {
+	Client     client.Client
+	Interval   time.Duration
+	MaxBackoff time.Duration
+	backoff    map[types.NamespacedName]*unsealBackoff
+}
+
+type unsealBackoff struct {
+	delay time.Duration
+	next  time.Time
+}
+
+// unsealResult describes the sealed state of the Vault servers of a Modela installation after an unseal attempt
+type unsealResult struct {
+	sealed   []string
+	unsealed []string
+	reason   string
+}
+
+// Sealed returns the names of the servers which remained sealed
+func (r unsealResult) Sealed() []string {
+	return r.sealed
+}
+
+func NewAutoUnsealer(c client.Client) *AutoUnsealer {
+	return &AutoUnsealer{
+		Client:     c,
+		Interval:   5 * time.Second,
+		MaxBackoff: 5 * time.Minute,
+		backoff:    make(map[types.NamespacedName]*unsealBackoff),
+	}
+}
+
+// NeedLeaderElection ensures that only the leading instance of the operator unseals Vault
+func (u *AutoUnsealer) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs the auto-unseal loop until the context is cancelled
+func (u *AutoUnsealer) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(u.Interval):
+			u.unsealAll(ctx)
+		}
+	}
+}
+
+func (u *AutoUnsealer) unsealAll(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	var modelas managementv1.ModelaList
+	if err := u.Client.List(ctx, &modelas); err != nil {
+		logger.Error(err, "Failed to list Modela resources")
+		return
+	}
+
+	for i := range modelas.Items {
+		modela := &modelas.Items[i]
+		key := types.NamespacedName{Namespace: modela.Namespace, Name: modela.Name}
+		if !usesVault(modela) {
+			delete(u.backoff, key)
+			continue
+		}
+
+		backoff, ok := u.backoff[key]
+		if !ok {
+			backoff = &unsealBackoff{}
+			u.backoff[key] = backoff
+		}
+		if time.Now().Before(backoff.next) {
+			continue
+		}
+
+		result, err := u.Unseal(ctx, modela)
+		if err != nil {
+			backoff.delay = nextBackoff(backoff.delay, u.Interval, u.MaxBackoff)
+			backoff.next = time.Now().Add(backoff.delay)
+			logger.Error(err, "Failed to unseal Vault", "modela", key, "retryAfter", backoff.delay)
+		} else {
+			backoff.delay, backoff.next = 0, time.Time{}
+		}
+
+		if err := u.updateCondition(ctx, modela, result, err); err != nil {
+			logger.Error(err, "Failed to update Vault sealed condition", "modela", key)
+		}
+	}
+}
+
+// usesVault returns true if a Modela installation uses a Vault server which can be reached by the operator
+func usesVault(modela *managementv1.Modela) bool {
+	if modela.Spec.Vault.VaultAddress != nil && *modela.Spec.Vault.VaultAddress != "" {
+		return true
+	}
+
+	// The bundled Vault servers are only reachable from inside the cluster
+	if _, err := os.Stat("/var/run/secrets/kubernetes.io/serviceaccount/token"); errors.Is(err, os.ErrNotExist) {
+		return false
+	}
+	return modela.Spec.Vault.Install
+}
+
+// Unseal attempts to unseal every server of the Vault used by a Modela installation
+func (u *AutoUnsealer) Unseal(ctx context.Context, modela *managementv1.Modela) (unsealResult, error) {
+	var result unsealResult
+	clients, err := vault.GetUnauthenticatedServerClients(modela)
+	if err != nil {
+		return result, err
+	}
+
+	var keys []string
+	var keysErr error
+	var loaded bool
+	var errs []string
+	for _, server := range sortedServers(clients) {
+		sealed, err := u.unsealServer(ctx, clients[server], func() ([]string, error) {
+			if !loaded {
+				keys, keysErr = loadUnsealKeys(modela)
+				loaded = true
+			}
+			return keys, keysErr
+		})
+		if err == vault.UnsealKeysUnavailableError {
+			result.reason = "UnsealKeysUnavailable"
+		} else if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", server, err))
+		}
+
+		if sealed {
+			result.sealed = append(result.sealed, server)
+			vaultSealedGauge.WithLabelValues(modela.Namespace+"/"+modela.Name, server).Set(1)
+		} else {
+			result.unsealed = append(result.unsealed, server)
+			vaultSealedGauge.WithLabelValues(modela.Namespace+"/"+modela.Name, server).Set(0)
+		}
+	}
+
+	if len(errs) > 0 {
+		return result, errors.Errorf("Failed to unseal Vault servers: %s", strings.Join(errs, "; "))
+	}
+	return result, nil
+}
+
+// unsealServer unseals a single Vault server, if it has been initialized and is sealed. It returns the sealed
+// state of the server after the attempt.
+func (u *AutoUnsealer) unsealServer(ctx context.Context, client *api.Client, getKeys func() ([]string, error)) (bool, error) {
+	sys := client.Sys()
+	status, err := sys.SealStatus()
+	if err != nil {
+		return true, err
+	}
+
+	// Uninitialized servers are handled by the installation of Vault
+	if !status.Initialized || !status.Sealed {
+		return status.Sealed, nil
+	}
+
+	keys, err := getKeys()
+	if err != nil {
+		return true, err
+	}
+
+	log.FromContext(ctx).Info("Attempting to unseal Vault server", "address", client.Address())
+	for _, key := range keys {
+		if status, err = sys.Unseal(key); err != nil {
+			return true, err
+		} else if !status.Sealed {
+			return false, nil
+		}
+	}
+
+	return status.Sealed, nil
+}
+
+// loadUnsealKeys returns the key shares held by the key custody backend of a Modela installation. Servers which
+// are unsealed through the transit seal unseal themselves, so no keys are required.
+func loadUnsealKeys(modela *managementv1.Modela) ([]string, error) {
+	custodian, err := vault.GetKeyCustodian(modela)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := custodian.UnsealKeys()
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 && modela.Spec.Vault.KeyCustody.Backend != managementv1.VaultKeyCustodyTransit {
+		return nil, vault.UnsealKeysUnavailableError
+	}
+	return keys, nil
+}
+
+// updateCondition records the sealed state of the Vault servers in the VaultSealed condition of a Modela installation
+func (u *AutoUnsealer) updateCondition(ctx context.Context, modela *managementv1.Modela, result unsealResult, err error) error {
+	original := modela.DeepCopy()
+	switch {
+	case len(result.sealed) > 0:
+		reason := result.reason
+		if reason == "" {
+			reason = "Sealed"
+		}
+		message := fmt.Sprintf("Sealed Vault servers: %s", strings.Join(result.sealed, ", "))
+		if err != nil {
+			message += ". " + err.Error()
+		}
+		modela.SetCondition(managementv1.VaultSealed, managementv1.ConditionTrue, reason, message)
+	case err != nil:
+		modela.SetCondition(managementv1.VaultSealed, managementv1.ConditionUnknown, "Unreachable", err.Error())
+	case len(result.unsealed) == 0:
+		return nil
+	default:
+		modela.SetCondition(managementv1.VaultSealed, managementv1.ConditionFalse, "Unsealed",
+			fmt.Sprintf("Unsealed Vault servers: %s", strings.Join(result.unsealed, ", ")))
+	}
+
+	if equalConditions(original.Status.Conditions, modela.Status.Conditions) {
+		return nil
+	}
+	return u.Client.Status().Patch(ctx, modela, client.MergeFrom(original))
+}
+
+func equalConditions(old []managementv1.ModelaCondition, new []managementv1.ModelaCondition) bool {
+	if len(old) != len(new) {
+		return false
+	}
+	for i := range old {
+		if old[i].Type != new[i].Type || old[i].Status != new[i].Status || old[i].Reason != new[i].Reason ||
+			old[i].Message != new[i].Message {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedServers(clients map[string]*api.Client) []string {
+	var servers []string
+	for server := range clients {
+		servers = append(servers, server)
+	}
+	sort.Strings(servers)
+	return servers
+}
+
+// nextBackoff doubles the previous back-off delay, starting at the base interval and capped at the maximum delay
+func nextBackoff(previous time.Duration, base time.Duration, max time.Duration) time.Duration {
+	if previous < base {
+		return base
+	}
+	if previous*2 > max {
+		return max
+	}
+	return previous * 2
+}
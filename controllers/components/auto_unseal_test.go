@@ -0,0 +1,30 @@
+package components
+
+import (
+	"github.com/metaprov/modela-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+var _ = Describe("Vault auto-unseal", func() {
+	It("Should back off exponentially up to the maximum delay", func() {
+		delay := nextBackoff(0, 5*time.Second, 30*time.Second)
+		Expect(delay).To(Equal(5 * time.Second))
+		delay = nextBackoff(delay, 5*time.Second, 30*time.Second)
+		Expect(delay).To(Equal(10 * time.Second))
+		delay = nextBackoff(20*time.Second, 5*time.Second, 30*time.Second)
+		Expect(delay).To(Equal(30 * time.Second))
+	})
+
+	It("Should only update the transition time when the sealed state changes", func() {
+		modela := &v1alpha1.Modela{}
+		modela.SetCondition(v1alpha1.VaultSealed, v1alpha1.ConditionTrue, "Sealed", "")
+		transition := modela.GetCondition(v1alpha1.VaultSealed).LastTransitionTime
+
+		modela.SetCondition(v1alpha1.VaultSealed, v1alpha1.ConditionTrue, "UnsealKeysUnavailable", "")
+		Expect(modela.Status.Conditions).To(HaveLen(1))
+		Expect(modela.GetCondition(v1alpha1.VaultSealed).LastTransitionTime).To(Equal(transition))
+		Expect(modela.GetCondition(v1alpha1.VaultSealed).Reason).To(Equal("UnsealKeysUnavailable"))
+	})
+})
@@ -0,0 +1,652 @@
+package components
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	"github.com/metaprov/modela-operator/pkg/vault"
+	"github.com/metaprov/modelaapi/pkg/util"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/url"
+	"path"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strconv"
+	"strings"
+
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+)
+
+// The names of the components backed up by a ModelaBackupRun, which are also the names of the containers of
+// the backup job
+const (
+	BackupComponentPostgres     = "postgres"
+	BackupComponentMongoDB      = "mongodb"
+	BackupComponentVault        = "vault"
+	BackupComponentVaultSecrets = "vault-secrets"
+	BackupComponentResources    = "resources"
+	BackupComponentUpload       = "upload"
+)
+
+// bundledMinioURL is the URL of the bundled Minio server inside the cluster
+const bundledMinioURL = "http://modela-storage-minio.modela-system.svc.cluster.local:9000"
+
+const (
+	backupPostgresScript = `set -eo pipefail
+mkdir -p /backup/postgres
+pg_dumpall --clean --if-exists --no-role-passwords | gzip > /backup/postgres/dumpall.sql.gz
+`
+
+	backupMongoScript = `set -e
+mkdir -p /backup/mongodb
+mongodump --host "$MONGO_HOST" --port "$MONGO_PORT" $MONGO_TLS_OPTIONS -u "$MONGO_USER" -p "$MONGO_PASSWORD" \
+  --authenticationDatabase admin --gzip --archive=/backup/mongodb/mongodb.archive.gz
+`
+
+	backupVaultScript = `set -e
+` + vaultLoginScript + `
+mkdir -p /backup/vault
+vault operator raft snapshot save /backup/vault/vault.snap
+`
+
+	// backupVaultSecretsScript exports the latest version of each secret of the mount when Vault does not run in
+	// high-availability mode, as its file storage does not support snapshots. The exported paths are listed in
+	// secrets.txt, and secrets whose latest version is deleted are skipped.
+	backupVaultSecretsScript = `set -e
+` + vaultLoginScript + `
+mkdir -p /backup/vault-secrets/kv
+touch /backup/vault-secrets/secrets.txt
+export_secrets() {
+  local keys key
+  if ! keys=$(vault kv list -format=json "$VAULT_MOUNT_PATH/$1" 2>/tmp/error); then
+    grep -q "No value found" /tmp/error && return 0
+    cat /tmp/error >&2
+    exit 1
+  fi
+  for key in $(echo "$keys" | tr -d '[]", '); do
+    case "$key" in
+      */) export_secrets "$1$key" ;;
+      *)
+        mkdir -p "$(dirname "/backup/vault-secrets/kv/$1$key")"
+        if ! vault kv get -format=json -field=data "$VAULT_MOUNT_PATH/$1$key" > "/backup/vault-secrets/kv/$1$key.json" 2>/tmp/error; then
+          rm -f "/backup/vault-secrets/kv/$1$key.json"
+          grep -q "No value found" /tmp/error && continue
+          cat /tmp/error >&2
+          exit 1
+        fi
+        echo "$1$key" >> /backup/vault-secrets/secrets.txt
+        ;;
+    esac
+  done
+}
+export_secrets ""
+`
+
+	// backupResourcesScript exports the Modela installations and tenants, and the custom resources of the Modela
+	// API inside each namespace created by the operator
+	backupResourcesScript = `set -eo pipefail
+mkdir -p /backup/resources
+kubectl get modelas.management.modela.ai,modelatenants.management.modela.ai -A -o yaml > /backup/resources/management.yaml
+resources=$(kubectl api-resources --namespaced --verbs=list -o name | grep '\.modela\.ai$' | grep -v '\.management\.modela\.ai$' | sort)
+for namespace in $(kubectl get namespaces -l "management.modela.ai/operator=$MODELA" -o jsonpath='{.items[*].metadata.name}'); do
+  mkdir -p "/backup/resources/$namespace"
+  for resource in $resources; do
+    kubectl get "$resource" -n "$namespace" -o yaml > "/backup/resources/$namespace/$resource.yaml"
+  done
+done
+`
+
+	// backupUploadScript uploads the dumps to the target and mirrors the buckets of the object storage. The
+	// manifest is uploaded last, so that only complete backups contain one.
+	backupUploadScript = `set -eo pipefail
+export MC_CONFIG_DIR=/tmp/.mc
+mkdir -p $MC_CONFIG_DIR/certs/CAs
+if [ -f "$TARGET_CA" ]; then cp "$TARGET_CA" $MC_CONFIG_DIR/certs/CAs/target.crt; fi
+if [ -f "$MINIO_CA" ]; then cp "$MINIO_CA" $MC_CONFIG_DIR/certs/CAs/source.crt; fi
+mc alias set target "$TARGET_URL" "$TARGET_ACCESS_KEY" "$TARGET_SECRET_KEY" >/dev/null
+mc mb --ignore-existing ${TARGET_REGION:+--region "$TARGET_REGION"} "target/$TARGET_BUCKET"
+
+cd /backup
+find . -type f | sort | xargs -r sha256sum > /tmp/SHA256SUMS
+mv /tmp/SHA256SUMS SHA256SUMS
+mc cp --recursive /backup/ "target/$TARGET_BUCKET/$FOLDER/"
+
+if [ -n "$MINIO_URL" ]; then
+  mc alias set source "$MINIO_URL" "$MINIO_ACCESS_KEY" "$MINIO_SECRET_KEY" >/dev/null
+  for bucket in $(mc ls source | awk '{print $NF}' | tr -d /); do
+    if [ "$MINIO_URL" = "$TARGET_URL" ] && [ "$bucket" = "$TARGET_BUCKET" ]; then continue; fi
+    mc mirror --overwrite "source/$bucket" "target/$TARGET_BUCKET/$FOLDER/buckets/$bucket"
+  done
+fi
+
+cat > /tmp/manifest.json <<EOF
+{"modela": "$MODELA", "distribution": "$DISTRIBUTION", "components": "$COMPONENTS", "createdAt": "$(date -u +%Y-%m-%dT%H:%M:%SZ)"}
+EOF
+mc cp /tmp/manifest.json "target/$TARGET_BUCKET/$FOLDER/manifest.json"
+`
+)
+
+// Backup takes backups of a Modela installation into S3-compatible object storage. Each backup runs as a job whose
+// init containers dump Postgres and MongoDB, take a snapshot of Vault or export its secrets, and export the Modela
+// API resources of the tenants into a shared volume, and whose main container uploads the volume and mirrors the
+// buckets of the object storage into a folder of the target bucket.
+type Backup struct {
+	Namespace      string
+	ServiceAccount string
+	KubectlImage   string
+	MinioImage     string
+}
+
+func NewBackup() *Backup {
+	return &Backup{
+		Namespace:      "modela-system",
+		ServiceAccount: "modela-backup",
+		KubectlImage:   "docker.io/bitnami/kubectl:1.25.9",
+		MinioImage:     minioClientImage,
+	}
+}
+
+// backupTarget is the object storage which stores a backup, with the defaults of the Modela installation applied
+type backupTarget struct {
+	URL       string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	CA        *v1.SecretKeySelector
+}
+
+// Components returns the names of the components backed up from the Modela installation
+func (b Backup) Components(modela *managementv1.Modela) []string {
+	components := []string{BackupComponentPostgres}
+	if modela.Spec.Database.InstallMongoDB {
+		components = append(components, BackupComponentMongoDB)
+	}
+	// Only the Raft storage of Vault in high-availability mode supports snapshots, so the secrets of the mount are
+	// exported otherwise
+	if modela.Spec.Vault.Install && modela.Spec.Vault.HA.Enabled {
+		components = append(components, BackupComponentVault)
+	} else if modela.Spec.Vault.Install {
+		components = append(components, BackupComponentVaultSecrets)
+	}
+	return append(components, BackupComponentResources, BackupComponentUpload)
+}
+
+// Folder returns the folder of the target bucket which contains the backup of a run
+func (b Backup) Folder(modela *managementv1.Modela, run *managementv1.ModelaBackupRun) string {
+	return path.Join(modela.Name, run.Name)
+}
+
+// JobName returns the name of the job of a run, which is unique across the namespaces of runs
+func (b Backup) JobName(run *managementv1.ModelaBackupRun) string {
+	checksum := sha256.Sum256([]byte(run.Namespace + "/" + run.Name))
+	name := "modela-backup-" + invalidJobNameCharacters.ReplaceAllString(strings.ToLower(run.Name), "-")
+	if len(name) > 54 {
+		name = name[:54]
+	}
+	return strings.TrimRight(name, "-") + "-" + hex.EncodeToString(checksum[:4])
+}
+
+// Start creates the job of a run, if it does not exist
+func (b Backup) Start(ctx context.Context, modela *managementv1.Modela, run *managementv1.ModelaBackupRun) error {
+	name := b.JobName(run)
+	if job, err := kube.GetJob(b.Namespace, name); err != nil || job != nil {
+		return err
+	}
+
+	if err := b.prepare(modela); err != nil {
+		return err
+	}
+
+	target, err := b.target(modela, run.Spec.Target)
+	if err != nil {
+		return err
+	}
+
+	components := b.Components(modela)
+	env := map[string]string{
+		"MODELA":            modela.Name,
+		"DISTRIBUTION":      modela.Spec.Distribution,
+		"COMPONENTS":        strings.Join(components, ","),
+		"FOLDER":            b.Folder(modela, run),
+		"TARGET_URL":        target.URL,
+		"TARGET_BUCKET":     target.Bucket,
+		"TARGET_REGION":     target.Region,
+		"TARGET_ACCESS_KEY": target.AccessKey,
+		"TARGET_SECRET_KEY": target.SecretKey,
+	}
+	cas := map[string]*v1.SecretKeySelector{"target": target.CA}
+	if target.CA != nil {
+		env["TARGET_CA"] = backupCAPath("target")
+	}
+	if err := databaseEnv(b.Namespace, modela, env, cas); err != nil {
+		return err
+	}
+	if err := b.objectStorageEnv(modela, env, cas); err != nil {
+		return err
+	}
+
+	// The environment contains credentials, so it is passed to the job through a Secret
+	labels := map[string]string{
+		"management.modela.ai/operator":   modela.Name,
+		"management.modela.ai/backup-run": run.Name,
+	}
+	if err := kube.CreateOrUpdateLabeledSecret(b.Namespace, name, labels, env); err != nil {
+		return err
+	}
+
+	var initContainers []v1.Container
+	for _, component := range components {
+		var container v1.Container
+		switch component {
+		case BackupComponentPostgres:
+			container = v1.Container{Image: postgresClientImage, Command: []string{"bash", "-c", backupPostgresScript}}
+		case BackupComponentMongoDB:
+			container = v1.Container{Image: mongoClientImage, Command: []string{"bash", "-c", backupMongoScript}}
+		case BackupComponentVault, BackupComponentVaultSecrets:
+			script := backupVaultScript
+			if component == BackupComponentVaultSecrets {
+				script = backupVaultSecretsScript
+			}
+			snapshots := NewVaultSnapshot()
+			container = snapshots.vaultContainer(modela, component, script, snapshots.SnapshotRole)
+			container.VolumeMounts = container.VolumeMounts[1:]
+		case BackupComponentResources:
+			container = v1.Container{Image: b.KubectlImage, Command: []string{"bash", "-c", backupResourcesScript}}
+		case BackupComponentUpload:
+			continue
+		}
+		initContainers = append(initContainers, jobContainer(name, component, container, cas))
+	}
+	upload := jobContainer(name, BackupComponentUpload, v1.Container{
+		Image:   b.MinioImage,
+		Command: []string{"bash", "-c", backupUploadScript},
+	}, cas)
+
+	log.FromContext(ctx).Info("Starting backup", "run", run.Name, "job", name, "components", components)
+	return kube.CreateJob(b.job(modela, name, labels, cas, initContainers, upload))
+}
+
+// job returns a job of the service account of the backups which runs the containers in order. The containers share
+// the backup volume and read their environment from the Secret with the name of the job.
+func (b Backup) job(modela *managementv1.Modela, name string, labels map[string]string, cas map[string]*v1.SecretKeySelector,
+	initContainers []v1.Container, container v1.Container) *batchv1.Job {
+	volumes := jobVolumes(cas)
+	if modela.Spec.Vault.TLS.Enabled {
+		volumes = append(volumes, v1.Volume{Name: "vault-tls", VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{SecretName: vault.TLSSecretName},
+		}})
+	}
+	return newJob(b.Namespace, b.ServiceAccount, name, labels, volumes, initContainers, container)
+}
+
+// newJob returns a job of a service account which runs the containers in order with the volumes. The job is deleted
+// by Kubernetes a day after it finished.
+func newJob(ns string, serviceAccount string, name string, labels map[string]string, volumes []v1.Volume,
+	initContainers []v1.Container, container v1.Container) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns, Labels: labels},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            util.Int32Ptr(0),
+			TTLSecondsAfterFinished: util.Int32Ptr(24 * 60 * 60),
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: v1.PodSpec{
+					ServiceAccountName: serviceAccount,
+					RestartPolicy:      v1.RestartPolicyNever,
+					InitContainers:     initContainers,
+					Containers:         []v1.Container{container},
+					Volumes:            volumes,
+				},
+			},
+		},
+	}
+}
+
+// jobVolumes returns the shared backup volume of a job and the volumes of the CA bundles of the external services
+// mounted by jobContainer
+func jobVolumes(cas map[string]*v1.SecretKeySelector) []v1.Volume {
+	volumes := []v1.Volume{{Name: "backup", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}}}
+	for _, component := range sortedKeys(backupCASecrets(cas)) {
+		ca := cas[component]
+		volumes = append(volumes, v1.Volume{Name: component + "-ca", VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{
+			SecretName: ca.Name,
+			Items:      []v1.KeyToPath{{Key: ca.Key, Path: "ca.crt"}},
+		}}})
+	}
+	return volumes
+}
+
+// Status returns the job of a run, or nil if it does not exist, and the status of the backup of each component
+// reported by the containers of the job
+func (b Backup) Status(run *managementv1.ModelaBackupRun) (*batchv1.Job, []managementv1.BackupComponentStatus, error) {
+	name := b.JobName(run)
+	job, err := kube.GetJob(b.Namespace, name)
+	if err != nil || job == nil {
+		return job, nil, err
+	}
+
+	components, err := jobContainerStatuses(b.Namespace, name)
+	return job, components, err
+}
+
+// jobContainerStatuses returns the status of each container of the last pod of a job, in the order in which they
+// run
+func jobContainerStatuses(ns string, job string) ([]managementv1.BackupComponentStatus, error) {
+	pods, err := kube.ListPods(ns, "job-name="+job)
+	if err != nil || len(pods) == 0 {
+		return nil, err
+	}
+
+	var components []managementv1.BackupComponentStatus
+	pod := pods[len(pods)-1]
+	for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		component := managementv1.BackupComponentStatus{Name: status.Name, Phase: managementv1.BackupRunPhasePending}
+		if terminated := status.State.Terminated; terminated != nil {
+			component.Phase = managementv1.BackupRunPhaseCompleted
+			if terminated.ExitCode != 0 {
+				component.Phase = managementv1.BackupRunPhaseFailed
+				component.Message = strings.TrimSpace(terminated.Message)
+				if component.Message == "" {
+					component.Message = fmt.Sprintf("%s with exit code %d", terminated.Reason, terminated.ExitCode)
+				}
+			}
+		} else if status.State.Running != nil {
+			component.Phase = managementv1.BackupRunPhaseRunning
+		} else if waiting := status.State.Waiting; waiting != nil && waiting.Reason != "PodInitializing" {
+			component.Message = waiting.Reason
+		}
+		components = append(components, component)
+	}
+	return components, nil
+}
+
+// Cleanup deletes the Secret which holds the credentials of the job of a finished run. The job is deleted by
+// Kubernetes a day after it finished.
+func (b Backup) Cleanup(run *managementv1.ModelaBackupRun) error {
+	return kube.DeleteSecret(b.Namespace, b.JobName(run))
+}
+
+// Purge deletes the job of a run and the backup stored in the target bucket
+func (b Backup) Purge(ctx context.Context, modela *managementv1.Modela, run *managementv1.ModelaBackupRun) error {
+	if err := kube.DeleteJob(b.Namespace, b.JobName(run)); err != nil {
+		return err
+	}
+	if err := b.Cleanup(run); err != nil {
+		return err
+	}
+	if run.Status.Folder == "" {
+		return nil
+	}
+
+	target, err := b.target(modela, run.Spec.Target)
+	if err != nil {
+		return err
+	}
+	client, err := target.client()
+	if err != nil {
+		return err
+	}
+	if exists, err := client.BucketExists(ctx, target.Bucket); err != nil {
+		return errors.Wrapf(err, "Failed to access backup bucket %s", target.Bucket)
+	} else if !exists {
+		return nil
+	}
+
+	log.FromContext(ctx).Info("Deleting backup", "run", run.Name, "folder", run.Status.Folder)
+	objects := make(chan minio.ObjectInfo)
+	var listErr error
+	go func() {
+		defer close(objects)
+		for object := range client.ListObjects(ctx, target.Bucket, minio.ListObjectsOptions{Prefix: run.Status.Folder + "/", Recursive: true}) {
+			if object.Err != nil {
+				listErr = object.Err
+				return
+			}
+			objects <- object
+		}
+	}()
+
+	var removeErr error
+	for result := range client.RemoveObjects(ctx, target.Bucket, objects, minio.RemoveObjectsOptions{}) {
+		if removeErr == nil {
+			removeErr = errors.Wrapf(result.Err, "Failed to delete %s from backup bucket %s", result.ObjectName, target.Bucket)
+		}
+	}
+	if listErr != nil {
+		return errors.Wrapf(listErr, "Failed to list backup %s", run.Status.Folder)
+	}
+	return removeErr
+}
+
+// prepare creates the service account of the backup jobs, which may read the Modela API resources of every
+// namespace, and is bound to the snapshot role of Vault
+func (b Backup) prepare(modela *managementv1.Modela) error {
+	labels := map[string]string{"management.modela.ai/operator": modela.Name}
+	if err := kube.CreateServiceAccount(b.Namespace, b.ServiceAccount, labels); err != nil {
+		return err
+	}
+
+	rules := []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"get", "list"}}}
+	for _, group := range []string{"management", "catalog", "team", "data", "inference", "infra", "training"} {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{group + ".modela.ai"},
+			Resources: []string{"*"},
+			Verbs:     []string{"get", "list"},
+		})
+	}
+	if err := kube.ApplyClusterRole(&rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: b.ServiceAccount, Labels: labels},
+		Rules:      rules,
+	}); err != nil {
+		return err
+	}
+	return kube.ApplyClusterRoleBinding(&rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: b.ServiceAccount, Labels: labels},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: b.ServiceAccount},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: b.ServiceAccount, Namespace: b.Namespace}},
+	})
+}
+
+// jobContainer returns a container of a job, which mounts the shared backup volume, the CA bundles of the external
+// services and the Secret which holds the environment of the job
+func jobContainer(job string, name string, container v1.Container, cas map[string]*v1.SecretKeySelector) v1.Container {
+	container.Name = name
+	container.TerminationMessagePolicy = v1.TerminationMessageFallbackToLogsOnError
+	container.EnvFrom = []v1.EnvFromSource{{SecretRef: &v1.SecretEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: job}}}}
+	container.VolumeMounts = append([]v1.VolumeMount{{Name: "backup", MountPath: "/backup"}}, container.VolumeMounts...)
+	for _, component := range sortedKeys(backupCASecrets(cas)) {
+		container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{
+			Name:      component + "-ca",
+			MountPath: path.Dir(backupCAPath(component)),
+			ReadOnly:  true,
+		})
+	}
+	return container
+}
+
+// databaseEnv adds the connection of the Postgres and MongoDB servers of the installation to the environment of a
+// job, reading the admin credentials of the servers from the namespace
+func databaseEnv(ns string, modela *managementv1.Modela, env map[string]string, cas map[string]*v1.SecretKeySelector) error {
+	postgres := map[string]string{"PGHOST": NewPostgresDatabase().Host(modela), "PGPORT": "5432", "PGUSER": "postgres"}
+	if service, ok := externalService(modela, "postgres"); ok {
+		admin, err := kube.GetSecretValuesAsString(ns, service.Spec.CredentialsSecretRef.Name)
+		if err != nil {
+			return errors.Wrap(err, "Failed to get the credentials of the external postgres")
+		}
+		postgres["PGHOST"], postgres["PGPORT"] = service.Spec.Host, strconv.Itoa(int(service.Port()))
+		postgres["PGUSER"], postgres["PGPASSWORD"], postgres["PGSSLMODE"] = admin["username"], admin["password"], "prefer"
+		if service.Spec.TLS.Enabled {
+			postgres["PGSSLMODE"] = "require"
+			if cas[BackupComponentPostgres] = service.Spec.TLS.CASecretRef; cas[BackupComponentPostgres] != nil {
+				postgres["PGSSLMODE"], postgres["PGSSLROOTCERT"] = "verify-full", backupCAPath(BackupComponentPostgres)
+			}
+		}
+	} else {
+		admin, err := kube.GetSecretValuesAsString(ns, "modela-postgresql")
+		if err != nil {
+			return errors.Wrap(err, "Failed to get the credentials of postgres")
+		}
+		postgres["PGPASSWORD"] = admin["postgres-password"]
+	}
+	postgres["PGDATABASE"] = "postgres"
+	for k, v := range postgres {
+		env[k] = v
+	}
+
+	if !modela.Spec.Database.InstallMongoDB {
+		return nil
+	}
+	mongo := map[string]string{"MONGO_HOST": NewMongoDatabase().ShellHost(modela), "MONGO_PORT": "27017", "MONGO_USER": "root"}
+	if service, ok := externalService(modela, "mongodb"); ok {
+		admin, err := kube.GetSecretValuesAsString(ns, service.Spec.CredentialsSecretRef.Name)
+		if err != nil {
+			return errors.Wrap(err, "Failed to get the credentials of the external mongodb")
+		}
+		mongo["MONGO_HOST"], mongo["MONGO_PORT"] = service.Spec.Host, strconv.Itoa(int(service.Port()))
+		mongo["MONGO_USER"], mongo["MONGO_PASSWORD"] = admin["username"], admin["password"]
+		if service.Spec.TLS.Enabled {
+			// The database tools use the legacy names of the TLS options
+			mongo["MONGO_TLS_OPTIONS"] = "--ssl"
+			if cas[BackupComponentMongoDB] = service.Spec.TLS.CASecretRef; cas[BackupComponentMongoDB] != nil {
+				mongo["MONGO_TLS_OPTIONS"] = "--ssl --sslCAFile " + backupCAPath(BackupComponentMongoDB)
+			}
+		}
+	} else {
+		admin, err := kube.GetSecretValuesAsString(ns, "modela-mongodb")
+		if err != nil {
+			return errors.Wrap(err, "Failed to get the credentials of mongodb")
+		}
+		mongo["MONGO_PASSWORD"] = admin["mongodb-root-password"]
+	}
+	for k, v := range mongo {
+		env[k] = v
+	}
+	return nil
+}
+
+// objectStorageEnv adds the connection of the object storage of the installation, whose buckets are mirrored into
+// the backup, to the environment of the backup job
+func (b Backup) objectStorageEnv(modela *managementv1.Modela, env map[string]string, cas map[string]*v1.SecretKeySelector) error {
+	if service, ok := externalService(modela, "object storage"); ok {
+		admin, err := kube.GetSecretValuesAsString(b.Namespace, service.Spec.CredentialsSecretRef.Name)
+		if err != nil {
+			return errors.Wrap(err, "Failed to get the credentials of the external object storage")
+		}
+		env["MINIO_URL"], env["MINIO_ACCESS_KEY"], env["MINIO_SECRET_KEY"] = objectStorageURL(service), admin["accessKey"], admin["secretKey"]
+		if cas["source"] = service.Spec.TLS.CASecretRef; cas["source"] != nil {
+			env["MINIO_CA"] = backupCAPath("source")
+		}
+		return nil
+	} else if !modela.Spec.ObjectStore.Install {
+		return nil
+	}
+
+	admin, err := kube.GetSecretValuesAsString(b.Namespace, "modela-storage-minio")
+	if err != nil {
+		return errors.Wrap(err, "Failed to get the credentials of the object storage")
+	}
+	env["MINIO_URL"], env["MINIO_ACCESS_KEY"], env["MINIO_SECRET_KEY"] = bundledMinioURL, admin["root-user"], admin["root-password"]
+	return nil
+}
+
+// target returns the object storage which stores backups. When the spec does not reference an endpoint, backups
+// are stored in the object storage of the Modela installation.
+func (b Backup) target(modela *managementv1.Modela, spec managementv1.BackupTargetSpec) (backupTarget, error) {
+	target := backupTarget{URL: spec.Endpoint, Bucket: spec.Bucket, Region: spec.Region, CA: spec.CASecretRef}
+	if target.Bucket == "" {
+		target.Bucket = "modela-backups"
+	}
+
+	secret, keys := "", [2]string{"accessKey", "secretKey"}
+	if ref := spec.CredentialsSecretRef; ref != nil {
+		secret = ref.Name
+	}
+	if target.URL == "" {
+		if service, ok := externalService(modela, "object storage"); ok {
+			target.URL, target.CA = objectStorageURL(service), service.Spec.TLS.CASecretRef
+			if target.Region == "" {
+				target.Region = modela.Spec.ObjectStore.External.Region
+			}
+			if secret == "" {
+				secret = service.Spec.CredentialsSecretRef.Name
+			}
+		} else {
+			target.URL = bundledMinioURL
+			if secret == "" {
+				secret, keys = "modela-storage-minio", [2]string{"root-user", "root-password"}
+			}
+		}
+	}
+	if secret == "" {
+		return target, errors.New("The backup target must reference a credentials Secret when an endpoint is set")
+	}
+
+	credentials, err := kube.GetSecretValuesAsString(b.Namespace, secret)
+	if err != nil {
+		return target, errors.Wrap(err, "Failed to get the credentials of the backup target")
+	}
+	target.AccessKey, target.SecretKey = credentials[keys[0]], credentials[keys[1]]
+	if target.AccessKey == "" || target.SecretKey == "" {
+		return target, errors.Errorf("The credentials of the backup target are missing the %s or %s key", keys[0], keys[1])
+	}
+	return target, nil
+}
+
+// client returns a client of the object storage of the target
+func (t backupTarget) client() (*minio.Client, error) {
+	endpoint, err := url.Parse(t.URL)
+	if err != nil || endpoint.Host == "" {
+		return nil, errors.Errorf("Invalid backup target endpoint %s", t.URL)
+	}
+
+	transport, err := minio.DefaultTransport(endpoint.Scheme == "https")
+	if err != nil {
+		return nil, err
+	}
+	if t.CA != nil {
+		secret, err := kube.GetSecret("modela-system", t.CA.Name)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to get the CA bundle of the backup target")
+		}
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.RootCAs = x509.NewCertPool()
+		if !transport.TLSClientConfig.RootCAs.AppendCertsFromPEM(secret.Data[t.CA.Key]) {
+			return nil, errors.New("The CA bundle of the backup target does not contain a PEM-encoded certificate")
+		}
+	}
+
+	return minio.New(endpoint.Host, &minio.Options{
+		Creds:     credentials.NewStaticV4(t.AccessKey, t.SecretKey, ""),
+		Secure:    endpoint.Scheme == "https",
+		Region:    t.Region,
+		Transport: transport,
+	})
+}
+
+// backupCAPath returns the path at which the CA bundle of a service is mounted into the backup job
+func backupCAPath(name string) string {
+	return fmt.Sprintf("/etc/modela/%s-ca/ca.crt", name)
+}
+
+// backupCASecrets returns the CA bundles which are referenced
+func backupCASecrets(cas map[string]*v1.SecretKeySelector) map[string]string {
+	secrets := make(map[string]string, len(cas))
+	for name, ca := range cas {
+		if ca != nil {
+			secrets[name] = ca.Name
+		}
+	}
+	return secrets
+}
@@ -0,0 +1,52 @@
+package components
+
+import (
+	"github.com/metaprov/modela-operator/api/v1alpha1"
+	"github.com/metaprov/modela-operator/pkg/vault"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
+)
+
+var _ = Describe("Backup", func() {
+	backup := NewBackup()
+
+	It("Should back up the components of the installation", func() {
+		modela := &v1alpha1.Modela{Spec: v1alpha1.ModelaSpec{}}
+		Expect(backup.Components(modela)).To(Equal([]string{"postgres", "resources", "upload"}))
+
+		modela.Spec.Database.InstallMongoDB = true
+		modela.Spec.Vault = v1alpha1.VaultSpec{Install: true, HA: v1alpha1.VaultHASpec{Enabled: true}}
+		Expect(backup.Components(modela)).To(Equal([]string{"postgres", "mongodb", "vault", "resources", "upload"}))
+	})
+
+	It("Should export the secrets of Vault without high availability", func() {
+		modela := &v1alpha1.Modela{Spec: v1alpha1.ModelaSpec{Vault: v1alpha1.VaultSpec{Install: true, MountPath: "/modela/secrets/"}}}
+		Expect(backup.Components(modela)).To(Equal([]string{"postgres", "vault-secrets", "resources", "upload"}))
+
+		container := NewVaultSnapshot().vaultContainer(modela, BackupComponentVaultSecrets, backupVaultSecretsScript, "role")
+		Expect(container.Env).To(ContainElement(v1.EnvVar{Name: "VAULT_MOUNT_PATH", Value: "modela/secrets"}))
+		Expect(container.Env).To(ContainElement(v1.EnvVar{Name: "VAULT_ADDR", Value: "http://" + vault.ServiceHost}))
+
+		modela.Spec.Vault.HA.Enabled = true
+		container = NewVaultSnapshot().vaultContainer(modela, BackupComponentVault, backupVaultScript, "role")
+		Expect(container.Env).To(ContainElement(v1.EnvVar{Name: "VAULT_ADDR", Value: "http://modela-vault-active.modela-system.svc:8200"}))
+	})
+
+	It("Should name the job of each run uniquely", func() {
+		run := &v1alpha1.ModelaBackupRun{ObjectMeta: metav1.ObjectMeta{
+			Name:      "modela-" + strings.Repeat("nightly-", 8) + "20230501020000",
+			Namespace: "modela-system",
+		}}
+		name := backup.JobName(run)
+		Expect(len(name)).To(BeNumerically("<=", 63))
+		Expect(name).To(HavePrefix("modela-backup-modela-nightly-"))
+
+		other := run.DeepCopy()
+		other.Namespace = "default"
+		Expect(backup.JobName(other)).NotTo(Equal(name))
+		Expect(backup.Folder(&v1alpha1.Modela{ObjectMeta: metav1.ObjectMeta{Name: "modela"}}, run)).To(Equal("modela/" + run.Name))
+	})
+})
@@ -0,0 +1,264 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"github.com/metaprov/modela-operator/pkg/helm"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strings"
+
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+)
+
+const (
+	// postgresReplicationScript prints the number of read replicas which stream from the primary, and the number
+	// of synchronous replicas among them
+	postgresReplicationScript = `psql -tA -v ON_ERROR_STOP=1 -d postgres -F ' ' -c "SELECT count(*), ` +
+		`count(*) FILTER (WHERE sync_state IN ('sync', 'quorum')) FROM pg_stat_replication WHERE state = 'streaming'"`
+	// mongoReplicationScript prints the number of healthy data-bearing members of the replica set, and whether the
+	// replica set has a primary
+	mongoReplicationScript = mongoShell + ` --eval "
+const members = rs.status().members;
+print(members.filter(m => m.health === 1 && ['PRIMARY', 'SECONDARY'].includes(m.stateStr)).length, members.some(m => m.stateStr === 'PRIMARY'))"`
+)
+
+// databaseReplicas returns the number of servers of each highly available database
+func databaseReplicas(modela *managementv1.Modela) int {
+	return modela.Spec.Database.HA.ReplicaCount()
+}
+
+// synchronousReplicas returns the number of Postgres read replicas which confirm each transaction
+func synchronousReplicas(modela *managementv1.Modela) int {
+	if replicas := modela.Spec.Database.HA.SynchronousReplicas; replicas != nil {
+		return *replicas
+	}
+	return 1
+}
+
+// Primary returns the name of the service and stateful set of the primary Postgres server, which is renamed by the
+// chart when Postgres replicates to read replicas
+func (db Postgres) Primary(modela *managementv1.Modela) string {
+	if modela.Spec.Database.HA.Enabled {
+		return db.ReleaseName + "-primary"
+	}
+	return db.ReleaseName
+}
+
+// Host returns the address of the read-write service of Postgres
+func (db Postgres) Host(modela *managementv1.Modela) string {
+	return fmt.Sprintf("%s.%s.svc.cluster.local", db.Primary(modela), db.Namespace)
+}
+
+// replicationValues returns the chart values of the replicas of Postgres, which may be applied to an installed
+// release to scale it
+func (db Postgres) replicationValues(modela *managementv1.Modela) map[string]interface{} {
+	synchronousCommit := "on"
+	if synchronousReplicas(modela) == 0 {
+		synchronousCommit = "off"
+	}
+	return map[string]interface{}{
+		"readReplicas": map[string]interface{}{"replicaCount": int64(databaseReplicas(modela) - 1)},
+		"replication": map[string]interface{}{
+			"synchronousCommit":      synchronousCommit,
+			"numSynchronousReplicas": int64(synchronousReplicas(modela)),
+		},
+	}
+}
+
+// applyHA configures the chart to replicate the primary server to read replicas
+func (db Postgres) applyHA(values map[string]interface{}, modela *managementv1.Modela) error {
+	values["architecture"] = "replication"
+	return mergeValues(values, db.replicationValues(modela))
+}
+
+// ScaleReplicas applies the number of replicas of the spec to the installed release. It returns true if the
+// release was upgraded.
+func (db Postgres) ScaleReplicas(ctx context.Context, modela *managementv1.Modela) (bool, error) {
+	return upgradeReleaseValues(ctx, db.Name, db.Namespace, db.ReleaseName, db.replicationValues(modela))
+}
+
+// ReplicationHealth returns a description of the problem of the replication of Postgres, or an empty string if
+// every read replica streams from the primary and enough of them are synchronous
+func (db Postgres) ReplicationHealth(ctx context.Context, modela *managementv1.Modela) (string, error) {
+	if architecture, err := releaseValue(ctx, db.Name, db.Namespace, db.ReleaseName, "architecture"); err != nil {
+		return "", err
+	} else if architecture != "replication" {
+		return "Postgres was installed without replication, and its topology cannot be changed", nil
+	}
+
+	admin, err := kube.GetSecretValuesAsString(db.Namespace, db.ReleaseName)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to get the credentials of Postgres")
+	}
+	output, err := postgresClient(modela, "modela-postgres-replication", postgresReplicationScript, admin).output(ctx)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to read the replication status of Postgres")
+	}
+
+	var streaming, synchronous int
+	if _, err := fmt.Sscan(output, &streaming, &synchronous); err != nil {
+		return "", errors.Wrapf(err, "Failed to parse the replication status of Postgres %q", output)
+	}
+	if expected := databaseReplicas(modela) - 1; streaming < expected {
+		return fmt.Sprintf("%d of %d Postgres read replicas are streaming", streaming, expected), nil
+	}
+	if expected := synchronousReplicas(modela); synchronous < expected {
+		return fmt.Sprintf("%d of %d Postgres read replicas are synchronous", synchronous, expected), nil
+	}
+	return "", nil
+}
+
+// ReplicaSetName returns the name of the replica set of MongoDB
+func (db Mongo) ReplicaSetName(modela *managementv1.Modela) string {
+	if name := modela.Spec.Database.HA.ReplicaSetName; name != "" {
+		return name
+	}
+	return "rs0"
+}
+
+// Hosts returns the addresses of the servers of MongoDB. The members of a replica set are addressed through the
+// headless service of the chart.
+func (db Mongo) Hosts(modela *managementv1.Modela) []string {
+	if !modela.Spec.Database.HA.Enabled {
+		return []string{fmt.Sprintf("%s.%s.svc.cluster.local", db.ReleaseName, db.Namespace)}
+	}
+	hosts := make([]string, databaseReplicas(modela))
+	for i := range hosts {
+		hosts[i] = fmt.Sprintf("%s-%d.%s-headless.%s.svc.cluster.local", db.ReleaseName, i, db.ReleaseName, db.Namespace)
+	}
+	return hosts
+}
+
+// ShellHost returns the host option of the MongoDB shell and tools, which is the seed list of the replica set when
+// MongoDB is highly available, so that writes are sent to the primary
+func (db Mongo) ShellHost(modela *managementv1.Modela) string {
+	if !modela.Spec.Database.HA.Enabled {
+		return db.Hosts(modela)[0]
+	}
+	return db.ReplicaSetName(modela) + "/" + strings.Join(db.Hosts(modela), ",")
+}
+
+// URI returns the connection string of a database of MongoDB for a user of that database
+func (db Mongo) URI(modela *managementv1.Modela, database string, username string, password string) string {
+	uri := fmt.Sprintf("mongodb://%s:%s@%s/%s?authSource=%s", username, password,
+		strings.Join(db.Hosts(modela), ":27017,")+":27017", database, database)
+	if modela.Spec.Database.HA.Enabled {
+		uri += "&replicaSet=" + db.ReplicaSetName(modela)
+	}
+	return uri
+}
+
+// replicationValues returns the chart values of the members of the replica set, which may be applied to an
+// installed release to scale it. An arbiter is added to a replica set with an even number of members, so that a
+// primary can be elected when a member is lost.
+func (db Mongo) replicationValues(modela *managementv1.Modela) map[string]interface{} {
+	return map[string]interface{}{
+		"replicaCount": int64(databaseReplicas(modela)),
+		"arbiter":      map[string]interface{}{"enabled": databaseReplicas(modela)%2 == 0},
+	}
+}
+
+// applyHA configures the chart to install MongoDB as a replica set
+func (db Mongo) applyHA(values map[string]interface{}, modela *managementv1.Modela) error {
+	values["architecture"] = "replicaset"
+	values["replicaSetName"] = db.ReplicaSetName(modela)
+	return mergeValues(values, db.replicationValues(modela))
+}
+
+// ScaleReplicas applies the number of members of the spec to the installed replica set. It returns true if the
+// release was upgraded.
+func (db Mongo) ScaleReplicas(ctx context.Context, modela *managementv1.Modela) (bool, error) {
+	return upgradeReleaseValues(ctx, db.Name, db.Namespace, db.ReleaseName, db.replicationValues(modela))
+}
+
+// ReplicationHealth returns a description of the problem of the replica set of MongoDB, or an empty string if
+// every member is healthy and the replica set has a primary
+func (db Mongo) ReplicationHealth(ctx context.Context, modela *managementv1.Modela) (string, error) {
+	if architecture, err := releaseValue(ctx, db.Name, db.Namespace, db.ReleaseName, "architecture"); err != nil {
+		return "", err
+	} else if architecture != "replicaset" {
+		return "MongoDB was installed without a replica set, and its topology cannot be changed", nil
+	}
+
+	admin, err := kube.GetSecretValuesAsString(db.Namespace, db.ReleaseName)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to get the credentials of MongoDB")
+	}
+	output, err := mongoClient(modela, "modela-mongodb-replication", mongoReplicationScript, admin).output(ctx)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to read the status of the MongoDB replica set")
+	}
+
+	var healthy int
+	var primary bool
+	if _, err := fmt.Sscan(output, &healthy, &primary); err != nil {
+		return "", errors.Wrapf(err, "Failed to parse the status of the MongoDB replica set %q", output)
+	}
+	if !primary {
+		return fmt.Sprintf("The MongoDB replica set %s has no primary", db.ReplicaSetName(modela)), nil
+	}
+	if expected := databaseReplicas(modela); healthy < expected {
+		return fmt.Sprintf("%d of %d members of the MongoDB replica set are healthy", healthy, expected), nil
+	}
+	return "", nil
+}
+
+// mergeValues sets the nested values into the chart values, keeping the other values of nested maps
+func mergeValues(values map[string]interface{}, nested map[string]interface{}, path ...string) error {
+	for key, value := range nested {
+		if child, ok := value.(map[string]interface{}); ok {
+			if err := mergeValues(values, child, append(path, key)...); err != nil {
+				return err
+			}
+		} else if err := unstructured.SetNestedField(values, value, append(path, key)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseValue returns a top-level value with which a release was installed or upgraded
+func releaseValue(ctx context.Context, name, ns, releaseName string, key string) (interface{}, error) {
+	release, err := helm.NewHelmChart(name, ns, releaseName, false).Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return release.Config[key], nil
+}
+
+// upgradeReleaseValues upgrades a release with the nested values when any of them differs from the values of the
+// release. It returns true if the release was upgraded.
+func upgradeReleaseValues(ctx context.Context, name, ns, releaseName string, values map[string]interface{}) (bool, error) {
+	release, err := helm.NewHelmChart(name, ns, releaseName, false).Get(ctx)
+	if err != nil {
+		return false, err
+	}
+	if !valuesDiffer(release.Config, values) {
+		return false, nil
+	}
+
+	log.FromContext(ctx).Info("Upgrading the replicas of release", "release", releaseName)
+	if err := helm.UpgradeChart(ctx, name, ns, releaseName, values); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// valuesDiffer returns true if any of the nested values differs from the values of a release. Numbers are
+// compared by their text, as the values of a release are decoded from JSON.
+func valuesDiffer(config map[string]interface{}, values map[string]interface{}) bool {
+	for key, value := range values {
+		if child, ok := value.(map[string]interface{}); ok {
+			current, _ := config[key].(map[string]interface{})
+			if valuesDiffer(current, child) {
+				return true
+			}
+		} else if current, ok := config[key]; !ok || fmt.Sprint(current) != fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package components
+
+import (
+	"github.com/metaprov/modela-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Database high availability", func() {
+	modela := &v1alpha1.Modela{Spec: v1alpha1.ModelaSpec{Database: v1alpha1.DatabaseSpec{
+		InstallMongoDB: true,
+		HA:             v1alpha1.DatabaseHASpec{Enabled: true, Replicas: 2},
+	}}}
+
+	It("Should render the chart values of replicated databases", func() {
+		values := map[string]interface{}{"readReplicas": map[string]interface{}{"resources": map[string]interface{}{}}}
+		Expect(NewPostgresDatabase().applyHA(values, modela)).To(Succeed())
+		Expect(values).To(Equal(map[string]interface{}{
+			"architecture": "replication",
+			"readReplicas": map[string]interface{}{"replicaCount": int64(1), "resources": map[string]interface{}{}},
+			"replication":  map[string]interface{}{"synchronousCommit": "on", "numSynchronousReplicas": int64(1)},
+		}))
+
+		values = map[string]interface{}{}
+		Expect(NewMongoDatabase().applyHA(values, modela)).To(Succeed())
+		Expect(values).To(Equal(map[string]interface{}{
+			"architecture":   "replicaset",
+			"replicaSetName": "rs0",
+			"replicaCount":   int64(2),
+			"arbiter":        map[string]interface{}{"enabled": true},
+		}))
+	})
+
+	It("Should address the primary Postgres server and the MongoDB replica set", func() {
+		Expect(NewPostgresDatabase().Host(modela)).To(Equal("modela-postgresql-primary.modela-system.svc.cluster.local"))
+		mongo := NewMongoDatabase()
+		Expect(mongo.ShellHost(modela)).To(Equal("rs0/modela-mongodb-0.modela-mongodb-headless.modela-system.svc.cluster.local," +
+			"modela-mongodb-1.modela-mongodb-headless.modela-system.svc.cluster.local"))
+		Expect(mongo.URI(modela, "acme", "acme", "secret")).To(Equal("mongodb://acme:secret@" +
+			"modela-mongodb-0.modela-mongodb-headless.modela-system.svc.cluster.local:27017," +
+			"modela-mongodb-1.modela-mongodb-headless.modela-system.svc.cluster.local:27017/acme?authSource=acme&replicaSet=rs0"))
+
+		standalone := &v1alpha1.Modela{}
+		Expect(NewPostgresDatabase().Host(standalone)).To(Equal("modela-postgresql.modela-system.svc.cluster.local"))
+		Expect(mongo.ShellHost(standalone)).To(Equal("modela-mongodb.modela-system.svc.cluster.local"))
+	})
+
+	It("Should only upgrade a release whose replicas changed", func() {
+		values := NewPostgresDatabase().replicationValues(modela)
+		release := map[string]interface{}{
+			"architecture": "replication",
+			"readReplicas": map[string]interface{}{"replicaCount": float64(1)},
+			"replication":  map[string]interface{}{"synchronousCommit": "on", "numSynchronousReplicas": float64(1)},
+		}
+		Expect(valuesDiffer(release, values)).To(BeFalse())
+		release["readReplicas"] = map[string]interface{}{"replicaCount": float64(2)}
+		Expect(valuesDiffer(release, values)).To(BeTrue())
+		Expect(valuesDiffer(nil, values)).To(BeTrue())
+	})
+
+	It("Should validate the number of synchronous replicas", func() {
+		synchronous := 2
+		ha := v1alpha1.DatabaseHASpec{Enabled: true, Replicas: 2, SynchronousReplicas: &synchronous}
+		Expect(ha.Validate()).NotTo(Succeed())
+		ha.Replicas = 3
+		Expect(ha.Validate()).To(Succeed())
+
+		ha.Replicas, synchronous = 1, 1
+		Expect(ha.ReplicaCount()).To(Equal(v1alpha1.DefaultDatabaseReplicas))
+		Expect(ha.Validate()).To(Succeed())
+		Expect(databaseReplicas(&v1alpha1.Modela{Spec: v1alpha1.ModelaSpec{Database: v1alpha1.DatabaseSpec{HA: ha}}})).To(Equal(3))
+	})
+
+	It("Should reject changing the topology of installed databases", func() {
+		installed := false
+		old := &v1alpha1.Modela{Status: v1alpha1.ModelaStatus{DatabaseHA: &installed}}
+		modela := old.DeepCopy()
+		modela.Spec.Database.HA.Enabled = true
+		Expect(modela.ValidateUpdate(old)).NotTo(Succeed())
+
+		old.Status.DatabaseHA = nil
+		Expect(modela.ValidateUpdate(old)).To(Succeed())
+	})
+})
@@ -0,0 +1,154 @@
+package components
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	"github.com/pkg/errors"
+	"net"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strconv"
+	"time"
+
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+)
+
+// externalCAPath is the path at which the CA bundle of an external service is mounted into the jobs which access it
+const externalCAPath = "/etc/modela/external-ca/ca.crt"
+
+// ExternalService is an externally managed service which is used in place of a bundled chart
+type ExternalService struct {
+	// Name is the name of the service, as reported by the preflight checks
+	Name string
+	// DefaultPort is the port used when the spec of the service does not set one
+	DefaultPort int32
+	// CredentialKeys are the keys which must exist in the credentials Secret of the service
+	CredentialKeys []string
+	Spec           managementv1.ExternalServiceSpec
+}
+
+// ExternalServices returns the external services configured by the Modela resource
+func ExternalServices(modela *managementv1.Modela) []ExternalService {
+	var services []ExternalService
+	if external := modela.Spec.Database.External.Postgres; external != nil {
+		services = append(services, ExternalService{
+			Name:           "postgres",
+			DefaultPort:    5432,
+			CredentialKeys: []string{"username", "password"},
+			Spec:           *external,
+		})
+	}
+	if external := modela.Spec.Database.External.MongoDB; external != nil && modela.Spec.Database.InstallMongoDB {
+		services = append(services, ExternalService{
+			Name:           "mongodb",
+			DefaultPort:    27017,
+			CredentialKeys: []string{"username", "password"},
+			Spec:           *external,
+		})
+	}
+	if external := modela.Spec.ObjectStore.External; external != nil {
+		services = append(services, ExternalService{
+			Name:           "object storage",
+			DefaultPort:    443,
+			CredentialKeys: []string{"accessKey", "secretKey"},
+			Spec:           external.ExternalServiceSpec,
+		})
+	}
+	return services
+}
+
+// Port returns the port of the service
+func (s ExternalService) Port() int32 {
+	if s.Spec.Port != 0 {
+		return s.Spec.Port
+	}
+	return s.DefaultPort
+}
+
+// Address returns the host and port of the service
+func (s ExternalService) Address() string {
+	return net.JoinHostPort(s.Spec.Host, strconv.Itoa(int(s.Port())))
+}
+
+// Preflight checks that the credentials Secret of the service contains the required keys, and that the service
+// accepts connections from the operator, completing a TLS handshake when TLS is enabled
+func (s ExternalService) Preflight(ctx context.Context) error {
+	credentials, err := kube.GetSecretValuesAsString("modela-system", s.Spec.CredentialsSecretRef.Name)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to get the credentials of the external %s", s.Name)
+	}
+	for _, key := range s.CredentialKeys {
+		if credentials[key] == "" {
+			return errors.Errorf("The credentials of the external %s are missing the %s key", s.Name, key)
+		}
+	}
+
+	config, err := externalTLSConfig(s.Name, s.Spec.Host, s.Spec.TLS)
+	if err != nil {
+		return err
+	}
+
+	log.FromContext(ctx).Info("Checking connectivity to external service", "service", s.Name, "address", s.Address())
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+
+	// Postgres negotiates TLS inside its own protocol, so only the TCP connection is checked
+	if !s.Spec.TLS.Enabled || s.Name == "postgres" {
+		conn, err := dialer.DialContext(ctx, "tcp", s.Address())
+		if err != nil {
+			return errors.Wrapf(err, "Failed to connect to the external %s at %s", s.Name, s.Address())
+		}
+		return conn.Close()
+	}
+
+	conn, err := tls.DialWithDialer(dialer, "tcp", s.Address(), config)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to complete a TLS handshake with the external %s at %s", s.Name, s.Address())
+	}
+	return conn.Close()
+}
+
+// externalTLSConfig returns the TLS configuration used to connect to an external service, which trusts the CA bundle
+// of the service when one is referenced
+func externalTLSConfig(name string, host string, spec managementv1.ExternalTLSSpec) (*tls.Config, error) {
+	config := &tls.Config{ServerName: host}
+	if ref := spec.CASecretRef; spec.Enabled && ref != nil {
+		secret, err := kube.GetSecret("modela-system", ref.Name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to get the CA bundle of the external %s", name)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(secret.Data[ref.Key]) {
+			return nil, errors.Errorf("The CA bundle of the external %s does not contain a PEM-encoded certificate", name)
+		}
+	}
+	return config, nil
+}
+
+// PreflightExternalServices runs the preflight checks of every external service configured by the Modela resource
+func PreflightExternalServices(ctx context.Context, modela *managementv1.Modela) error {
+	for _, service := range ExternalServices(modela) {
+		if err := service.Preflight(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// externalService returns the external service with the given name, if it is configured by the Modela resource
+func externalService(modela *managementv1.Modela, name string) (ExternalService, bool) {
+	for _, service := range ExternalServices(modela) {
+		if service.Name == name {
+			return service, true
+		}
+	}
+	return ExternalService{}, false
+}
+
+// objectStorageURL returns the URL of an external object storage
+func objectStorageURL(service ExternalService) string {
+	if service.Spec.TLS.Enabled {
+		return "https://" + service.Address()
+	}
+	return "http://" + service.Address()
+}
@@ -0,0 +1,74 @@
+package components
+
+import (
+	"github.com/metaprov/modela-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("External services", func() {
+	modela := &v1alpha1.Modela{Spec: v1alpha1.ModelaSpec{
+		ObjectStore: v1alpha1.ObjectStorageSpec{
+			Install: true,
+			External: &v1alpha1.ExternalObjectStorageSpec{
+				ExternalServiceSpec: v1alpha1.ExternalServiceSpec{
+					Host:                 "s3.us-east-1.amazonaws.com",
+					TLS:                  v1alpha1.ExternalTLSSpec{Enabled: true},
+					CredentialsSecretRef: v1.LocalObjectReference{Name: "s3-admin"},
+				},
+				Provider:     v1alpha1.ObjectStorageProviderAWS,
+				Region:       "us-east-1",
+				BucketPrefix: "acme-",
+			},
+		},
+		Database: v1alpha1.DatabaseSpec{
+			External: v1alpha1.ExternalDatabaseSpec{
+				Postgres: &v1alpha1.ExternalServiceSpec{
+					Host:                 "postgres.example.com",
+					CredentialsSecretRef: v1.LocalObjectReference{Name: "postgres-admin"},
+				},
+			},
+		},
+	}}
+
+	It("Should skip the bundled charts of external services", func() {
+		Expect(NewPostgresDatabase().IsEnabled(*modela)).To(BeFalse())
+		Expect(NewObjectStorage().IsEnabled(*modela)).To(BeFalse())
+	})
+
+	It("Should use the external endpoints for tenant connections", func() {
+		service, ok := externalService(modela, "postgres")
+		Expect(ok).To(BeTrue())
+		Expect(service.Address()).To(Equal("postgres.example.com:5432"))
+		Expect(ConnectionSecrets(modela)).To(ConsistOf("s3-admin", "postgres-admin"))
+
+		tenant := NewTenant("acme")
+		Expect(tenant.bucket(modela)).To(Equal("acme-acme"))
+		Expect(scopedObjectStorage(modela)).To(BeFalse())
+
+		filter := connectionFilter(modela)
+		Expect(filter.ObjectStorageProvider).To(Equal("aws"))
+		Expect(filter.ObjectStorageRegion).To(Equal("us-east-1"))
+		Expect(filter.SSL).To(ConsistOf("minio-connection"))
+	})
+
+	It("Should use the credentials of an external Redis for tenant connections", func() {
+		redis := modela.DeepCopy()
+		redis.Spec.OnlineStore = v1alpha1.OnlineStoreSpec{
+			Install: true,
+			External: &v1alpha1.ExternalRedisSpec{
+				Address:              "redis.example.com:6380",
+				TLS:                  v1alpha1.ExternalTLSSpec{Enabled: true},
+				CredentialsSecretRef: v1.LocalObjectReference{Name: "redis-admin"},
+			},
+		}
+		Expect(ConnectionSecrets(redis)).To(ContainElement("redis-admin"))
+
+		sources := connectionSources(redis)
+		credentials := sources[len(sources)-1].Credentials(*NewTenant("acme"), map[string]string{"username": "modela", "password": "secret"}, nil)
+		Expect(credentials).To(HaveKeyWithValue("host", "redis.example.com"))
+		Expect(credentials).To(HaveKeyWithValue("port", "6380"))
+		Expect(credentials).To(HaveKeyWithValue("username", "modela"))
+	})
+})
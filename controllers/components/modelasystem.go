@@ -7,7 +7,7 @@ import (
 	"github.com/Masterminds/goutils"
 	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
 	"github.com/metaprov/modela-operator/pkg/kube"
-	"github.com/metaprov/modela-operator/pkg/vault"
+	"github.com/metaprov/modela-operator/pkg/secrets"
 	infra "github.com/metaprov/modelaapi/pkg/apis/infra/v1alpha1"
 	"golang.org/x/mod/semver"
 	"io/ioutil"
@@ -219,16 +219,22 @@ func (ms ModelaSystem) Install(ctx context.Context, modela *managementv1.Modela)
 		return err
 	}
 
-	var vaultAddress string
-	if modela.Spec.Vault.VaultAddress == nil || *modela.Spec.Vault.VaultAddress == "" {
-		vaultAddress = "http://modela-vault.modela-system.svc.cluster.local:8200"
-	} else {
-		vaultAddress = *modela.Spec.Vault.VaultAddress
+	store, err := secrets.GetSecretStore(modela)
+	if err != nil {
+		return err
+	}
+
+	config, err := PlatformConfig(modela)
+	if err != nil {
+		return err
+	}
+	if err := ApplyVaultCA(modela); err != nil {
+		return err
 	}
 
 	yaml, _, err := kube.LoadResources(ms.SystemManifestPath, []kio.Filter{
 		kube.SkipCertManagerFilter{},
-		kube.ModelaConfigFilter{VaultAddress: vaultAddress, VaultMountPath: modela.Spec.Vault.MountPath},
+		kube.ModelaConfigFilter{Data: config},
 		kube.ContainerVersionFilter{Version: ms.ModelaVersion},
 		kube.LabelFilter{Labels: map[string]string{"management.modela.ai/operator": modela.Name}},
 		kube.OwnerReferenceFilter{Owner: modela.GetName(), OwnerNamespace: modela.GetNamespace(), UID: string(modela.GetUID())},
@@ -247,16 +253,31 @@ func (ms ModelaSystem) Install(ctx context.Context, modela *managementv1.Modela)
 	if err != nil {
 		return err
 	}
-	return vault.ApplySecret(modela, "jwt-secret", map[string]interface{}{"token": token})
+	return store.ApplySecret("jwt-secret", map[string]interface{}{"token": token})
 }
 
 func (ms ModelaSystem) InstallNewVersion(ctx context.Context, modela *managementv1.Modela) error {
 	logger := log.FromContext(ctx)
 
+	// Preserve the live JWT secret, which is only changed through secret rotation
+	var jwtSecret string
+	if values, err := kube.GetSecretValuesAsString(ms.Namespace, "modela-auth-token"); err == nil {
+		jwtSecret = values["jwt-secret"]
+	}
+
+	config, err := PlatformConfig(modela)
+	if err != nil {
+		return err
+	}
+	if err := ApplyVaultCA(modela); err != nil {
+		return err
+	}
+
 	yaml, _, err := kube.LoadResources(ms.SystemManifestPath, []kio.Filter{
+		kube.ModelaConfigFilter{Data: config},
 		kube.ContainerVersionFilter{Version: ms.ModelaVersion},
 		kube.LabelFilter{Labels: map[string]string{"management.modela.ai/operator": modela.Name}},
-		kube.JwtSecretFilter{},
+		kube.JwtSecretFilter{Secret: jwtSecret},
 		kube.OwnerReferenceFilter{Owner: modela.GetName(), OwnerNamespace: modela.GetNamespace(), UID: string(modela.GetUID())},
 	}, true)
 	if err != nil {
@@ -30,8 +30,9 @@ func (db Mongo) GetInstallPhase() managementv1.ModelaPhase {
 	return managementv1.ModelaPhaseInstallingDatabase
 }
 
+// IsEnabled returns true if MongoDB is used and no external MongoDB server is configured
 func (db Mongo) IsEnabled(modela managementv1.Modela) bool {
-	return modela.Spec.Database.InstallMongoDB
+	return modela.Spec.Database.InstallMongoDB && modela.Spec.Database.External.MongoDB == nil
 }
 
 func (db Mongo) Installed(ctx context.Context) (bool, error) {
@@ -59,6 +60,11 @@ func (db Mongo) Install(ctx context.Context, modela *managementv1.Modela) error
 		values = make(map[string]interface{})
 	}
 	values["useStatefulSet"] = true
+	if modela.Spec.Database.HA.Enabled {
+		if err := db.applyHA(values, modela); err != nil {
+			return err
+		}
+	}
 	return helm.InstallChart(ctx, db.Name, db.Namespace, db.ReleaseName, values)
 }
 
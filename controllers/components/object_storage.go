@@ -36,8 +36,9 @@ func (os ObjectStorage) GetInstallPhase() managementv1.ModelaPhase {
 	return managementv1.ModelaPhaseInstallingObjectStorage
 }
 
+// IsEnabled returns true if MinIO should be installed and no external object storage is configured
 func (os ObjectStorage) IsEnabled(modela managementv1.Modela) bool {
-	return modela.Spec.ObjectStore.Install
+	return modela.Spec.ObjectStore.Install && modela.Spec.ObjectStore.External == nil
 }
 
 // Check if the database installed
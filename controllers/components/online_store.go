@@ -1,13 +1,20 @@
 package components
 
 import (
+	"bufio"
 	"context"
+	"crypto/tls"
+	"fmt"
 	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
 	"github.com/metaprov/modela-operator/pkg/helm"
 	"github.com/metaprov/modela-operator/pkg/kube"
+	"github.com/pkg/errors"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"net"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/kustomize/kyaml/kio"
+	"strings"
+	"time"
 )
 
 // Modela system represent the model core system
@@ -51,6 +58,11 @@ func (os OnlineStore) Installed(ctx context.Context) (bool, error) {
 		return false, err
 	}
 
+	// The Redis chart is not installed when the online store uses an external Redis
+	if secret, err := kube.GetSecret(os.Namespace, "redis-secret"); err == nil && secret.Annotations[kube.ExternalServiceAnnotation] == "true" {
+		return true, nil
+	}
+
 	if installed, err := helm.IsChartInstalled(ctx, os.Name, os.Namespace, os.ReleaseName); !installed {
 		return false, err
 	}
@@ -66,22 +78,24 @@ func (os OnlineStore) Install(ctx context.Context, modela *managementv1.Modela)
 		return err
 	}
 
-	logger.Info("Applying Helm Chart", "version", os.Version)
-	if installed, err := helm.IsChartInstalled(ctx, os.Name, os.Namespace, os.ReleaseName); !installed {
-		if err = helm.InstallChart(ctx, os.Name, os.Namespace, os.ReleaseName, modela.Spec.OnlineStore.Values.Object); err != nil {
-			return err
+	if modela.Spec.OnlineStore.External == nil {
+		logger.Info("Applying Helm Chart", "version", os.Version)
+		if installed, err := helm.IsChartInstalled(ctx, os.Name, os.Namespace, os.ReleaseName); !installed {
+			if err = helm.InstallChart(ctx, os.Name, os.Namespace, os.ReleaseName, modela.Spec.OnlineStore.Values.Object); err != nil {
+				return err
+			}
 		}
 	}
 
-	var password string
-	if values, err := kube.GetSecretValuesAsString("modela-system", "redis"); err == nil {
-		password, _ = values["redis-password"]
+	redisSecret, err := os.redisSecret(modela)
+	if err != nil {
+		return err
 	}
 
 	yaml, _, err := kube.LoadResources(os.ManifestPath, []kio.Filter{
 		kube.LabelFilter{Labels: map[string]string{"management.modela.ai/operator": modela.Name}},
 		kube.NamespaceFilter{Namespace: os.Namespace},
-		kube.RedisSecretFilter{Password: password},
+		redisSecret,
 		kube.ContainerVersionFilter{Version: modela.Spec.Distribution},
 		kube.OwnerReferenceFilter{Owner: modela.GetName(), OwnerNamespace: modela.GetNamespace(), UID: string(modela.GetUID())},
 	}, false)
@@ -100,15 +114,15 @@ func (os OnlineStore) Install(ctx context.Context, modela *managementv1.Modela)
 func (os OnlineStore) InstallNewVersion(ctx context.Context, modela *managementv1.Modela) error {
 	logger := log.FromContext(ctx)
 
-	var password string
-	if values, err := kube.GetSecretValuesAsString("modela-system", "redis"); err == nil {
-		password, _ = values["redis-password"]
+	redisSecret, err := os.redisSecret(modela)
+	if err != nil {
+		return err
 	}
 
 	yaml, _, err := kube.LoadResources(os.ManifestPath, []kio.Filter{
 		kube.LabelFilter{Labels: map[string]string{"management.modela.ai/operator": modela.Name}},
 		kube.NamespaceFilter{Namespace: os.Namespace},
-		kube.RedisSecretFilter{Password: password},
+		redisSecret,
 		kube.ContainerVersionFilter{Version: modela.Spec.Distribution},
 		kube.OwnerReferenceFilter{Owner: modela.GetName(), OwnerNamespace: modela.GetNamespace(), UID: string(modela.GetUID())},
 	}, false)
@@ -124,6 +138,100 @@ func (os OnlineStore) InstallNewVersion(ctx context.Context, modela *managementv
 	return nil
 }
 
+// redisSecret returns the filter which renders the redis-secret against the bundled or external Redis
+func (os OnlineStore) redisSecret(modela *managementv1.Modela) (kube.RedisSecretFilter, error) {
+	external := modela.Spec.OnlineStore.External
+	if external == nil {
+		var password string
+		if values, err := kube.GetSecretValuesAsString("modela-system", "redis"); err == nil {
+			password, _ = values["redis-password"]
+		}
+		return kube.RedisSecretFilter{Password: password}, nil
+	}
+
+	host, port, err := external.HostPort()
+	if err != nil {
+		return kube.RedisSecretFilter{}, err
+	}
+	credentials, err := kube.GetSecretValuesAsString("modela-system", external.CredentialsSecretRef.Name)
+	if err != nil {
+		return kube.RedisSecretFilter{}, errors.Wrap(err, "Failed to get the credentials of the external Redis")
+	}
+	return kube.RedisSecretFilter{
+		Address:  net.JoinHostPort(host, port),
+		Username: credentials["username"],
+		Password: credentials["password"],
+		TLS:      external.TLS.Enabled,
+	}, nil
+}
+
+// CheckExternalRedis authenticates with the external Redis of the online store and sends it a PING
+func (os OnlineStore) CheckExternalRedis(ctx context.Context, modela *managementv1.Modela) error {
+	external := modela.Spec.OnlineStore.External
+	host, port, err := external.HostPort()
+	if err != nil {
+		return err
+	}
+	credentials, err := kube.GetSecretValuesAsString("modela-system", external.CredentialsSecretRef.Name)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get the credentials of the external Redis")
+	}
+
+	address := net.JoinHostPort(host, port)
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	if external.TLS.Enabled {
+		config, err := externalTLSConfig("Redis", host, external.TLS)
+		if err != nil {
+			return err
+		}
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, config)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to connect to the external Redis at %s", address)
+		}
+	} else if conn, err = dialer.DialContext(ctx, "tcp", address); err != nil {
+		return errors.Wrapf(err, "Failed to connect to the external Redis at %s", address)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	reader := bufio.NewReader(conn)
+	command := func(args ...string) (string, error) {
+		request := fmt.Sprintf("*%d\r\n", len(args))
+		for _, arg := range args {
+			request += fmt.Sprintf("$%d\r\n%s\r\n", len(arg), arg)
+		}
+		if _, err := conn.Write([]byte(request)); err != nil {
+			return "", err
+		}
+		reply, err := reader.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		if reply = strings.TrimSpace(reply); strings.HasPrefix(reply, "-") {
+			return "", errors.New(reply[1:])
+		}
+		return reply, nil
+	}
+
+	if password := credentials["password"]; password != "" {
+		args := []string{"AUTH", password}
+		if username := credentials["username"]; username != "" {
+			args = []string{"AUTH", username, password}
+		}
+		if _, err := command(args...); err != nil {
+			return errors.Wrap(err, "Failed to authenticate with the external Redis")
+		}
+	}
+
+	if reply, err := command("PING"); err != nil {
+		return errors.Wrap(err, "Failed to ping the external Redis")
+	} else if reply != "+PONG" {
+		return errors.Errorf("Unexpected reply %s from the external Redis", reply)
+	}
+	return nil
+}
+
 func (os OnlineStore) Installing(ctx context.Context) (bool, error) {
 	installed, err := os.Installed(ctx)
 	if !installed {
@@ -26,4 +26,21 @@ var _ = Describe("Online store installer", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(installed).To(BeTrue())
 	})
+
+	It("Should default the port of the external Redis", func() {
+		for address, expected := range map[string][]string{
+			"redis.example.com":      {"redis.example.com", "6379"},
+			"redis.example.com:6380": {"redis.example.com", "6380"},
+			"[2001:db8::1]":          {"2001:db8::1", "6379"},
+		} {
+			host, port, err := v1alpha1.ExternalRedisSpec{Address: address}.HostPort()
+			Expect(err).NotTo(HaveOccurred())
+			Expect([]string{host, port}).To(Equal(expected))
+		}
+
+		for _, address := range []string{":6379", "redis.example.com:redis", "redis.example.com:70000", "2001:db8::1"} {
+			_, _, err := v1alpha1.ExternalRedisSpec{Address: address}.HostPort()
+			Expect(err).To(HaveOccurred(), address)
+		}
+	})
 })
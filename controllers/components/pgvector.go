@@ -0,0 +1,173 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"regexp"
+	"sort"
+	"strings"
+
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+)
+
+// DefaultPgvectorImage is the pgvector image installed by the bundled Postgres chart when the spec does not set one
+const DefaultPgvectorImage = "docker.io/ankane/pgvector:v0.5.1"
+
+// The reasons of the PgvectorReady condition when the extension cannot be bootstrapped
+const (
+	PgvectorExtensionUnavailable = "ExtensionUnavailable"
+	PgvectorVersionMismatch      = "VersionMismatch"
+)
+
+// postgresPgvectorScript creates the pgvector extension in each of the existing databases listed in DATABASES, or
+// updates the extension to the version available in the server. The version available in the server is printed
+// first, followed by the databases which do not exist and the databases whose extension has another version.
+const postgresPgvectorScript = `set -e
+available=$(psql -tAq -v ON_ERROR_STOP=1 -d postgres -c "SELECT default_version FROM pg_available_extensions WHERE name = 'vector'")
+echo "available $available"
+[ -n "$available" ] || exit 0
+for database in $(echo "$DATABASES" | tr , ' '); do
+  exists=$(psql -tAq -v ON_ERROR_STOP=1 -d postgres -c "SELECT 1 FROM pg_database WHERE datname = '$database'")
+  if [ -z "$exists" ]; then echo "missing $database"; continue; fi
+  psql -q -v ON_ERROR_STOP=1 -d "$database" -c "CREATE EXTENSION IF NOT EXISTS vector" -c "ALTER EXTENSION vector UPDATE" >/dev/null
+  version=$(psql -tAq -v ON_ERROR_STOP=1 -d "$database" -c "SELECT extversion FROM pg_extension WHERE extname = 'vector'")
+  if [ "$version" != "$available" ]; then echo "installed $database $version"; fi
+done`
+
+// pgvectorVersionPattern matches the version of pgvector at the start of the tag of a pgvector image, such as
+// v0.5.1 or 0.5.1-pg15
+var pgvectorVersionPattern = regexp.MustCompile(`^v?(\d+\.\d+\.\d+)`)
+
+// PgvectorError is returned when the pgvector extension is not available in the Postgres server, or when the version
+// of the extension is not the expected version. The reason is the reason of the PgvectorReady condition.
+type PgvectorError struct {
+	Reason  string
+	Message string
+}
+
+func (e PgvectorError) Error() string {
+	return e.Message
+}
+
+// PgvectorImage returns the pgvector image installed by the bundled Postgres chart
+func PgvectorImage(modela *managementv1.Modela) string {
+	if modela.Spec.Database.PgvectorImage != "" {
+		return modela.Spec.Database.PgvectorImage
+	}
+	return DefaultPgvectorImage
+}
+
+// pgvectorImageValues returns the image values of the Postgres chart for an image reference, which consists of an
+// optional registry, the repository and a tag or digest
+func pgvectorImageValues(image string) map[string]interface{} {
+	name, tag, digest := image, "", ""
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, digest = name[:i], name[i+1:]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+	registry, repository := "docker.io", name
+	if i := strings.Index(name, "/"); i >= 0 && (strings.ContainsAny(name[:i], ".:") || name[:i] == "localhost") {
+		registry, repository = name[:i], name[i+1:]
+	}
+
+	values := map[string]interface{}{"registry": registry, "repository": repository}
+	if digest != "" {
+		values["digest"] = digest
+	}
+	if tag != "" {
+		values["tag"] = tag
+	} else if digest == "" {
+		values["tag"] = "latest"
+	}
+	return values
+}
+
+// PgvectorVersion returns the version of pgvector provided by a pgvector image, which is read from its tag. An empty
+// string is returned when the image is referenced by digest, or its tag does not start with a version.
+func PgvectorVersion(image string) string {
+	tag, _ := pgvectorImageValues(image)["tag"].(string)
+	if match := pgvectorVersionPattern.FindStringSubmatch(tag); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// PostgresDatabases returns the sorted names of the Postgres databases of tenants
+func PostgresDatabases(tenants []string) []string {
+	var databases []string
+	for _, tenant := range tenants {
+		databases = append(databases, NewTenant(tenant).postgresIdentifier())
+	}
+	sort.Strings(databases)
+	return databases
+}
+
+// BootstrapPgvector creates the pgvector extension in the databases of the tenants, or updates the extension to the
+// version available in the Postgres server. The version of the extension is verified against the version of the
+// pgvector image when the bundled Postgres is used; the version is only reported for an external server.
+func BootstrapPgvector(ctx context.Context, modela *managementv1.Modela, databases []string) (*managementv1.PgvectorStatus, error) {
+	secret := NewPostgresDatabase().ReleaseName
+	if service, ok := externalService(modela, "postgres"); ok {
+		secret = service.Spec.CredentialsSecretRef.Name
+	}
+	admin, err := kube.GetSecretValuesAsString(NewPostgresDatabase().Namespace, secret)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to get the credentials of Postgres")
+	}
+
+	client := postgresClient(modela, "modela-postgres-pgvector", postgresPgvectorScript, admin)
+	client.Env["DATABASES"] = strings.Join(databases, ",")
+	output, err := client.output(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to bootstrap the pgvector extension")
+	}
+
+	status := &managementv1.PgvectorStatus{}
+	if _, external := externalService(modela, "postgres"); !external {
+		status.Image = PgvectorImage(modela)
+	}
+	return status, verifyPgvector(status, databases, output)
+}
+
+// verifyPgvector reads the output of the bootstrap script into the status, and verifies the version of the extension
+func verifyPgvector(status *managementv1.PgvectorStatus, databases []string, output string) error {
+	missing := make(map[string]bool)
+	var mismatches []string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) > 0 && fields[0] == "available":
+			status.Version = strings.Join(fields[1:], "")
+		case len(fields) == 2 && fields[0] == "missing":
+			missing[fields[1]] = true
+		case len(fields) >= 2 && fields[0] == "installed":
+			mismatches = append(mismatches, fmt.Sprintf("%s (%s)", fields[1], strings.Join(fields[2:], "")))
+		}
+	}
+
+	for _, database := range databases {
+		if !missing[database] {
+			status.Databases = append(status.Databases, database)
+		}
+	}
+	now := metav1.Now()
+	status.LastSyncTime = &now
+
+	if status.Version == "" {
+		return PgvectorError{Reason: PgvectorExtensionUnavailable, Message: "The pgvector extension is not available in the Postgres server"}
+	}
+	if expected := PgvectorVersion(status.Image); expected != "" && expected != status.Version {
+		return PgvectorError{Reason: PgvectorVersionMismatch, Message: fmt.Sprintf(
+			"The Postgres server provides pgvector %s, but the image %s provides pgvector %s", status.Version, status.Image, expected)}
+	}
+	if len(mismatches) > 0 {
+		return PgvectorError{Reason: PgvectorVersionMismatch, Message: fmt.Sprintf(
+			"The pgvector extension could not be updated to %s in the databases %s", status.Version, strings.Join(mismatches, ", "))}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package components
+
+import (
+	"github.com/metaprov/modela-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pgvector", func() {
+	It("Should render the image values of a pgvector image", func() {
+		Expect(pgvectorImageValues(DefaultPgvectorImage)).To(Equal(map[string]interface{}{
+			"registry": "docker.io", "repository": "ankane/pgvector", "tag": "v0.5.1",
+		}))
+		Expect(pgvectorImageValues("ankane/pgvector")).To(Equal(map[string]interface{}{
+			"registry": "docker.io", "repository": "ankane/pgvector", "tag": "latest",
+		}))
+		Expect(pgvectorImageValues("registry.local:5000/db/pgvector:0.6.0-pg16")).To(Equal(map[string]interface{}{
+			"registry": "registry.local:5000", "repository": "db/pgvector", "tag": "0.6.0-pg16",
+		}))
+		Expect(pgvectorImageValues("ghcr.io/pgvector/pgvector@sha256:abc")).To(Equal(map[string]interface{}{
+			"registry": "ghcr.io", "repository": "pgvector/pgvector", "digest": "sha256:abc",
+		}))
+	})
+
+	It("Should read the version of pgvector from the tag of its image", func() {
+		Expect(PgvectorVersion(DefaultPgvectorImage)).To(Equal("0.5.1"))
+		Expect(PgvectorVersion("pgvector/pgvector:0.6.0-pg16")).To(Equal("0.6.0"))
+		Expect(PgvectorVersion("pgvector/pgvector:pg16")).To(BeEmpty())
+		Expect(PgvectorVersion("pgvector/pgvector@sha256:abc")).To(BeEmpty())
+	})
+
+	It("Should verify the version of the extension", func() {
+		databases := PostgresDatabases([]string{"tenant-b", "tenant-a"})
+		Expect(databases).To(Equal([]string{"tenant_tenant_a", "tenant_tenant_b"}))
+
+		status := &v1alpha1.PgvectorStatus{Image: DefaultPgvectorImage}
+		Expect(verifyPgvector(status, databases, "available 0.5.1\nmissing tenant_tenant_b\n")).To(Succeed())
+		Expect(status.Version).To(Equal("0.5.1"))
+		Expect(status.Databases).To(Equal([]string{"tenant_tenant_a"}))
+
+		err := verifyPgvector(&v1alpha1.PgvectorStatus{Image: DefaultPgvectorImage}, databases, "available \n")
+		Expect(err).To(Equal(PgvectorError{Reason: PgvectorExtensionUnavailable, Message: "The pgvector extension is not available in the Postgres server"}))
+
+		err = verifyPgvector(&v1alpha1.PgvectorStatus{Image: "pgvector/pgvector:0.6.0-pg16"}, databases, "available 0.5.1\n")
+		Expect(err).To(HaveOccurred())
+		Expect(err.(PgvectorError).Reason).To(Equal(PgvectorVersionMismatch))
+
+		// The version of an external server is not verified against an image
+		err = verifyPgvector(&v1alpha1.PgvectorStatus{}, databases, "available 0.7.0\ninstalled tenant_tenant_a 0.5.0\n")
+		Expect(err).To(HaveOccurred())
+		Expect(err.(PgvectorError).Reason).To(Equal(PgvectorVersionMismatch))
+		Expect(verifyPgvector(&v1alpha1.PgvectorStatus{}, databases, "available 0.7.0\n")).To(Succeed())
+	})
+})
@@ -0,0 +1,154 @@
+package components
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	"github.com/metaprov/modela-operator/pkg/secrets"
+	"github.com/metaprov/modela-operator/pkg/vault"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"net/url"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strings"
+
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+)
+
+// PlatformConfigName is the name of the ConfigMap which holds the platform configuration
+const PlatformConfigName = "modela-config"
+
+// The platform deployments mount the CA bundle of Vault from the VaultCASecretName Secret at VaultCAPath
+const (
+	VaultCASecretName = "modela-vault-ca"
+	VaultCAPath       = "/etc/modela/vault-ca/ca.crt"
+)
+
+// platformDeployments are the deployments which read the platform configuration on startup
+var platformDeployments = []string{"modela-control-plane", "modela-data-plane", "modela-api-gateway"}
+
+// PlatformConfig validates the platform configuration of the Modela resource and returns the data of the
+// modela-config ConfigMap
+func PlatformConfig(modela *managementv1.Modela) (map[string]string, error) {
+	config := modela.Spec.PlatformConfig
+
+	store, err := secrets.GetSecretStore(modela)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]string{
+		"release":         modela.Spec.Distribution,
+		"secretStore":     string(store.Type()),
+		"imagePullPolicy": string(v1.PullIfNotPresent),
+		"cachePath":       "/var/opt/modela/data",
+		"vaultAddress":    vault.Address(modela),
+		"vaultMountPath":  modela.Spec.Vault.MountPath,
+		// The workloads of a tenant namespace log in to Vault through the role of their tenant
+		"vaultRole":             "modela",
+		"vaultTenantRolePrefix": vault.TenantRolePrefix,
+	}
+
+	switch config.ImagePullPolicy {
+	case "":
+	case v1.PullAlways, v1.PullIfNotPresent, v1.PullNever:
+		data["imagePullPolicy"] = string(config.ImagePullPolicy)
+	default:
+		return nil, errors.Errorf("Invalid image pull policy %s", config.ImagePullPolicy)
+	}
+
+	if config.CachePath != "" {
+		if !strings.HasPrefix(config.CachePath, "/") {
+			return nil, errors.Errorf("The cache path %s must be absolute", config.CachePath)
+		}
+		data["cachePath"] = config.CachePath
+	}
+
+	if config.VaultAddress != "" {
+		address, err := url.Parse(config.VaultAddress)
+		if err != nil || (address.Scheme != "http" && address.Scheme != "https") || address.Host == "" {
+			return nil, errors.Errorf("The Vault address %s must be an http or https URL", config.VaultAddress)
+		}
+		data["vaultAddress"] = config.VaultAddress
+	}
+
+	if config.VaultMountPath != "" {
+		data["vaultMountPath"] = strings.Trim(config.VaultMountPath, "/")
+	}
+
+	if vault.CASecretRef(modela) != nil {
+		data["vaultCACert"] = VaultCAPath
+	}
+
+	for key, value := range config.Extra {
+		if _, ok := data[key]; ok {
+			return nil, errors.Errorf("The key %s is managed by the platform configuration and may not be overridden", key)
+		}
+		if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+			return nil, errors.Errorf("Invalid platform configuration key %s: %s", key, strings.Join(errs, "; "))
+		}
+		data[key] = value
+	}
+
+	return data, nil
+}
+
+// ApplyPlatformConfig reconciles the platform configuration into the modela-config ConfigMap, and triggers a rolling
+// restart of the control plane, data plane and API gateway when it changes. It returns the checksum of the applied
+// configuration.
+func (ms ModelaSystem) ApplyPlatformConfig(ctx context.Context, modela *managementv1.Modela) (string, error) {
+	data, err := PlatformConfig(modela)
+	if err != nil {
+		return "", err
+	}
+	if err := ApplyVaultCA(modela); err != nil {
+		return "", err
+	}
+
+	// The map is marshaled with sorted keys, which makes the checksum stable
+	encoded, _ := json.Marshal(data)
+	checksum := sha256.Sum256(encoded)
+	version := hex.EncodeToString(checksum[:8])
+
+	changed, err := kube.UpdateConfigMapData(ms.Namespace, PlatformConfigName, data)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to apply the platform configuration")
+	}
+
+	// Restart the platform when the ConfigMap changed, or when a previous restart did not complete
+	applied := modela.Status.PlatformConfigVersion
+	if !changed && (applied == "" || applied == version) {
+		return version, nil
+	}
+
+	log.FromContext(ctx).Info("Restarting the platform to apply the platform configuration", "version", version)
+	for _, deployment := range platformDeployments {
+		if err := kube.RestartDeployment(ms.Namespace, deployment); err != nil && !k8serr.IsNotFound(errors.Cause(err)) {
+			return "", err
+		}
+	}
+	return version, nil
+}
+
+// ApplyVaultCA copies the CA bundle of Vault into the Secret mounted by the platform deployments, or deletes the
+// Secret when the certificate of Vault is verified against the system roots
+func ApplyVaultCA(modela *managementv1.Modela) error {
+	ref := vault.CASecretRef(modela)
+	if ref == nil {
+		return kube.DeleteSecret("modela-system", VaultCASecretName)
+	}
+
+	values, err := kube.GetSecretValuesAsString("modela-system", ref.Name)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to get Vault CA secret %s", ref.Name)
+	}
+	ca, ok := values[ref.Key]
+	if !ok {
+		return errors.Errorf("Vault CA secret %s has no key %s", ref.Name, ref.Key)
+	}
+	return kube.CreateOrUpdateSecret("modela-system", VaultCASecretName, map[string]string{"ca.crt": ca})
+}
@@ -0,0 +1,54 @@
+package components
+
+import (
+	"github.com/metaprov/modela-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Platform configuration", func() {
+	It("Should render the defaults and overrides of the platform configuration", func() {
+		modela := &v1alpha1.Modela{Spec: v1alpha1.ModelaSpec{
+			Distribution: "develop",
+			Vault:        v1alpha1.VaultSpec{MountPath: "modela/secrets"},
+			PlatformConfig: v1alpha1.PlatformConfigSpec{
+				VaultAddress:   "https://vault.example.com:8200",
+				VaultMountPath: "/platform/secrets/",
+				Extra:          map[string]string{"logLevel": "debug"},
+			},
+		}}
+
+		data, err := PlatformConfig(modela)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(HaveKeyWithValue("release", "develop"))
+		Expect(data).To(HaveKeyWithValue("imagePullPolicy", "IfNotPresent"))
+		Expect(data).To(HaveKeyWithValue("vaultAddress", "https://vault.example.com:8200"))
+		Expect(data).To(HaveKeyWithValue("vaultMountPath", "platform/secrets"))
+		Expect(data).To(HaveKeyWithValue("vaultTenantRolePrefix", "modela-tenant-"))
+		Expect(data).To(HaveKeyWithValue("logLevel", "debug"))
+	})
+
+	It("Should publish the CA bundle of a Vault served over TLS", func() {
+		modela := &v1alpha1.Modela{Spec: v1alpha1.ModelaSpec{Vault: v1alpha1.VaultSpec{Install: true}}}
+		data, err := PlatformConfig(modela)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).NotTo(HaveKey("vaultCACert"))
+
+		modela.Spec.Vault.TLS.Enabled = true
+		data, err = PlatformConfig(modela)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(HaveKeyWithValue("vaultCACert", VaultCAPath))
+	})
+
+	It("Should reject an invalid platform configuration", func() {
+		for _, config := range []v1alpha1.PlatformConfigSpec{
+			{CachePath: "relative/path"},
+			{VaultAddress: "vault:8200"},
+			{Extra: map[string]string{"vaultAddress": "http://vault:8200"}},
+			{Extra: map[string]string{"invalid key": "value"}},
+		} {
+			_, err := PlatformConfig(&v1alpha1.Modela{Spec: v1alpha1.ModelaSpec{PlatformConfig: config}})
+			Expect(err).To(HaveOccurred())
+		}
+	})
+})
@@ -6,6 +6,7 @@ import (
 	"github.com/metaprov/modela-operator/pkg/helm"
 	"github.com/metaprov/modela-operator/pkg/kube"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -36,13 +37,17 @@ func (db Postgres) GetInstallPhase() managementv1.ModelaPhase {
 	return managementv1.ModelaPhaseInstallingDatabase
 }
 
+// IsEnabled returns true unless an external Postgres server is used
 func (db Postgres) IsEnabled(modela managementv1.Modela) bool {
-	return true
+	return modela.Spec.Database.External.Postgres == nil
 }
 
 func (db Postgres) Installed(ctx context.Context) (bool, error) {
-	if belonging, err := kube.IsStatefulSetCreatedByModela(db.Namespace, "modela-postgresql"); err == nil && !belonging {
-		return true, managementv1.ComponentNotInstalledByModelaError
+	// The stateful set of the primary server is renamed when Postgres replicates to read replicas
+	for _, name := range []string{db.ReleaseName, db.ReleaseName + "-primary"} {
+		if belonging, err := kube.IsStatefulSetCreatedByModela(db.Namespace, name); err == nil && !belonging {
+			return true, managementv1.ComponentNotInstalledByModelaError
+		}
 	}
 
 	if installed, err := helm.IsChartInstalled(ctx, db.Name, db.Namespace, db.ReleaseName); !installed {
@@ -65,16 +70,37 @@ func (db Postgres) Install(ctx context.Context, modela *managementv1.Modela) err
 		values = make(map[string]interface{})
 	}
 	if modela.Spec.Database.InstallPgvector {
-		imageValues := make(map[string]interface{})
-		imageValues["registry"] = "docker.io"
-		imageValues["repository"] = "ankane/pgvector"
-		imageValues["tag"] = "v0.5.1"
-		values["image"] = imageValues
+		values["image"] = pgvectorImageValues(PgvectorImage(modela))
+	}
+	if modela.Spec.Database.HA.Enabled {
+		if err := db.applyHA(values, modela); err != nil {
+			return err
+		}
 	}
 
 	return helm.InstallChart(ctx, db.Name, db.Namespace, db.ReleaseName, values)
 }
 
+// UpgradePgvectorImage upgrades the release to the pgvector image of the spec when the release was installed with
+// another image. It returns true if the release was upgraded.
+func (db Postgres) UpgradePgvectorImage(ctx context.Context, modela *managementv1.Modela) (bool, error) {
+	release, err := helm.NewHelmChart(db.Name, db.Namespace, db.ReleaseName, false).Get(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	values := pgvectorImageValues(PgvectorImage(modela))
+	if current, ok := release.Config["image"].(map[string]interface{}); ok && reflect.DeepEqual(current, values) {
+		return false, nil
+	}
+
+	log.FromContext(ctx).Info("Upgrading the pgvector image of Postgres", "image", PgvectorImage(modela))
+	if err := helm.UpgradeChart(ctx, db.Name, db.Namespace, db.ReleaseName, map[string]interface{}{"image": values}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (db Postgres) Installing(ctx context.Context) (bool, error) {
 	installed, err := db.Installed(ctx)
 	if !installed {
@@ -0,0 +1,641 @@
+package components
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	"github.com/metaprov/modela-operator/pkg/vault"
+	"github.com/minio/minio-go/v7"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/semver"
+	"io"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"path"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+)
+
+// The names of the containers of the restore jobs which do not restore a backed up component
+const (
+	restoreContainerDownload = "download"
+	restoreContainerBuckets  = "buckets"
+)
+
+const (
+	// restoreTargetScript configures the alias of the backup target, and of the object storage of the installation
+	// when it is set
+	restoreTargetScript = `set -eo pipefail
+export MC_CONFIG_DIR=/tmp/.mc
+mkdir -p $MC_CONFIG_DIR/certs/CAs
+if [ -f "$TARGET_CA" ]; then cp "$TARGET_CA" $MC_CONFIG_DIR/certs/CAs/target.crt; fi
+if [ -f "$MINIO_CA" ]; then cp "$MINIO_CA" $MC_CONFIG_DIR/certs/CAs/source.crt; fi
+mc alias set target "$TARGET_URL" "$TARGET_ACCESS_KEY" "$TARGET_SECRET_KEY" >/dev/null
+if [ -n "$MINIO_URL" ]; then mc alias set source "$MINIO_URL" "$MINIO_ACCESS_KEY" "$MINIO_SECRET_KEY" >/dev/null; fi
+`
+
+	// restoreDownloadScript downloads the dumps of the restored components and verifies their checksums
+	restoreDownloadScript = restoreTargetScript + `
+mc cp "target/$TARGET_BUCKET/$FOLDER/SHA256SUMS" /backup/SHA256SUMS
+cd /backup
+for component in $(echo "$COMPONENTS" | tr , ' '); do
+  mc cp --recursive "target/$TARGET_BUCKET/$FOLDER/$component/" "/backup/$component/"
+  grep " \./$component/" SHA256SUMS | sha256sum -c -
+done
+`
+
+	restoreVaultScript = `set -e
+` + vaultLoginScript + `
+vault operator raft snapshot restore -force /backup/vault/vault.snap
+`
+
+	// restoreVaultSecretsScript writes the secrets exported by the backup back into the mount, as a new version of
+	// each secret
+	restoreVaultSecretsScript = `set -e
+` + vaultLoginScript + `
+while read -r key; do
+  if [ -n "$key" ]; then
+    vault kv put "$VAULT_MOUNT_PATH/$key" "@/backup/vault-secrets/kv/$key.json" >/dev/null
+  fi
+done < /backup/vault-secrets/secrets.txt
+`
+
+	// restorePostgresScript replays the dump of every database. The dump drops the databases before they are
+	// recreated, so the remaining connections are terminated first. Statements which fail, such as dropping the
+	// role of the connected user, do not stop the restore.
+	restorePostgresScript = `set -eo pipefail
+psql -q -c "SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE pid <> pg_backend_pid() AND datname IS NOT NULL" >/dev/null
+gunzip -c /backup/postgres/dumpall.sql.gz | psql -q -v ON_ERROR_STOP=0 -d postgres
+`
+
+	// restoreMongoScript replaces the collections of every database. The users of the admin database are kept, so
+	// that the credentials of the installation remain valid.
+	restoreMongoScript = `set -e
+mongorestore --host "$MONGO_HOST" --port "$MONGO_PORT" $MONGO_TLS_OPTIONS -u "$MONGO_USER" -p "$MONGO_PASSWORD" \
+  --authenticationDatabase admin --drop --gzip --archive=/backup/mongodb/mongodb.archive.gz \
+  --nsExclude 'admin.*' --nsExclude 'config.*'
+`
+
+	// restoreBucketsScript mirrors the buckets contained in the backup back into the object storage
+	restoreBucketsScript = restoreTargetScript + `
+if [ -z "$MINIO_URL" ]; then exit 0; fi
+for bucket in $(mc ls "target/$TARGET_BUCKET/$FOLDER/buckets/" | awk '{print $NF}' | tr -d /); do
+  mc mb --ignore-existing "source/$bucket"
+  mc mirror --overwrite "target/$TARGET_BUCKET/$FOLDER/buckets/$bucket" "source/$bucket"
+done
+`
+)
+
+// restoreSuspendedDeployments are the deployments of the installation which are scaled down while a backup is
+// restored, as they write to the databases
+var restoreSuspendedDeployments = []string{"modela-control-plane", "modela-data-plane", "modela-api-gateway"}
+
+// RestoreSource is the folder of the object storage which contains a restored backup
+type RestoreSource struct {
+	Target managementv1.BackupTargetSpec
+	Folder string
+}
+
+// backupManifest is the manifest uploaded last by a backup job
+type backupManifest struct {
+	Modela       string `json:"modela"`
+	Distribution string `json:"distribution"`
+	Components   string `json:"components"`
+	CreatedAt    string `json:"createdAt"`
+}
+
+// JobFailedError is returned when the job of a step of a restore has failed. The step is not retried.
+type JobFailedError struct {
+	Job     string
+	Message string
+}
+
+func (e *JobFailedError) Error() string {
+	return e.Message
+}
+
+// Restore restores a Modela installation from a backup taken by Backup. Vault and the databases are restored by
+// jobs which download the dumps from the backup target, while the Modela API resources of the tenants are
+// re-applied by the operator.
+type Restore struct {
+	Backup
+}
+
+func NewRestore() *Restore {
+	return &Restore{Backup: *NewBackup()}
+}
+
+// Validate verifies that the backup is complete and that the checksum of each file of the backup matches the
+// checksum recorded when it was taken, and returns the description of the backup
+func (r Restore) Validate(ctx context.Context, modela *managementv1.Modela, source RestoreSource) (*managementv1.RestoredBackupStatus, error) {
+	target, err := r.target(modela, source.Target)
+	if err != nil {
+		return nil, err
+	}
+	client, err := target.client()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := r.readObject(ctx, client, target.Bucket, path.Join(source.Folder, "manifest.json"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "The backup %s is incomplete, as its manifest cannot be read", source.Folder)
+	}
+	var manifest backupManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse the manifest of backup %s", source.Folder)
+	}
+
+	sums, err := r.readObject(ctx, client, target.Bucket, path.Join(source.Folder, "SHA256SUMS"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to read the checksums of backup %s", source.Folder)
+	}
+	checksums, err := parseChecksums(sums)
+	if err != nil {
+		return nil, err
+	}
+
+	backup := &managementv1.RestoredBackupStatus{Folder: source.Folder, Modela: manifest.Modela, Distribution: manifest.Distribution}
+	for _, component := range strings.Split(manifest.Components, ",") {
+		if component == "" || component == BackupComponentUpload {
+			continue
+		}
+		backup.Components = append(backup.Components, component)
+		if !containsPrefix(checksums, component+"/") {
+			return nil, errors.Errorf("The backup %s does not contain the dump of %s", source.Folder, component)
+		}
+	}
+	if createdAt, err := time.Parse(time.RFC3339, manifest.CreatedAt); err == nil {
+		backup.CreatedAt = &metav1.Time{Time: createdAt}
+	}
+
+	for _, file := range sortedKeys(checksums) {
+		object, err := client.GetObject(ctx, target.Bucket, path.Join(source.Folder, file), minio.GetObjectOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to read %s from backup %s", file, source.Folder)
+		}
+		hash := sha256.New()
+		_, err = io.Copy(hash, object)
+		_ = object.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to read %s from backup %s", file, source.Folder)
+		}
+		if hex.EncodeToString(hash.Sum(nil)) != checksums[file] {
+			return nil, errors.Errorf("The checksum of %s does not match the checksum recorded by backup %s", file, source.Folder)
+		}
+	}
+
+	log.FromContext(ctx).Info("Validated backup", "folder", source.Folder, "distribution", backup.Distribution,
+		"components", backup.Components)
+	return backup, nil
+}
+
+// CompatibleDistribution returns an error if a backup of a distribution of Modela cannot be restored into an
+// installation of another distribution. A backup can be restored into the same minor version of a distribution
+// which is not older than the backup. Distributions which are not semantic versions, such as stable, must match.
+func CompatibleDistribution(backup string, running string) error {
+	if backup == running {
+		return nil
+	}
+	if !semver.IsValid(backup) || !semver.IsValid(running) {
+		return errors.Errorf("The backup of distribution %s cannot be restored into distribution %s", backup, running)
+	}
+	if semver.MajorMinor(backup) != semver.MajorMinor(running) {
+		return errors.Errorf("The backup of distribution %s cannot be restored into distribution %s, as the minor "+
+			"versions differ", backup, running)
+	}
+	if semver.Compare(backup, running) > 0 {
+		return errors.Errorf("The backup of distribution %s is newer than distribution %s", backup, running)
+	}
+	return nil
+}
+
+// Pause scales down the deployments of the installation which write to the databases
+func (r Restore) Pause(ctx context.Context) error {
+	for _, deployment := range restoreSuspendedDeployments {
+		if err := kube.SuspendDeployment(r.Namespace, deployment); err != nil {
+			return err
+		}
+	}
+	log.FromContext(ctx).Info("Paused Modela for restore", "deployments", restoreSuspendedDeployments)
+	return nil
+}
+
+// Resume restores the deployments scaled down by Pause to their previous replicas
+func (r Restore) Resume(ctx context.Context) error {
+	for _, deployment := range restoreSuspendedDeployments {
+		if err := kube.ResumeDeployment(r.Namespace, deployment); err != nil {
+			return err
+		}
+	}
+	log.FromContext(ctx).Info("Resumed Modela after restore", "deployments", restoreSuspendedDeployments)
+	return nil
+}
+
+// RestoreVault restores the snapshot of Vault contained in the backup through a job. It returns true once the job
+// has completed successfully.
+func (r Restore) RestoreVault(ctx context.Context, modela *managementv1.Modela, restore *managementv1.ModelaRestore,
+	source RestoreSource) (bool, error) {
+	snapshots := NewVaultSnapshot()
+	container := snapshots.vaultContainer(modela, BackupComponentVault, restoreVaultScript, snapshots.RestoreRole)
+	container.VolumeMounts = container.VolumeMounts[1:]
+	restored, err := r.runJob(ctx, modela, restore, source, BackupComponentVault, []string{BackupComponentVault}, container)
+	if restored {
+		// The token of the operator may not exist in the restored storage
+		if client, err := vault.GetAuthenticatedClient(modela); err == nil {
+			vault.InvalidateAuthenticatedClient(client)
+		}
+	}
+	return restored, err
+}
+
+// RestoreVaultSecrets writes the secrets of Vault exported by the backup back into the mount through a job. It
+// returns true once the job has completed successfully.
+func (r Restore) RestoreVaultSecrets(ctx context.Context, modela *managementv1.Modela, restore *managementv1.ModelaRestore,
+	source RestoreSource) (bool, error) {
+	snapshots := NewVaultSnapshot()
+	container := snapshots.vaultContainer(modela, BackupComponentVaultSecrets, restoreVaultSecretsScript, snapshots.RestoreRole)
+	container.VolumeMounts = container.VolumeMounts[1:]
+	return r.runJob(ctx, modela, restore, source, BackupComponentVault, []string{BackupComponentVaultSecrets}, container)
+}
+
+// StoreUnsealKeys hands the unseal keys of the restored Vault, held by a Secret, to the key custody backend of
+// the installation
+func (r Restore) StoreUnsealKeys(modela *managementv1.Modela, secret string) error {
+	values, err := kube.GetSecretValuesAsString(r.Namespace, secret)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to get the unseal keys of the restored Vault from secret %s", secret)
+	}
+
+	var indexes []int
+	for key := range values {
+		if index, err := strconv.Atoi(strings.TrimPrefix(key, "key-")); err == nil && strings.HasPrefix(key, "key-") {
+			indexes = append(indexes, index)
+		}
+	}
+	if len(indexes) == 0 {
+		return errors.Errorf("The secret %s does not contain unseal keys", secret)
+	}
+	sort.Ints(indexes)
+
+	keys := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		keys = append(keys, values[fmt.Sprintf("key-%d", index)])
+	}
+
+	custodian, err := vault.GetKeyCustodian(modela)
+	if err != nil {
+		return err
+	}
+	return custodian.StoreKeys(keys)
+}
+
+// RestoreDatabases restores the dumps of the databases contained in the backup, and mirrors the buckets of the
+// backup into the object storage, through a job. It returns true once the job has completed successfully.
+func (r Restore) RestoreDatabases(ctx context.Context, modela *managementv1.Modela, restore *managementv1.ModelaRestore,
+	source RestoreSource, components []string) (bool, error) {
+	var databases []string
+	for _, component := range components {
+		if component == BackupComponentPostgres || component == BackupComponentMongoDB {
+			databases = append(databases, component)
+		}
+	}
+
+	var containers []v1.Container
+	for _, component := range databases {
+		switch component {
+		case BackupComponentPostgres:
+			containers = append(containers, v1.Container{Name: component, Image: postgresClientImage,
+				Command: []string{"bash", "-c", restorePostgresScript}})
+		case BackupComponentMongoDB:
+			containers = append(containers, v1.Container{Name: component, Image: mongoClientImage,
+				Command: []string{"bash", "-c", restoreMongoScript}})
+		}
+	}
+	containers = append(containers, v1.Container{Name: restoreContainerBuckets, Image: r.MinioImage,
+		Command: []string{"bash", "-c", restoreBucketsScript}})
+	return r.runJob(ctx, modela, restore, source, "databases", databases, containers...)
+}
+
+// RestoreResources re-applies the tenants and the Modela API resources of their namespaces contained in the
+// backup. The resources are re-homed into their namespace with the NamespaceFilter and the tenants are normalized
+// with the TenantFilter, as when a tenant is installed. When tenants are given, only their resources are restored.
+// It returns the restored namespaces.
+func (r Restore) RestoreResources(ctx context.Context, modela *managementv1.Modela, source RestoreSource,
+	backup *managementv1.RestoredBackupStatus, tenants []string) ([]string, error) {
+	target, err := r.target(modela, source.Target)
+	if err != nil {
+		return nil, err
+	}
+	client, err := target.client()
+	if err != nil {
+		return nil, err
+	}
+
+	included := func(tenant string) bool {
+		if len(tenants) == 0 {
+			return true
+		}
+		for _, name := range tenants {
+			if name == tenant {
+				return true
+			}
+		}
+		return false
+	}
+
+	files := make(map[string][]string)
+	prefix := path.Join(source.Folder, BackupComponentResources) + "/"
+	for object := range client.ListObjects(ctx, target.Bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			return nil, errors.Wrapf(object.Err, "Failed to list the resources of backup %s", source.Folder)
+		}
+		namespace := path.Dir(strings.TrimPrefix(object.Key, prefix))
+		files[namespace] = append(files[namespace], object.Key)
+	}
+
+	labels := kube.LabelFilter{Labels: map[string]string{"management.modela.ai/operator": modela.Name}}
+
+	// The tenants of the installation are restored first, as their controller creates their namespace
+	for _, key := range files["."] {
+		nodes, err := r.readResources(ctx, client, target.Bucket, key)
+		if err != nil {
+			return nil, err
+		}
+
+		var restored []*yaml.RNode
+		for _, node := range nodes {
+			if node.GetKind() != "ModelaTenant" || !included(node.GetName()) {
+				continue
+			}
+			// Only the tenants of the backed up installation are restored
+			if ref, err := node.GetString("spec.modelaRef.name"); err == nil && ref != "" && ref != backup.Modela {
+				continue
+			}
+			for field, value := range map[string]string{"name": modela.Name, "namespace": modela.Namespace} {
+				if err := node.PipeE(yaml.LookupCreate(yaml.MappingNode, "spec", "modelaRef"),
+					yaml.SetField(field, yaml.NewStringRNode(value))); err != nil {
+					return nil, err
+				}
+			}
+			restored = append(restored, node)
+		}
+		if err := r.applyResources(ctx, restored, labels); err != nil {
+			return nil, err
+		}
+	}
+
+	var backedUp []string
+	for namespace := range files {
+		if namespace != "." {
+			backedUp = append(backedUp, namespace)
+		}
+	}
+	sort.Strings(backedUp)
+
+	var namespaces []string
+	for _, namespace := range backedUp {
+		if namespace != r.Namespace && !included(namespace) {
+			continue
+		}
+
+		var nodes []*yaml.RNode
+		for _, key := range files[namespace] {
+			read, err := r.readResources(ctx, client, target.Bucket, key)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, read...)
+		}
+
+		filters := []kio.Filter{labels}
+		if namespace == r.Namespace {
+			var restored []*yaml.RNode
+			for _, node := range nodes {
+				if node.GetKind() == "Tenant" && included(node.GetName()) {
+					if _, err := (kube.TenantFilter{TenantName: node.GetName()}).Filter([]*yaml.RNode{node}); err != nil {
+						return nil, err
+					}
+					restored = append(restored, node)
+				} else if len(tenants) == 0 {
+					restored = append(restored, node)
+				}
+			}
+			nodes = restored
+		} else {
+			if err := kube.CreateNamespace(namespace, modela.Name); err != nil {
+				return nil, err
+			}
+			filters = append(filters, kube.NamespaceFilter{Namespace: namespace})
+		}
+
+		if err := r.applyResources(ctx, nodes, filters...); err != nil {
+			return nil, errors.Wrapf(err, "Failed to restore the resources of namespace %s", namespace)
+		}
+		namespaces = append(namespaces, namespace)
+	}
+	return namespaces, nil
+}
+
+// Cleanup deletes the Secrets which hold the credentials of the jobs of a restore. The jobs are deleted by
+// Kubernetes a day after they finished.
+func (r Restore) Cleanup(restore *managementv1.ModelaRestore) error {
+	for _, step := range []string{BackupComponentVault, "databases"} {
+		if err := kube.DeleteSecret(r.Namespace, r.jobName(restore, step)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jobName returns the name of the job of a step of a restore, which is unique across the namespaces of restores
+func (r Restore) jobName(restore *managementv1.ModelaRestore, step string) string {
+	checksum := sha256.Sum256([]byte(restore.Namespace + "/" + restore.Name))
+	name := "modela-restore-" + step + "-" + invalidJobNameCharacters.ReplaceAllString(strings.ToLower(restore.Name), "-")
+	if len(name) > 54 {
+		name = name[:54]
+	}
+	return strings.TrimRight(name, "-") + "-" + hex.EncodeToString(checksum[:4])
+}
+
+// runJob runs the containers of a step of a restore through a job, after the dumps of the components have been
+// downloaded from the backup target. It returns true once the job has completed successfully, and a
+// JobFailedError if the job has failed.
+func (r Restore) runJob(ctx context.Context, modela *managementv1.Modela, restore *managementv1.ModelaRestore,
+	source RestoreSource, step string, components []string, containers ...v1.Container) (bool, error) {
+	name := r.jobName(restore, step)
+	job, err := kube.GetJob(r.Namespace, name)
+	if err != nil {
+		return false, err
+	}
+
+	if job != nil {
+		if finished, condition := kube.JobFinished(job); !finished {
+			return false, nil
+		} else if condition == batchv1.JobComplete {
+			return true, nil
+		}
+
+		statuses, err := jobContainerStatuses(r.Namespace, name)
+		if err != nil {
+			return false, err
+		}
+		message := fmt.Sprintf("The job %s failed", name)
+		for _, status := range statuses {
+			if status.Phase == managementv1.BackupRunPhaseFailed {
+				message = fmt.Sprintf("The restore of %s failed, see the logs of job %s: %s", status.Name, name, status.Message)
+				break
+			}
+		}
+		return false, &JobFailedError{Job: name, Message: message}
+	}
+
+	if err := r.prepare(modela); err != nil {
+		return false, err
+	}
+
+	target, err := r.target(modela, source.Target)
+	if err != nil {
+		return false, err
+	}
+	env := map[string]string{
+		"MODELA":            modela.Name,
+		"COMPONENTS":        strings.Join(components, ","),
+		"FOLDER":            source.Folder,
+		"TARGET_URL":        target.URL,
+		"TARGET_BUCKET":     target.Bucket,
+		"TARGET_REGION":     target.Region,
+		"TARGET_ACCESS_KEY": target.AccessKey,
+		"TARGET_SECRET_KEY": target.SecretKey,
+	}
+	cas := map[string]*v1.SecretKeySelector{"target": target.CA}
+	if target.CA != nil {
+		env["TARGET_CA"] = backupCAPath("target")
+	}
+	if err := databaseEnv(r.Namespace, modela, env, cas); err != nil {
+		return false, err
+	}
+	if err := r.objectStorageEnv(modela, env, cas); err != nil {
+		return false, err
+	}
+
+	labels := map[string]string{
+		"management.modela.ai/operator": modela.Name,
+		"management.modela.ai/restore":  restore.Name,
+	}
+	if err := kube.CreateOrUpdateLabeledSecret(r.Namespace, name, labels, env); err != nil {
+		return false, err
+	}
+
+	initContainers := []v1.Container{jobContainer(name, restoreContainerDownload, v1.Container{
+		Image:   r.MinioImage,
+		Command: []string{"bash", "-c", restoreDownloadScript},
+	}, cas)}
+	for _, container := range containers[:len(containers)-1] {
+		initContainers = append(initContainers, jobContainer(name, container.Name, container, cas))
+	}
+	main := containers[len(containers)-1]
+
+	log.FromContext(ctx).Info("Starting restore", "restore", restore.Name, "job", name, "components", components)
+	return false, kube.CreateJob(r.job(modela, name, labels, cas, initContainers, jobContainer(name, main.Name, main, cas)))
+}
+
+// readObject reads an object of the backup target
+func (r Restore) readObject(ctx context.Context, client *minio.Client, bucket string, key string) ([]byte, error) {
+	object, err := client.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+	return io.ReadAll(object)
+}
+
+// readResources reads the resources exported by the backup job into a file of the backup target. The metadata
+// assigned by the cluster which exported the resources and their status are removed.
+func (r Restore) readResources(ctx context.Context, client *minio.Client, bucket string, key string) ([]*yaml.RNode, error) {
+	data, err := r.readObject(ctx, client, bucket, key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to read %s from the backup target", key)
+	}
+	nodes, err := (&kio.ByteReader{Reader: bytes.NewReader(data), OmitReaderAnnotations: true}).Read()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse %s", key)
+	}
+	for _, node := range nodes {
+		if err := node.PipeE(yaml.Clear("status")); err != nil {
+			return nil, err
+		}
+		for _, field := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields",
+			"ownerReferences", "selfLink"} {
+			if err := node.PipeE(yaml.Lookup("metadata"), yaml.Clear(field)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nodes, nil
+}
+
+// applyResources applies resources after passing them through the filters
+func (r Restore) applyResources(ctx context.Context, nodes []*yaml.RNode, filters ...kio.Filter) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	var err error
+	for _, filter := range filters {
+		if nodes, err = filter.Filter(nodes); err != nil {
+			return err
+		}
+	}
+
+	var out bytes.Buffer
+	if err := (kio.ByteWriter{Writer: &out}).Write(nodes); err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("Restoring resources", "count", len(nodes))
+	return kube.ApplyYaml(out.String())
+}
+
+// parseChecksums parses the output of sha256sum into the checksum of each file, relative to the backup folder
+func parseChecksums(data []byte) (map[string]string, error) {
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 || len(fields[0]) != sha256.Size*2 {
+			return nil, errors.Errorf("Invalid checksum %q", line)
+		}
+		checksums[strings.TrimPrefix(strings.TrimPrefix(fields[1], "*"), "./")] = fields[0]
+	}
+	if len(checksums) == 0 {
+		return nil, errors.New("The backup does not contain any checksums")
+	}
+	return checksums, scanner.Err()
+}
+
+// containsPrefix returns true if the name of a file starts with a prefix
+func containsPrefix(files map[string]string, prefix string) bool {
+	for file := range files {
+		if strings.HasPrefix(file, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package components
+
+import (
+	"github.com/metaprov/modela-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
+)
+
+var _ = Describe("Restore", func() {
+	restore := NewRestore()
+
+	It("Should only restore backups of a compatible distribution", func() {
+		Expect(CompatibleDistribution("v1.2.0", "v1.2.0")).To(Succeed())
+		Expect(CompatibleDistribution("v1.2.0", "v1.2.3")).To(Succeed())
+		Expect(CompatibleDistribution("stable", "stable")).To(Succeed())
+		Expect(CompatibleDistribution("v1.2.3", "v1.2.0")).NotTo(Succeed())
+		Expect(CompatibleDistribution("v1.1.0", "v1.2.0")).NotTo(Succeed())
+		Expect(CompatibleDistribution("develop", "v1.2.0")).NotTo(Succeed())
+	})
+
+	It("Should parse the checksums of a backup", func() {
+		sum := strings.Repeat("a", 64)
+		checksums, err := parseChecksums([]byte(sum + "  ./postgres/dumpall.sql.gz\n" + sum + " *./vault/vault.snap\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(checksums).To(Equal(map[string]string{"postgres/dumpall.sql.gz": sum, "vault/vault.snap": sum}))
+		Expect(containsPrefix(checksums, "vault/")).To(BeTrue())
+		Expect(containsPrefix(checksums, "mongodb/")).To(BeFalse())
+
+		_, err = parseChecksums([]byte("invalid  ./postgres/dumpall.sql.gz\n"))
+		Expect(err).To(HaveOccurred())
+		_, err = parseChecksums(nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should name the jobs of each restore uniquely", func() {
+		modelaRestore := &v1alpha1.ModelaRestore{ObjectMeta: metav1.ObjectMeta{
+			Name:      "restore-" + strings.Repeat("nightly-", 8),
+			Namespace: "modela-system",
+		}}
+		name := restore.jobName(modelaRestore, "databases")
+		Expect(len(name)).To(BeNumerically("<=", 63))
+		Expect(name).To(HavePrefix("modela-restore-databases-restore-nightly-"))
+		Expect(restore.jobName(modelaRestore, "vault")).NotTo(Equal(name))
+
+		other := modelaRestore.DeepCopy()
+		other.Namespace = "default"
+		Expect(restore.jobName(other, "databases")).NotTo(Equal(name))
+	})
+
+	It("Should report the components of a backup", func() {
+		var backup *v1alpha1.RestoredBackupStatus
+		Expect(backup.Contains("postgres")).To(BeFalse())
+		backup = &v1alpha1.RestoredBackupStatus{Components: []string{"postgres", "vault", "resources"}}
+		Expect(backup.Contains("vault")).To(BeTrue())
+		Expect(backup.Contains("mongodb")).To(BeFalse())
+	})
+})
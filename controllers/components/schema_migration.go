@@ -0,0 +1,208 @@
+package components
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	"github.com/metaprov/modelaapi/pkg/util"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"path"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"strings"
+	"time"
+
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+)
+
+// schemaMigrationContainer is the name of the container of the migration job
+const schemaMigrationContainer = "migrate"
+
+// SchemaMigration migrates the schemas of Postgres and MongoDB to a distribution of Modela before the images of the
+// distribution are rolled out. Each distribution is migrated by a job of the migration image of its ManagedImage
+// catalog, which receives the connection of the databases and writes the resulting schema version to its
+// termination message. A job which runs longer than the timeout is failed by Kubernetes. A failed job is kept until
+// it is deleted, which retries the migration. The job runs as its own service account, which has no access to the
+// API server or to Vault.
+type SchemaMigration struct {
+	Namespace           string
+	ServiceAccount      string
+	CatalogManifestPath string
+	ImageName           string
+	Timeout             time.Duration
+}
+
+func NewSchemaMigration() *SchemaMigration {
+	return &SchemaMigration{
+		Namespace:           "modela-system",
+		ServiceAccount:      "modela-schema-migration",
+		CatalogManifestPath: "modela-catalog/managedimages",
+		ImageName:           "modela-migrations",
+		Timeout:             2 * time.Hour,
+	}
+}
+
+// JobName returns the name of the migration job of a distribution
+func (m SchemaMigration) JobName(distribution string) string {
+	checksum := sha256.Sum256([]byte(distribution))
+	name := "modela-schema-migration-" + invalidJobNameCharacters.ReplaceAllString(strings.ToLower(distribution), "-")
+	if len(name) > 54 {
+		name = name[:54]
+	}
+	return strings.TrimRight(name, "-") + "-" + hex.EncodeToString(checksum[:4])
+}
+
+// Image returns the migration image of a distribution, which is read from the ManagedImage catalog rendered for
+// the distribution
+func (m SchemaMigration) Image(distribution string) (string, error) {
+	data, _, err := kube.LoadResources(m.CatalogManifestPath, []kio.Filter{kube.ManagedImageFilter{Version: distribution}}, true)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to load the ManagedImage catalog")
+	}
+	return managedImage(data, m.ImageName)
+}
+
+// managedImage returns the reference of the image of the ManagedImage resource whose repository has the name
+func managedImage(data []byte, name string) (string, error) {
+	nodes, err := kio.FromBytes(data)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to parse the ManagedImage catalog")
+	}
+	for _, node := range nodes {
+		if node.GetKind() != "ManagedImage" {
+			continue
+		}
+		if repository, _ := node.GetString("spec.repository"); path.Base(repository) == name {
+			registry, _ := node.GetString("spec.registry")
+			tag, _ := node.GetString("spec.tag")
+			if registry != "" {
+				repository = registry + "/" + repository
+			}
+			return repository + ":" + tag, nil
+		}
+	}
+	return "", errors.Errorf("The ManagedImage catalog does not contain the image %s", name)
+}
+
+// Run runs the migration described by the status, creating its job if it does not exist. It returns the schema
+// version reported by the job once it has succeeded, and a JobFailedError if the job has failed. The job and the
+// Secret which holds its environment are deleted once the job has succeeded.
+func (m SchemaMigration) Run(ctx context.Context, modela *managementv1.Modela, status *managementv1.SchemaMigrationStatus) (string, bool, error) {
+	job, err := kube.GetJob(m.Namespace, status.Job)
+	if err != nil {
+		return "", false, err
+	}
+
+	if job != nil {
+		if finished, condition := kube.JobFinished(job); !finished {
+			return "", false, nil
+		} else if condition == batchv1.JobFailed {
+			message := fmt.Sprintf("The job %s failed", status.Job)
+			if jobFailureReason(job) == "DeadlineExceeded" {
+				message = fmt.Sprintf("The job %s did not finish within %s", status.Job, m.Timeout)
+			}
+			if statuses, err := jobContainerStatuses(m.Namespace, status.Job); err != nil {
+				return "", false, err
+			} else if len(statuses) > 0 && statuses[0].Message != "" {
+				message = fmt.Sprintf("%s: %s", message, statuses[0].Message)
+			}
+			return "", false, &JobFailedError{Job: status.Job, Message: message}
+		}
+
+		version, err := m.schemaVersion(status.Job)
+		if err != nil {
+			return "", false, err
+		} else if version == "" {
+			version = status.Distribution
+		}
+		if err := kube.DeleteJob(m.Namespace, status.Job); err != nil {
+			return "", false, err
+		}
+		return version, true, kube.DeleteSecret(m.Namespace, status.Job)
+	}
+
+	if err := kube.CreateServiceAccount(m.Namespace, m.ServiceAccount, map[string]string{
+		"management.modela.ai/operator": modela.Name,
+	}); err != nil {
+		return "", false, err
+	}
+
+	env := map[string]string{
+		"MODELA":            modela.Name,
+		"FROM_DISTRIBUTION": status.FromDistribution,
+		"TO_DISTRIBUTION":   status.Distribution,
+		"SCHEMA_VERSION":    modela.Status.SchemaVersion,
+	}
+	cas := map[string]*v1.SecretKeySelector{}
+	if err := databaseEnv(m.Namespace, modela, env, cas); err != nil {
+		return "", false, err
+	}
+
+	labels := map[string]string{
+		"management.modela.ai/operator":         modela.Name,
+		"management.modela.ai/schema-migration": status.Job,
+	}
+	if err := kube.CreateOrUpdateLabeledSecret(m.Namespace, status.Job, labels, env); err != nil {
+		return "", false, err
+	}
+
+	log.FromContext(ctx).Info("Starting schema migration", "job", status.Job, "image", status.Image,
+		"from", status.FromDistribution, "to", status.Distribution)
+	return "", false, kube.CreateJob(m.job(status, labels, cas))
+}
+
+// job returns the migration job described by the status. The migration only connects to the databases, so the
+// token of its service account is not mounted.
+func (m SchemaMigration) job(status *managementv1.SchemaMigrationStatus, labels map[string]string,
+	cas map[string]*v1.SecretKeySelector) *batchv1.Job {
+	container := jobContainer(status.Job, schemaMigrationContainer, v1.Container{Image: status.Image}, cas)
+	job := newJob(m.Namespace, m.ServiceAccount, status.Job, labels, jobVolumes(cas), nil, container)
+	// The failed job blocks the upgrade until it is deleted
+	job.Spec.TTLSecondsAfterFinished = nil
+	job.Spec.ActiveDeadlineSeconds = util.Int64Ptr(int64(m.Timeout.Seconds()))
+	job.Spec.Template.Spec.AutomountServiceAccountToken = util.BoolPtr(false)
+	return job
+}
+
+// Waiting returns the reason for which the container of a running migration job is waiting to start, such as
+// ImagePullBackOff, or an empty string if it is not waiting
+func (m SchemaMigration) Waiting(job string) (string, error) {
+	statuses, err := jobContainerStatuses(m.Namespace, job)
+	if err != nil {
+		return "", err
+	}
+	for _, status := range statuses {
+		if status.Phase == managementv1.BackupRunPhasePending && status.Message != "" {
+			return status.Message, nil
+		}
+	}
+	return "", nil
+}
+
+// jobFailureReason returns the reason of the failed condition of a job, such as DeadlineExceeded
+func jobFailureReason(job *batchv1.Job) string {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == v1.ConditionTrue {
+			return condition.Reason
+		}
+	}
+	return ""
+}
+
+// schemaVersion returns the schema version written by the migration job to its termination message
+func (m SchemaMigration) schemaVersion(job string) (string, error) {
+	pods, err := kube.ListPods(m.Namespace, "job-name="+job)
+	if err != nil || len(pods) == 0 {
+		return "", err
+	}
+	for _, status := range pods[len(pods)-1].Status.ContainerStatuses {
+		if status.Name == schemaMigrationContainer && status.State.Terminated != nil {
+			return strings.TrimSpace(status.State.Terminated.Message), nil
+		}
+	}
+	return "", nil
+}
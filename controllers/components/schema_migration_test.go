@@ -0,0 +1,75 @@
+package components
+
+import (
+	"github.com/metaprov/modelaapi/pkg/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"strings"
+
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+)
+
+var _ = Describe("Schema migration", func() {
+	migration := NewSchemaMigration()
+
+	It("Should read the migration image from the ManagedImage catalog", func() {
+		catalog := `apiVersion: catalog.modela.ai/v1alpha1
+kind: ManagedImage
+metadata:
+  name: modela-control-plane-latest
+spec:
+  registry: ghcr.io
+  repository: metaprov/modela-control-plane
+  tag: v1.2.0
+---
+apiVersion: catalog.modela.ai/v1alpha1
+kind: ManagedImage
+metadata:
+  name: modela-migrations-latest
+spec:
+  registry: ghcr.io
+  repository: metaprov/modela-migrations
+  tag: v1.2.0
+`
+		image, err := managedImage([]byte(catalog), migration.ImageName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal("ghcr.io/metaprov/modela-migrations:v1.2.0"))
+
+		_, err = managedImage([]byte(catalog), "modela-frontend")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should name the migration job of each distribution uniquely", func() {
+		name := migration.JobName("v1.2.0")
+		Expect(name).To(HavePrefix("modela-schema-migration-v1-2-0-"))
+		Expect(migration.JobName("v1.2.1")).NotTo(Equal(name))
+		Expect(migration.JobName("V1.2.0")).NotTo(Equal(name))
+		Expect(len(migration.JobName("release-" + strings.Repeat("candidate-", 8)))).To(BeNumerically("<=", 63))
+	})
+
+	It("Should read the reason of a failed migration job", func() {
+		job := &batchv1.Job{Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+			{Type: batchv1.JobSuspended, Status: v1.ConditionFalse, Reason: "JobResumed"},
+			{Type: batchv1.JobFailed, Status: v1.ConditionTrue, Reason: "DeadlineExceeded"},
+		}}}
+		Expect(jobFailureReason(job)).To(Equal("DeadlineExceeded"))
+		Expect(jobFailureReason(&batchv1.Job{})).To(BeEmpty())
+		Expect(migration.Timeout).To(BeNumerically(">", 0))
+	})
+
+	It("Should run the migration job as a service account without access to the API server", func() {
+		job := migration.job(&managementv1.SchemaMigrationStatus{
+			Job:   migration.JobName("v1.2.0"),
+			Image: "ghcr.io/metaprov/modela-migrations:v1.2.0",
+		}, nil, map[string]*v1.SecretKeySelector{})
+		spec := job.Spec.Template.Spec
+		Expect(spec.ServiceAccountName).To(Equal(migration.ServiceAccount))
+		Expect(spec.ServiceAccountName).NotTo(Equal(NewBackup().ServiceAccount))
+		Expect(spec.AutomountServiceAccountToken).To(Equal(util.BoolPtr(false)))
+		Expect(job.Spec.ActiveDeadlineSeconds).To(Equal(util.Int64Ptr(int64(migration.Timeout.Seconds()))))
+		Expect(job.Spec.TTLSecondsAfterFinished).To(BeNil())
+		Expect(spec.Containers[0].Image).To(Equal("ghcr.io/metaprov/modela-migrations:v1.2.0"))
+	})
+})
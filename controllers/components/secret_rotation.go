@@ -0,0 +1,287 @@
+package components
+
+import (
+	"context"
+	"github.com/Masterminds/goutils"
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+	"github.com/metaprov/modela-operator/pkg/helm"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	"github.com/metaprov/modela-operator/pkg/secrets"
+	"github.com/pkg/errors"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// The names of the credentials which can be rotated
+const (
+	RotatedJwtSecret     = "jwt-secret"
+	RotatedApiKeySecret  = "api-key-secret"
+	RotatedPostgres      = "postgres"
+	RotatedMongo         = "mongo"
+	RotatedRedis         = "redis"
+	RotatedObjectStorage = "object-storage"
+)
+
+// The rotation scripts change the password from the current password to the pending password, which are read from
+// the first two lines of their standard input so that they are not recorded by the audit log of the API server. A
+// database whose password was already changed by a failed rotation is accepted when it can be logged in with the
+// pending password.
+const (
+	postgresRotateScript = `read -r current; read -r password
+PGPASSWORD="$current" psql -U postgres -c "ALTER USER postgres WITH PASSWORD '$password'" ||
+PGPASSWORD="$password" psql -U postgres -c "SELECT 1"`
+	mongoRotateScript = `read -r current; read -r password
+mongosh admin --quiet --host "${1:-localhost}" -u root -p "$current" --eval "db.changeUserPassword('root', '$password')" ||
+mongosh admin --quiet --host "${1:-localhost}" -u root -p "$password" --eval "db.runCommand({ping: 1})"`
+)
+
+// pendingPasswordKey is the key of the Secret of a database release which holds the password being rotated to,
+// until the release and the connections of the tenants are updated. A failed rotation is completed with it.
+const pendingPasswordKey = "pending-password"
+
+// RotationInterval associates a rotated credential with the interval at which it is rotated
+type RotationInterval struct {
+	Name     string
+	Interval metav1.Duration
+}
+
+// SecretRotation rotates the credentials of the components managed by the Modela Operator. After rotating a
+// credential, the backing Helm release or database is updated first, then Vault, and finally the workloads
+// which consume the credential are restarted.
+type SecretRotation struct {
+	Namespace       string
+	SystemConsumers []string
+}
+
+func NewSecretRotation() *SecretRotation {
+	return &SecretRotation{
+		Namespace:       "modela-system",
+		SystemConsumers: []string{"modela-control-plane", "modela-data-plane", "modela-api-gateway"},
+	}
+}
+
+// Intervals returns the credentials which have rotation enabled
+func (sr SecretRotation) Intervals(modela managementv1.Modela) []RotationInterval {
+	var intervals []RotationInterval
+	spec := modela.Spec.SecretRotation
+	for _, rotation := range []struct {
+		name     string
+		interval *metav1.Duration
+		enabled  bool
+	}{
+		{RotatedJwtSecret, spec.JwtSecret, true},
+		{RotatedApiKeySecret, spec.ApiKeySecret, true},
+		{RotatedPostgres, spec.Postgres, NewPostgresDatabase().IsEnabled(modela)},
+		{RotatedMongo, spec.Mongo, NewMongoDatabase().IsEnabled(modela)},
+		{RotatedRedis, spec.Redis, modela.Spec.OnlineStore.Install && modela.Spec.OnlineStore.External == nil},
+		{RotatedObjectStorage, spec.ObjectStorage, NewObjectStorage().IsEnabled(modela)},
+	} {
+		if rotation.interval != nil && rotation.interval.Duration > 0 && rotation.enabled {
+			intervals = append(intervals, RotationInterval{Name: rotation.name, Interval: *rotation.interval})
+		}
+	}
+	return intervals
+}
+
+// Rotate rotates a single credential. The API key secrets and connections of the given tenants are updated with it.
+func (sr SecretRotation) Rotate(ctx context.Context, modela *managementv1.Modela, tenants []string, name string) error {
+	log.FromContext(ctx).Info("Rotating credential", "name", name)
+	switch name {
+	case RotatedJwtSecret:
+		return sr.rotateJwtSecret(ctx, modela)
+	case RotatedApiKeySecret:
+		return sr.rotateApiKeySecrets(ctx, modela, tenants)
+	case RotatedPostgres:
+		return sr.rotatePostgres(ctx, modela, tenants)
+	case RotatedMongo:
+		return sr.rotateMongo(ctx, modela, tenants)
+	case RotatedRedis:
+		return sr.rotateRedis(ctx, modela)
+	case RotatedObjectStorage:
+		return sr.rotateObjectStorage(ctx, modela, tenants)
+	}
+	return errors.Errorf("unknown credential %s", name)
+}
+
+func (sr SecretRotation) rotateJwtSecret(ctx context.Context, modela *managementv1.Modela) error {
+	token, err := goutils.RandomAlphaNumeric(32)
+	if err != nil {
+		return err
+	}
+
+	store, err := secrets.GetSecretStore(modela)
+	if err != nil {
+		return err
+	}
+
+	if err := store.ApplySecret("jwt-secret", map[string]interface{}{"token": token}); err != nil {
+		return err
+	}
+
+	if _, err := kube.GetSecret(sr.Namespace, "modela-auth-token"); err == nil {
+		if err := kube.CreateOrUpdateSecret(sr.Namespace, "modela-auth-token", map[string]string{"jwt-secret": token}); err != nil {
+			return err
+		}
+	} else if !k8serr.IsNotFound(err) {
+		return err
+	}
+
+	return sr.restartSystemConsumers()
+}
+
+func (sr SecretRotation) rotateApiKeySecrets(ctx context.Context, modela *managementv1.Modela, tenants []string) error {
+	for _, tenant := range tenants {
+		if err := NewTenant(tenant).ApplyApiKeySecret(ctx, modela); err != nil {
+			return err
+		}
+	}
+
+	return sr.restartSystemConsumers()
+}
+
+func (sr SecretRotation) rotatePostgres(ctx context.Context, modela *managementv1.Modela, tenants []string) error {
+	postgres := NewPostgresDatabase()
+	values, err := kube.GetSecretValuesAsString(postgres.Namespace, postgres.ReleaseName)
+	if err != nil {
+		return err
+	}
+
+	password, err := sr.pendingPassword(postgres.Namespace, postgres.ReleaseName, values)
+	if err != nil {
+		return err
+	}
+
+	if _, err := kube.ExecCommandWithInput(postgres.Namespace, postgres.Primary(modela)+"-0", "postgresql",
+		[]string{"sh", "-c", postgresRotateScript}, values["postgres-password"]+"\n"+password+"\n"); err != nil {
+		return err
+	}
+
+	if err := helm.UpgradeChart(ctx, postgres.Name, postgres.Namespace, postgres.ReleaseName, map[string]interface{}{
+		"auth": map[string]interface{}{"postgresPassword": password},
+	}); err != nil {
+		return err
+	}
+
+	if err := sr.propagateConnections(ctx, modela, tenants); err != nil {
+		return err
+	}
+	return kube.DeleteSecretKeys(postgres.Namespace, postgres.ReleaseName, pendingPasswordKey)
+}
+
+func (sr SecretRotation) rotateMongo(ctx context.Context, modela *managementv1.Modela, tenants []string) error {
+	mongo := NewMongoDatabase()
+	values, err := kube.GetSecretValuesAsString(mongo.Namespace, mongo.ReleaseName)
+	if err != nil {
+		return err
+	}
+
+	password, err := sr.pendingPassword(mongo.Namespace, mongo.ReleaseName, values)
+	if err != nil {
+		return err
+	}
+
+	// Inside a replica set, the password is changed through the primary
+	command := []string{"sh", "-c", mongoRotateScript}
+	if modela.Spec.Database.HA.Enabled {
+		command = append(command, "sh", mongo.ShellHost(modela))
+	}
+	input := values["mongodb-root-password"] + "\n" + password + "\n"
+	if _, err := kube.ExecCommandWithInput(mongo.Namespace, mongo.ReleaseName+"-0", "mongodb", command, input); err != nil {
+		return err
+	}
+
+	if err := helm.UpgradeChart(ctx, mongo.Name, mongo.Namespace, mongo.ReleaseName, map[string]interface{}{
+		"auth": map[string]interface{}{"rootPassword": password},
+	}); err != nil {
+		return err
+	}
+
+	if err := sr.propagateConnections(ctx, modela, tenants); err != nil {
+		return err
+	}
+	return kube.DeleteSecretKeys(mongo.Namespace, mongo.ReleaseName, pendingPasswordKey)
+}
+
+// pendingPassword returns the pending password of a database release left by a failed rotation, or generates a new
+// password and persists it as pending before the password of the database is changed
+func (sr SecretRotation) pendingPassword(ns string, name string, values map[string]string) (string, error) {
+	if password := values[pendingPasswordKey]; password != "" {
+		return password, nil
+	}
+
+	password, err := goutils.RandomAlphaNumeric(32)
+	if err != nil {
+		return "", err
+	}
+	if err := kube.CreateOrUpdateSecret(ns, name, map[string]string{pendingPasswordKey: password}); err != nil {
+		return "", errors.Wrapf(err, "Failed to persist the pending password of %s", name)
+	}
+	return password, nil
+}
+
+func (sr SecretRotation) rotateRedis(ctx context.Context, modela *managementv1.Modela) error {
+	onlineStore := NewOnlineStore()
+	password, err := goutils.RandomAlphaNumeric(32)
+	if err != nil {
+		return err
+	}
+
+	// Redis reads its password on startup, so the release must be restarted with the new password
+	if err := helm.UpgradeChart(ctx, onlineStore.Name, onlineStore.Namespace, onlineStore.ReleaseName, map[string]interface{}{
+		"auth": map[string]interface{}{"password": password},
+	}); err != nil {
+		return err
+	}
+
+	if err := kube.RestartReleaseWorkloads(onlineStore.Namespace, onlineStore.ReleaseName); err != nil {
+		return err
+	}
+
+	if err := onlineStore.InstallNewVersion(ctx, modela); err != nil {
+		return err
+	}
+
+	return kube.RestartDeployment(onlineStore.Namespace, onlineStore.PodNamePrefix)
+}
+
+func (sr SecretRotation) rotateObjectStorage(ctx context.Context, modela *managementv1.Modela, tenants []string) error {
+	objectStorage := NewObjectStorage()
+	password, err := goutils.RandomAlphaNumeric(32)
+	if err != nil {
+		return err
+	}
+
+	// Minio reads its root credentials on startup, so the release must be restarted with the new password
+	if err := helm.UpgradeChart(ctx, objectStorage.Name, objectStorage.Namespace, objectStorage.ReleaseName, map[string]interface{}{
+		"auth": map[string]interface{}{"rootPassword": password},
+	}); err != nil {
+		return err
+	}
+
+	if err := kube.RestartReleaseWorkloads(objectStorage.Namespace, objectStorage.ReleaseName); err != nil {
+		return err
+	}
+
+	return sr.propagateConnections(ctx, modela, tenants)
+}
+
+// propagateConnections updates the connection secrets of the tenants and restarts the system consumers
+func (sr SecretRotation) propagateConnections(ctx context.Context, modela *managementv1.Modela, tenants []string) error {
+	for _, tenant := range tenants {
+		if err := NewTenant(tenant).ApplyConnections(ctx, modela); err != nil {
+			return err
+		}
+	}
+
+	return sr.restartSystemConsumers()
+}
+
+func (sr SecretRotation) restartSystemConsumers() error {
+	for _, deployment := range sr.SystemConsumers {
+		if err := kube.RestartDeployment(sr.Namespace, deployment); err != nil && !k8serr.IsNotFound(errors.Cause(err)) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package components
+
+import (
+	"github.com/metaprov/modela-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
+
+var _ = Describe("Secret rotation", func() {
+	rotation := NewSecretRotation()
+
+	It("Should only rotate credentials with an interval", func() {
+		intervals := rotation.Intervals(v1alpha1.Modela{
+			Spec: v1alpha1.ModelaSpec{
+				SecretRotation: v1alpha1.SecretRotationSpec{
+					JwtSecret: &v1.Duration{Duration: time.Hour},
+					Postgres:  &v1.Duration{Duration: 24 * time.Hour},
+				},
+			},
+		})
+		Expect(intervals).To(HaveLen(2))
+		Expect(intervals[0].Name).To(Equal(RotatedJwtSecret))
+		Expect(intervals[1].Name).To(Equal(RotatedPostgres))
+	})
+
+	It("Should not rotate the credentials of components which are not installed", func() {
+		intervals := rotation.Intervals(v1alpha1.Modela{
+			Spec: v1alpha1.ModelaSpec{
+				Database:    v1alpha1.DatabaseSpec{InstallMongoDB: false},
+				OnlineStore: v1alpha1.OnlineStoreSpec{Install: false},
+				SecretRotation: v1alpha1.SecretRotationSpec{
+					Mongo: &v1.Duration{Duration: time.Hour},
+					Redis: &v1.Duration{Duration: time.Hour},
+				},
+			},
+		})
+		Expect(intervals).To(BeEmpty())
+	})
+})
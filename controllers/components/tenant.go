@@ -6,8 +6,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"github.com/metaprov/modela-operator/pkg/kube"
-	"github.com/metaprov/modela-operator/pkg/vault"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/metaprov/modela-operator/pkg/secrets"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/kustomize/kyaml/kio"
@@ -47,21 +46,30 @@ func (t Tenant) Install(ctx context.Context, modela *managementv1.Modela, tenant
 		return err
 	}
 
-	var adminPassword string
-	if tenant.AdminPassword != nil {
-		adminPassword = *tenant.AdminPassword
-	} else {
-		adminPassword = "default"
+	adopted, err := t.adopt(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := t.ApplyDeletionPolicy(ctx, tenant.DeletionPolicy); err != nil {
+		return err
 	}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(adminPassword), bcrypt.MinCost)
+	if err := t.ApplyQuota(ctx, modela, tenant.Quota); err != nil {
+		return err
+	}
+
+	workspaces, err := workspaceFilter(tenant.TenantConfig)
 	if err != nil {
 		return err
 	}
 
-	if err := vault.ApplySecret(modela, fmt.Sprintf("tenant/%s/accounts/admin", t.Name), map[string]interface{}{
-		"password": string(hash),
-	}); err != nil {
+	store, err := secrets.GetSecretStore(modela)
+	if err != nil {
+		return err
+	}
+
+	if err := store.ApplyTenant(t.Name); err != nil {
 		return err
 	}
 
@@ -69,10 +77,8 @@ func (t Tenant) Install(ctx context.Context, modela *managementv1.Modela, tenant
 		kube.LabelFilter{Labels: map[string]string{"management.modela.ai/operator": modela.Name}},
 		kube.NamespaceFilter{Namespace: t.Name},
 		kube.TenantFilter{TenantName: t.Name},
-		kube.ConnectionFilter{
-			PgvectorEnabled: modela.Spec.Database.InstallPgvector,
-			MongoEnabled:    modela.Spec.Database.InstallMongoDB,
-		},
+		workspaces,
+		connectionFilter(modela),
 	}, false)
 	if err != nil {
 		return err
@@ -85,64 +91,31 @@ func (t Tenant) Install(ctx context.Context, modela *managementv1.Modela, tenant
 		}
 	}
 
-	key := make([]byte, 32)
-	_, err = rand.Read(key)
-	if err != nil {
-		return err
-	}
-
-	if err := vault.ApplySecret(modela, fmt.Sprintf("tenant/%s/api-key-secret", t.Name), map[string]interface{}{
-		"secret": hex.EncodeToString(key),
-	}); err != nil {
-		return err
-	}
-
-	if values, err := kube.GetSecretValuesAsString("modela-system", "modela-storage-minio"); err == nil {
-		accessKey, _ := values["root-user"]
-		secretKey, _ := values["root-password"]
-
-		logger.Info("Applying minio secret")
-		if err := vault.ApplySecret(modela, fmt.Sprintf("tenant/%s/connections/minio-connection", t.Name), map[string]interface{}{
-			"accessKey": accessKey,
-			"secretKey": secretKey,
-			"host":      "modela-storage-minio.modela-system.svc.cluster.local:9000",
-		}); err != nil {
+	// The secrets of a re-adopted tenant are retained, and its API key remains valid
+	if !adopted {
+		if err := t.ApplyApiKeySecret(ctx, modela); err != nil {
 			return err
 		}
 	}
 
-	if values, err := kube.GetSecretValuesAsString("modela-system", "modela-postgresql"); err == nil {
-		password, _ := values["postgres-password"]
-
-		logger.Info("Applying postgres secret")
+	return t.ApplyConnections(ctx, modela)
+}
 
-		for _, conn := range []string{"postgres-connection", "postgres-vector-connection"} {
-			if err := vault.ApplySecret(modela, fmt.Sprintf("tenant/%s/connections/%s", t.Name, conn), map[string]interface{}{
-				"username": "postgres",
-				"password": password,
-				"host":     "modela-postgresql.modela-system.svc.cluster.local",
-				"port":     "5432",
-			}); err != nil {
-				return err
-			}
-		}
+// ApplyApiKeySecret generates a new API key secret for the tenant and stores it in the secret store
+func (t Tenant) ApplyApiKeySecret(ctx context.Context, modela *managementv1.Modela) error {
+	store, err := secrets.GetSecretStore(modela)
+	if err != nil {
+		return err
 	}
 
-	if values, err := kube.GetSecretValuesAsString("modela-system", "modela-mongodb"); err == nil {
-		password, _ := values["mongodb-root-password"]
-
-		logger.Info("Applying mongo secret")
-		if err := vault.ApplySecret(modela, fmt.Sprintf("tenant/%s/connections/mongodb-connection", t.Name), map[string]interface{}{
-			"username": "root",
-			"password": password,
-			"host":     "modela-mongodb.modela-system.svc.cluster.local",
-			"port":     "27017",
-		}); err != nil {
-			return err
-		}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return err
 	}
 
-	return nil
+	return store.ApplySecret(fmt.Sprintf("tenant/%s/api-key-secret", t.Name), map[string]interface{}{
+		"secret": hex.EncodeToString(key),
+	})
 }
 
 func (t Tenant) Installing(ctx context.Context) (bool, error) {
@@ -153,8 +126,14 @@ func (t Tenant) Installing(ctx context.Context) (bool, error) {
 	return false, nil
 }
 
+// Ready checks that the resources of the tenant exist. The labs and serving sites of the tenant are reported
+// separately by ReadyLabs and ReadyServingSites, as they depend on the spec of the tenant.
 func (t Tenant) Ready(ctx context.Context) (bool, error) {
-	if _, missing, err := kube.LoadResources(t.ManifestPath, []kio.Filter{kube.NamespaceFilter{Namespace: t.Name}, kube.TenantFilter{TenantName: t.Name}}, false); missing > 0 {
+	if _, missing, err := kube.LoadResources(t.ManifestPath, []kio.Filter{
+		kube.NamespaceFilter{Namespace: t.Name},
+		kube.TenantFilter{TenantName: t.Name},
+		kube.KindFilter{Kinds: []string{"Lab", "ServingSite"}, Exclude: true},
+	}, false); missing > 0 {
 		return false, managementv1.ComponentMissingResourcesError
 	} else if err != nil {
 		return false, err
@@ -162,6 +141,57 @@ func (t Tenant) Ready(ctx context.Context) (bool, error) {
 	return true, nil
 }
 
+// SecretKeys returns the keys of the secrets generated for the tenant inside the secret store
+func (t Tenant) SecretKeys(modela *managementv1.Modela, config managementv1.TenantConfig) []string {
+	keys := []string{
+		fmt.Sprintf("tenant/%s/accounts/admin", t.Name),
+		fmt.Sprintf("tenant/%s/api-key-secret", t.Name),
+	}
+	for _, source := range connectionSources(modela) {
+		for _, connection := range source.Connections {
+			keys = append(keys, t.connectionSecretKey(connection))
+		}
+	}
+	for _, account := range config.Accounts {
+		if !account.SSOOnly {
+			keys = append(keys, t.accountSecretKey(account.Username))
+		}
+	}
+	return keys
+}
+
+// ReadyLabs returns the names of the labs of the tenant which are ready
+func (t Tenant) ReadyLabs(ctx context.Context) ([]string, error) {
+	labs, err := kube.ListLabs(t.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var ready []string
+	for _, lab := range labs {
+		if lab.IsReady() {
+			ready = append(ready, lab.Name)
+		}
+	}
+	return ready, nil
+}
+
+// ReadyServingSites returns the names of the serving sites of the tenant which are ready
+func (t Tenant) ReadyServingSites(ctx context.Context) ([]string, error) {
+	sites, err := kube.ListServingSites(t.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var ready []string
+	for _, site := range sites {
+		if site.IsReady() {
+			ready = append(ready, site.Name)
+		}
+	}
+	return ready, nil
+}
+
 func (d Tenant) Uninstall(ctx context.Context, modela *managementv1.Modela) error {
 	if created, err := kube.IsNamespaceCreatedByOperator(d.Name, modela.Name); !created {
 		return managementv1.ComponentNotInstalledByModelaError
@@ -173,5 +203,18 @@ func (d Tenant) Uninstall(ctx context.Context, modela *managementv1.Modela) erro
 		return err
 	}
 
-	return nil
+	if err := kube.DeleteSecret(modela.Namespace, AdminPasswordSecretName(d.Name)); err != nil {
+		return err
+	}
+
+	if err := d.DropConnections(ctx, modela); err != nil {
+		return err
+	}
+
+	store, err := secrets.GetSecretStore(modela)
+	if err != nil {
+		return err
+	}
+
+	return store.DeleteTenant(d.Name)
 }
@@ -0,0 +1,152 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	"github.com/metaprov/modela-operator/pkg/secrets"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+)
+
+// ApplyAccounts reconciles the declarative accounts of the tenant. The password of each account is hashed and
+// written to the secret store when its Secret changes, the Account resources and the role bindings of the Tenant
+// are applied when an account is added or changed, and the accounts removed from the spec are deleted along
+// with their password.
+func (t Tenant) ApplyAccounts(ctx context.Context, modela *managementv1.Modela, config managementv1.TenantConfig) error {
+	logger := log.FromContext(ctx)
+
+	if err := validateAccounts(config.Accounts); err != nil {
+		return err
+	}
+
+	workspaces, err := workspaceFilter(config)
+	if err != nil {
+		return err
+	}
+
+	existing, err := kube.ListAccounts(t.Name, map[string]string{kube.DeclarativeAccountLabel: "true"})
+	if err != nil {
+		return errors.Wrapf(err, "Failed to list the accounts of tenant %s", t.Name)
+	}
+	applied := make(map[string]map[string]string, len(existing))
+	for _, account := range existing {
+		applied[account.Name] = account.Annotations
+	}
+
+	store, err := secrets.GetSecretStore(modela)
+	if err != nil {
+		return err
+	}
+
+	var changed bool
+	var accounts []kube.TenantAccount
+	for _, spec := range config.Accounts {
+		account := kube.TenantAccount{
+			Username:  spec.Username,
+			Email:     spec.Email,
+			FirstName: spec.FirstName,
+			LastName:  spec.LastName,
+			RoleClass: spec.RoleClass,
+		}
+		if account.RoleClass == "" {
+			account.RoleClass = "administrator"
+		}
+
+		annotations, exists := applied[spec.Username]
+		if spec.SSOOnly {
+			account.PasswordVersion = "sso"
+		} else {
+			password, version, err := secretPassword(modela.Namespace, spec.PasswordSecretRef)
+			if err != nil {
+				return err
+			}
+			account.PasswordVersion = fmt.Sprintf("%s@%d", version, passwordHashCost(config))
+
+			if !exists || annotations[kube.PasswordVersionAnnotation] != account.PasswordVersion {
+				hash, err := bcrypt.GenerateFromPassword([]byte(password), passwordHashCost(config))
+				if err != nil {
+					return errors.Wrapf(err, "Failed to hash the password of account %s", spec.Username)
+				}
+				if err := store.ApplySecret(t.accountSecretKey(spec.Username), map[string]interface{}{
+					"password": string(hash),
+				}); err != nil {
+					return err
+				}
+			}
+		}
+
+		if exists && spec.SSOOnly && annotations[kube.PasswordVersionAnnotation] != account.PasswordVersion {
+			// The account no longer uses a password
+			if err := store.DeleteSecret(t.accountSecretKey(spec.Username)); err != nil {
+				return err
+			}
+		}
+
+		if !exists || annotations[kube.PasswordVersionAnnotation] != account.PasswordVersion ||
+			annotations[kube.RoleClassAnnotation] != account.RoleClass {
+			changed = true
+		}
+		accounts = append(accounts, account)
+		delete(applied, spec.Username)
+	}
+
+	if changed || len(applied) > 0 {
+		yaml, _, err := kube.LoadResources(t.ManifestPath, []kio.Filter{
+			kube.LabelFilter{Labels: map[string]string{"management.modela.ai/operator": modela.Name}},
+			kube.NamespaceFilter{Namespace: t.Name},
+			kube.TenantFilter{TenantName: t.Name},
+			workspaces,
+			kube.AccountFilter{Accounts: accounts},
+			kube.KindFilter{Kinds: []string{"Tenant", "Account"}},
+		}, true)
+		if err != nil {
+			return err
+		}
+
+		logger.Info("Applying tenant accounts", "tenant", t.Name, "accounts", len(accounts))
+		if err := kube.ApplyYaml(string(yaml)); err != nil {
+			return err
+		}
+	}
+
+	// Remove the accounts which no longer exist in the spec
+	for username := range applied {
+		logger.Info("Removing tenant account", "tenant", t.Name, "account", username)
+		if err := kube.DeleteAccount(t.Name, username); err != nil {
+			return err
+		}
+		if err := store.DeleteSecret(t.accountSecretKey(username)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t Tenant) accountSecretKey(username string) string {
+	return fmt.Sprintf("tenant/%s/accounts/%s", t.Name, username)
+}
+
+// validateAccounts ensures that each account is unique, and either has a password or only uses single sign-on
+func validateAccounts(accounts []managementv1.TenantAccountSpec) error {
+	usernames := make(map[string]bool, len(accounts))
+	for _, account := range accounts {
+		if account.Username == "admin" {
+			return errors.New("The admin account is defined by the admin password of the tenant")
+		}
+		if usernames[account.Username] {
+			return errors.Errorf("Account %s is defined more than once", account.Username)
+		}
+		usernames[account.Username] = true
+
+		if account.SSOOnly == (account.PasswordSecretRef != nil) {
+			return errors.Errorf("Account %s must either reference a password secret or be SSO-only", account.Username)
+		}
+	}
+	return nil
+}
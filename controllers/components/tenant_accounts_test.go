@@ -0,0 +1,32 @@
+package components
+
+import (
+	"github.com/metaprov/modela-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("Tenant accounts", func() {
+	password := &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "jane"}, Key: "password"}
+
+	It("Should accept accounts with a password or SSO only", func() {
+		Expect(validateAccounts([]v1alpha1.TenantAccountSpec{
+			{Username: "jane", PasswordSecretRef: password},
+			{Username: "john", SSOOnly: true},
+		})).To(Succeed())
+	})
+
+	It("Should reject accounts with both or neither a password and SSO", func() {
+		Expect(validateAccounts([]v1alpha1.TenantAccountSpec{{Username: "jane"}})).NotTo(Succeed())
+		Expect(validateAccounts([]v1alpha1.TenantAccountSpec{{Username: "jane", SSOOnly: true, PasswordSecretRef: password}})).NotTo(Succeed())
+	})
+
+	It("Should reject duplicate accounts and the admin account", func() {
+		Expect(validateAccounts([]v1alpha1.TenantAccountSpec{
+			{Username: "john", SSOOnly: true},
+			{Username: "john", SSOOnly: true},
+		})).NotTo(Succeed())
+		Expect(validateAccounts([]v1alpha1.TenantAccountSpec{{Username: "admin", SSOOnly: true}})).NotTo(Succeed())
+	})
+})
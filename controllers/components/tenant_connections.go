@@ -0,0 +1,283 @@
+package components
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	"github.com/metaprov/modela-operator/pkg/secrets"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strconv"
+	"strings"
+
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+)
+
+// connectionSource is a system component used by the connections of tenants. The admin credentials of the component
+// are read from its Secret and used to provision the scoped database, user or bucket of each tenant, and only the
+// scoped credentials are written to the connections of the tenant.
+type connectionSource struct {
+	// Secret is the name of the Secret of the component in the modela-system namespace
+	Secret string
+	// Connections contains the names of the tenant connections which use the component
+	Connections []string
+	// Provision creates the scoped resources of a tenant, or resets their credentials
+	Provision func(ctx context.Context, t Tenant, admin map[string]string, credentials map[string]string) error
+	// Drop removes the scoped resources of a tenant
+	Drop func(ctx context.Context, t Tenant, admin map[string]string) error
+	// Credentials returns the values of the connection secrets of a tenant
+	Credentials func(t Tenant, admin map[string]string, credentials map[string]string) map[string]interface{}
+}
+
+// ConnectionSecrets returns the names of the Secrets of the system components which are used by the connections
+// of tenants
+func ConnectionSecrets(modela *managementv1.Modela) []string {
+	var names []string
+	for _, source := range connectionSources(modela) {
+		names = append(names, source.Secret)
+	}
+	return names
+}
+
+// connectionSources returns the components used by tenant connections, which are either installed by the Modela
+// resource or external
+func connectionSources(modela *managementv1.Modela) []connectionSource {
+	var sources []connectionSource
+	if modela.Spec.ObjectStore.Install || modela.Spec.ObjectStore.External != nil {
+		source := connectionSource{
+			Secret:      "modela-storage-minio",
+			Connections: []string{"minio-connection"},
+			Provision: func(ctx context.Context, t Tenant, admin map[string]string, credentials map[string]string) error {
+				return t.provisionMinio(ctx, modela, admin, credentials[TenantMinioSecretKey])
+			},
+			Drop: func(ctx context.Context, t Tenant, admin map[string]string) error {
+				return t.dropMinio(ctx, modela, admin)
+			},
+			Credentials: func(t Tenant, _ map[string]string, credentials map[string]string) map[string]interface{} {
+				return map[string]interface{}{
+					"accessKey": t.Name,
+					"secretKey": credentials[TenantMinioSecretKey],
+					"bucket":    t.bucket(modela),
+					"host":      "modela-storage-minio.modela-system.svc.cluster.local:9000",
+				}
+			},
+		}
+		if service, ok := externalService(modela, "object storage"); ok {
+			source.Secret = service.Spec.CredentialsSecretRef.Name
+			source.Credentials = func(t Tenant, admin map[string]string, credentials map[string]string) map[string]interface{} {
+				values := map[string]interface{}{
+					"accessKey": admin["accessKey"],
+					"secretKey": admin["secretKey"],
+					"bucket":    t.bucket(modela),
+					"host":      service.Address(),
+					"region":    modela.Spec.ObjectStore.External.Region,
+				}
+				if scopedObjectStorage(modela) {
+					values["accessKey"], values["secretKey"] = t.Name, credentials[TenantMinioSecretKey]
+				}
+				return values
+			}
+		}
+		sources = append(sources, source)
+	}
+
+	postgres := connectionSource{
+		Secret:      "modela-postgresql",
+		Connections: []string{"postgres-connection", "postgres-vector-connection"},
+		Provision: func(ctx context.Context, t Tenant, admin map[string]string, credentials map[string]string) error {
+			return t.provisionPostgres(ctx, modela, admin, credentials[TenantPostgresPasswordKey])
+		},
+		Drop: func(ctx context.Context, t Tenant, admin map[string]string) error {
+			return t.dropPostgres(ctx, modela, admin)
+		},
+	}
+	host, port := NewPostgresDatabase().Host(modela), "5432"
+	if service, ok := externalService(modela, "postgres"); ok {
+		postgres.Secret = service.Spec.CredentialsSecretRef.Name
+		host, port = service.Spec.Host, strconv.Itoa(int(service.Port()))
+	}
+	postgres.Credentials = func(t Tenant, _ map[string]string, credentials map[string]string) map[string]interface{} {
+		return map[string]interface{}{
+			"username": t.postgresIdentifier(),
+			"password": credentials[TenantPostgresPasswordKey],
+			"database": t.postgresIdentifier(),
+			"host":     host,
+			"port":     port,
+		}
+	}
+	sources = append(sources, postgres)
+
+	if modela.Spec.Database.InstallMongoDB {
+		mongo := connectionSource{
+			Secret:      "modela-mongodb",
+			Connections: []string{"mongodb-connection"},
+			Provision: func(ctx context.Context, t Tenant, admin map[string]string, credentials map[string]string) error {
+				return t.provisionMongo(ctx, modela, admin, credentials[TenantMongoPasswordKey])
+			},
+			Drop: func(ctx context.Context, t Tenant, admin map[string]string) error {
+				return t.dropMongo(ctx, modela, admin)
+			},
+		}
+		// The members of a replica set are listed in the host, and the port applies to the last member
+		database := NewMongoDatabase()
+		host, port := strings.Join(database.Hosts(modela), ","), "27017"
+		if service, ok := externalService(modela, "mongodb"); ok {
+			mongo.Secret = service.Spec.CredentialsSecretRef.Name
+			host, port = service.Spec.Host, strconv.Itoa(int(service.Port()))
+		}
+		mongo.Credentials = func(t Tenant, _ map[string]string, credentials map[string]string) map[string]interface{} {
+			values := map[string]interface{}{
+				"username": t.Name,
+				"password": credentials[TenantMongoPasswordKey],
+				"database": t.Name,
+				"host":     host,
+				"port":     port,
+			}
+			if database.IsEnabled(*modela) && modela.Spec.Database.HA.Enabled {
+				values["replicaSet"] = database.ReplicaSetName(modela)
+				values["uri"] = database.URI(modela, t.Name, t.Name, credentials[TenantMongoPasswordKey])
+			}
+			return values
+		}
+		sources = append(sources, mongo)
+	}
+
+	if modela.Spec.OnlineStore.Install {
+		// The online store is shared by the tenants, which are isolated by the platform through key prefixes
+		redis := connectionSource{
+			Secret:      "redis",
+			Connections: []string{"redis-connection"},
+			Credentials: func(_ Tenant, admin map[string]string, _ map[string]string) map[string]interface{} {
+				return map[string]interface{}{
+					"password": admin["redis-password"],
+					"host":     "redis-master.modela-system.svc.cluster.local",
+					"port":     "6379",
+				}
+			},
+		}
+		if external := modela.Spec.OnlineStore.External; external != nil {
+			redis.Secret = external.CredentialsSecretRef.Name
+			redis.Credentials = func(_ Tenant, admin map[string]string, _ map[string]string) map[string]interface{} {
+				host, port, _ := external.HostPort()
+				return map[string]interface{}{
+					"username": admin["username"],
+					"password": admin["password"],
+					"host":     host,
+					"port":     port,
+					"tls":      strconv.FormatBool(external.TLS.Enabled),
+				}
+			}
+		}
+		sources = append(sources, redis)
+	}
+
+	return sources
+}
+
+// connectionFilter returns the filter which renders the connections and virtual buckets of tenants for the
+// components in use, including the provider of an external object storage and the TLS settings of external services
+func connectionFilter(modela *managementv1.Modela) kube.ConnectionFilter {
+	filter := kube.ConnectionFilter{
+		PgvectorEnabled: modela.Spec.Database.InstallPgvector,
+		MongoEnabled:    modela.Spec.Database.InstallMongoDB,
+	}
+	if mongo := NewMongoDatabase(); mongo.IsEnabled(*modela) && modela.Spec.Database.HA.Enabled {
+		filter.MongoReplicaSet = mongo.ReplicaSetName(modela)
+	}
+	if external := modela.Spec.ObjectStore.External; external != nil {
+		filter.ObjectStorageProvider = string(external.Provider)
+		filter.ObjectStorageRegion = external.Region
+	}
+
+	connections := map[string][]string{
+		"postgres":       {"postgres-connection", "postgres-vector-connection"},
+		"mongodb":        {"mongodb-connection"},
+		"object storage": {"minio-connection"},
+	}
+	for _, service := range ExternalServices(modela) {
+		if service.Spec.TLS.Enabled {
+			filter.SSL = append(filter.SSL, connections[service.Name]...)
+		}
+	}
+	return filter
+}
+
+// ApplyConnections copies the credentials of the system components into the connection secrets of the tenant
+func (t Tenant) ApplyConnections(ctx context.Context, modela *managementv1.Modela) error {
+	_, err := t.SyncConnections(ctx, modela, nil)
+	return err
+}
+
+// SyncConnections provisions the scoped databases, users and bucket of the tenant and writes their credentials to
+// the connection secrets of the tenant when any component Secret, or the scoped credentials of the tenant, differ
+// from the applied version. The connections whose component Secret does not exist yet are reported as missing.
+func (t Tenant) SyncConnections(ctx context.Context, modela *managementv1.Modela,
+	applied *managementv1.TenantConnectionStatus) (*managementv1.TenantConnectionStatus, error) {
+	type componentSecret struct {
+		source connectionSource
+		values map[string]string
+	}
+
+	credentials, credentialsVersion, err := t.credentials(modela)
+	if err != nil {
+		return applied, err
+	}
+
+	var found []componentSecret
+	var missing []string
+	versions := []string{TenantCredentialsSecretName(t.Name) + "/" + credentialsVersion}
+	for _, source := range connectionSources(modela) {
+		secret, err := kube.GetSecret("modela-system", source.Secret)
+		if k8serr.IsNotFound(err) {
+			missing = append(missing, source.Connections...)
+			versions = append(versions, source.Secret+"/-")
+			continue
+		} else if err != nil {
+			return applied, err
+		}
+
+		values := make(map[string]string, len(secret.Data))
+		for k, v := range secret.Data {
+			values[k] = string(v)
+		}
+		found = append(found, componentSecret{source: source, values: values})
+		versions = append(versions, fmt.Sprintf("%s/%s", source.Secret, secret.ResourceVersion))
+	}
+
+	checksum := sha256.Sum256([]byte(strings.Join(versions, ",")))
+	version := hex.EncodeToString(checksum[:8])
+	if applied != nil && applied.Version == version {
+		return applied, nil
+	}
+
+	store, err := secrets.GetSecretStore(modela)
+	if err != nil {
+		return applied, err
+	}
+
+	for _, secret := range found {
+		if secret.source.Provision != nil {
+			log.FromContext(ctx).Info("Provisioning tenant connection", "tenant", t.Name, "secret", secret.source.Secret)
+			if err := secret.source.Provision(ctx, t, secret.values, credentials); err != nil {
+				return applied, err
+			}
+		}
+
+		log.FromContext(ctx).Info("Applying connection secret", "tenant", t.Name, "secret", secret.source.Secret)
+		for _, connection := range secret.source.Connections {
+			if err := store.ApplySecret(t.connectionSecretKey(connection), secret.source.Credentials(t, secret.values, credentials)); err != nil {
+				return applied, err
+			}
+		}
+	}
+
+	now := metav1.Now()
+	return &managementv1.TenantConnectionStatus{Version: version, Missing: missing, LastSyncTime: &now}, nil
+}
+
+func (t Tenant) connectionSecretKey(connection string) string {
+	return fmt.Sprintf("tenant/%s/connections/%s", t.Name, connection)
+}
@@ -0,0 +1,569 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"github.com/Masterminds/goutils"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	"github.com/metaprov/modelaapi/pkg/util"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"path"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+)
+
+// The keys of the scoped credentials of a tenant inside its credentials Secret
+const (
+	TenantPostgresPasswordKey = "postgres-password"
+	TenantMongoPasswordKey    = "mongodb-password"
+	TenantMinioSecretKey      = "minio-secret-key"
+)
+
+// The images of the jobs which provision tenants inside external services
+const (
+	postgresClientImage = "docker.io/bitnami/postgresql:15.4.0"
+	mongoClientImage    = "docker.io/bitnami/mongodb:6.0.10"
+	minioClientImage    = "docker.io/bitnami/minio-client:2023.5.4"
+)
+
+const (
+	// postgresProvisionScript creates the role and database of a tenant, or resets the password of the role. The
+	// database is only accessible to the role of the tenant. The connection of psql is configured through the PG*
+	// environment variables.
+	postgresProvisionScript = `psql -v ON_ERROR_STOP=1 <<EOF
+DO \$\$ BEGIN IF NOT EXISTS (SELECT FROM pg_roles WHERE rolname = '$TENANT') THEN CREATE ROLE "$TENANT" LOGIN; END IF; END \$\$;
+ALTER ROLE "$TENANT" WITH LOGIN PASSWORD '$TENANT_PASSWORD';
+GRANT "$TENANT" TO CURRENT_USER;
+SELECT 'CREATE DATABASE "$TENANT" OWNER "$TENANT"' WHERE NOT EXISTS (SELECT FROM pg_database WHERE datname = '$TENANT')\gexec
+REVOKE ALL ON DATABASE "$TENANT" FROM PUBLIC;
+EOF`
+	postgresVectorScript = `psql -v ON_ERROR_STOP=1 -d "$TENANT" -c "CREATE EXTENSION IF NOT EXISTS vector"`
+	postgresDropScript   = `psql -v ON_ERROR_STOP=1 <<EOF
+DROP DATABASE IF EXISTS "$TENANT" WITH (FORCE);
+DROP ROLE IF EXISTS "$TENANT";
+EOF`
+
+	mongoShell = `mongosh admin --quiet --host "${MONGO_HOST:-localhost}" --port "${MONGO_PORT:-27017}" $MONGO_TLS_OPTIONS \
+-u "$MONGO_USER" -p "$MONGO_PASSWORD" --authenticationDatabase admin`
+	// mongoProvisionScript creates the database user of a tenant, or resets its password
+	mongoProvisionScript = mongoShell + ` --eval "
+const tenant = db.getSiblingDB('$TENANT');
+if (tenant.getUser('$TENANT')) { tenant.changeUserPassword('$TENANT', '$TENANT_PASSWORD') }
+else { tenant.createUser({user: '$TENANT', pwd: '$TENANT_PASSWORD', roles: [{role: 'dbOwner', db: '$TENANT'}]}) }"`
+	mongoDropScript = mongoShell + ` --eval "
+const tenant = db.getSiblingDB('$TENANT');
+if (tenant.getUser('$TENANT')) { tenant.dropUser('$TENANT') }
+tenant.dropDatabase()"`
+
+	minioAlias = `export MC_CONFIG_DIR=/tmp/.mc
+if [ -f "$MINIO_CA" ]; then mkdir -p $MC_CONFIG_DIR/certs/CAs && cp "$MINIO_CA" $MC_CONFIG_DIR/certs/CAs/; fi
+mc alias set modela "$MINIO_URL" "$MINIO_ACCESS_KEY" "$MINIO_SECRET_KEY" >/dev/null || exit 1
+`
+	// minioBucketScript creates the bucket of a tenant
+	minioBucketScript = minioAlias + `mc mb --ignore-existing ${MINIO_REGION:+--region "$MINIO_REGION"} "modela/$BUCKET"`
+	// minioProvisionScript creates the bucket of a tenant and an access key which may only access the bucket
+	minioProvisionScript = minioBucketScript + ` || exit 1
+cat > "/tmp/$TENANT-policy.json" <<EOF
+{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": ["s3:*"],
+ "Resource": ["arn:aws:s3:::$BUCKET", "arn:aws:s3:::$BUCKET/*"]}]}
+EOF
+mc admin policy create modela "tenant-$TENANT" "/tmp/$TENANT-policy.json" || exit 1
+mc admin user add modela "$TENANT" "$TENANT_PASSWORD" || exit 1
+mc admin policy attach modela "tenant-$TENANT" --user "$TENANT" 2>/dev/null || mc admin user info modela "$TENANT" | grep -q "tenant-$TENANT"`
+	minioBucketDropScript = minioAlias + `mc rb --force "modela/$BUCKET" 2>/dev/null
+exit 0`
+	minioDropScript = minioAlias + `mc admin user remove modela "$TENANT" 2>/dev/null
+mc admin policy remove modela "tenant-$TENANT" 2>/dev/null
+mc rb --force "modela/$BUCKET" 2>/dev/null
+exit 0`
+)
+
+// TenantCredentialsSecretName returns the name of the Secret which holds the scoped credentials of a tenant
+func TenantCredentialsSecretName(tenant string) string {
+	return tenant + "-credentials"
+}
+
+// postgresIdentifier returns the name of the Postgres database and role of the tenant
+func (t Tenant) postgresIdentifier() string {
+	return "tenant_" + strings.ReplaceAll(t.Name, "-", "_")
+}
+
+// bucket returns the name of the bucket of the tenant
+func (t Tenant) bucket(modela *managementv1.Modela) string {
+	if external := modela.Spec.ObjectStore.External; external != nil {
+		return external.BucketPrefix + t.Name
+	}
+	return t.Name
+}
+
+// credentials returns the scoped credentials of the tenant, generating the passwords which do not exist yet. The
+// resource version of the Secret changes with the credentials.
+func (t Tenant) credentials(modela *managementv1.Modela) (map[string]string, string, error) {
+	name := TenantCredentialsSecretName(t.Name)
+	secret, err := kube.GetSecret(modela.Namespace, name)
+	if err != nil && !k8serr.IsNotFound(err) {
+		return nil, "", err
+	} else if err != nil {
+		secret = &v1.Secret{}
+	}
+
+	values := make(map[string]string)
+	for _, key := range []string{TenantPostgresPasswordKey, TenantMongoPasswordKey, TenantMinioSecretKey} {
+		if len(secret.Data[key]) == 0 {
+			password, err := goutils.RandomAlphaNumeric(32)
+			if err != nil {
+				return nil, "", err
+			}
+			values[key] = password
+		}
+	}
+
+	if len(values) > 0 {
+		if err := kube.CreateOrUpdateLabeledSecret(modela.Namespace, name, map[string]string{
+			"management.modela.ai/operator": modela.Name,
+			"management.modela.ai/tenant":   t.Name,
+		}, values); err != nil {
+			return nil, "", err
+		}
+		if secret, err = kube.GetSecret(modela.Namespace, name); err != nil {
+			return nil, "", err
+		}
+	}
+
+	values = make(map[string]string, len(secret.Data))
+	for k, v := range secret.Data {
+		values[k] = string(v)
+	}
+	return values, secret.ResourceVersion, nil
+}
+
+// clientScript is a script which runs against a system component, with the environment of the client of the
+// component. The script runs inside the pod of a bundled component, or in a job when the component is external.
+type clientScript struct {
+	// Name is the name of the job which runs the script against an external component
+	Name   string
+	Script string
+	Env    map[string]string
+	// Namespace, Pod and Container select the pod of the bundled component
+	Namespace string
+	Pod       string
+	Container string
+	// Image is the image of the job which runs the script against an external component
+	Image string
+	// CA references the CA bundle of the external component, which is mounted at externalCAPath
+	CA *v1.SecretKeySelector
+}
+
+// clientJobScript runs the script given as its first argument inside the job of a client script. The output of a
+// successful script is written to the termination message of the container, so that it can be read without access
+// to the logs of the pod. The logs are used as the termination message when the script fails.
+const clientJobScript = `output=$(sh -c "$1")
+status=$?
+printf '%s\n' "$output"
+if [ $status -eq 0 ]; then printf '%s' "$output" | head -c 4096 > /dev/termination-log; fi
+exit $status`
+
+// clientExecScript runs the script given as its first argument inside the pod of a bundled component, after
+// exporting the environment read from its standard input
+const clientExecScript = `eval "$(cat)"
+eval "$1"`
+
+// clientJobTimeout is the time after which Kubernetes fails a client job which has not finished, including the
+// time spent waiting for its pod to be scheduled
+const clientJobTimeout = 10 * time.Minute
+
+// startingReasons are the reasons for which the container of a client job waits while it starts normally. The
+// other reasons, such as ImagePullBackOff, are reported as errors until the job has started or failed.
+var startingReasons = map[string]bool{"ContainerCreating": true, "PodInitializing": true}
+
+// ClientJobRunningError is returned by a client script which runs in a job until the job has finished, so that the
+// reconciliation is requeued rather than blocked while the job runs
+type ClientJobRunningError struct {
+	Job string
+}
+
+func (e *ClientJobRunningError) Error() string {
+	return fmt.Sprintf("Waiting for job %s to finish", e.Job)
+}
+
+// IsClientJobRunning returns true if an error was returned by a client script whose job has not finished
+func IsClientJobRunning(err error) bool {
+	var running *ClientJobRunningError
+	return errors.As(err, &running)
+}
+
+func (c clientScript) run(ctx context.Context) error {
+	_, err := c.output(ctx)
+	return err
+}
+
+// output runs the script and returns its standard output. Inside the pod of a bundled component, the environment
+// contains credentials, so it is written to the standard input of the shell rather than given as arguments, which
+// are recorded by the audit log of the API server. A script which runs in a job returns a ClientJobRunningError
+// until the job has finished, or an error while the container of the job cannot start, and its output is limited
+// to 4096 bytes.
+func (c clientScript) output(ctx context.Context) (string, error) {
+	if c.Image == "" {
+		return kube.ExecCommandWithInput(c.Namespace, c.Pod, c.Container,
+			[]string{"sh", "-c", clientExecScript, "client", c.Script}, shellExports(c.Env))
+	}
+
+	job, err := kube.GetJob(c.Namespace, c.Name)
+	if err != nil {
+		return "", err
+	} else if job == nil {
+		return "", c.startJob(ctx)
+	}
+
+	status, err := c.containerStatus()
+	if err != nil {
+		return "", err
+	}
+
+	// A finished job is deleted in the background before the script can run again
+	finished, condition := kube.JobFinished(job)
+	if job.DeletionTimestamp != nil {
+		return "", &ClientJobRunningError{Job: c.Name}
+	} else if !finished {
+		if status != nil && status.State.Waiting != nil && !startingReasons[status.State.Waiting.Reason] {
+			return "", errors.Errorf("Job %s is waiting to start: %s", c.Name, status.State.Waiting.Reason)
+		}
+		return "", &ClientJobRunningError{Job: c.Name}
+	}
+
+	var message string
+	if status != nil && status.State.Terminated != nil {
+		message = strings.TrimSpace(status.State.Terminated.Message)
+	}
+	// A failed job is reported once with its termination message, and the script runs again on the next call
+	if err := kube.DeleteJob(c.Namespace, c.Name); err != nil {
+		return "", err
+	}
+	if err := kube.DeleteSecret(c.Namespace, c.Name); err != nil {
+		return "", err
+	}
+	if condition == batchv1.JobFailed {
+		failure := fmt.Sprintf("Job %s failed", c.Name)
+		if jobFailureReason(job) == "DeadlineExceeded" {
+			failure = fmt.Sprintf("Job %s did not finish within %s", c.Name, clientJobTimeout)
+		}
+		if message != "" {
+			failure = fmt.Sprintf("%s: %s", failure, message)
+		}
+		return "", errors.New(failure)
+	}
+	return message, nil
+}
+
+// startJob creates the job which runs the script against an external component, and returns a
+// ClientJobRunningError
+func (c clientScript) startJob(ctx context.Context) error {
+	// The environment contains credentials, so it is passed to the job through a Secret
+	labels := map[string]string{"app.kubernetes.io/managed-by": "modela-operator"}
+	if err := kube.CreateOrUpdateLabeledSecret(c.Namespace, c.Name, labels, c.Env); err != nil {
+		return err
+	}
+
+	container := v1.Container{
+		Name:    "client",
+		Image:   c.Image,
+		Command: []string{"sh", "-c", clientJobScript, "client", c.Script},
+		EnvFrom: []v1.EnvFromSource{{SecretRef: &v1.SecretEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: c.Name}}}},
+
+		TerminationMessagePolicy: v1.TerminationMessageFallbackToLogsOnError,
+	}
+	podSpec := v1.PodSpec{RestartPolicy: v1.RestartPolicyNever}
+	if c.CA != nil {
+		podSpec.Volumes = []v1.Volume{{Name: "ca", VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{
+			SecretName: c.CA.Name,
+			Items:      []v1.KeyToPath{{Key: c.CA.Key, Path: path.Base(externalCAPath)}},
+		}}}}
+		container.VolumeMounts = []v1.VolumeMount{{Name: "ca", MountPath: path.Dir(externalCAPath), ReadOnly: true}}
+	}
+	podSpec.Containers = []v1.Container{container}
+
+	if err := kube.CreateJob(&batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: c.Name, Namespace: c.Namespace, Labels: labels},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          util.Int32Ptr(0),
+			ActiveDeadlineSeconds: util.Int64Ptr(int64(clientJobTimeout.Seconds())),
+			Template:              v1.PodTemplateSpec{Spec: podSpec},
+		},
+	}); err != nil {
+		return err
+	}
+
+	log.FromContext(ctx).Info("Started client job", "job", c.Name)
+	return &ClientJobRunningError{Job: c.Name}
+}
+
+// containerStatus returns the status of the client container of the job, or nil if its pod has not been created
+func (c clientScript) containerStatus() (*v1.ContainerStatus, error) {
+	pods, err := kube.ListPods(c.Namespace, "job-name="+c.Name)
+	if err != nil || len(pods) == 0 {
+		return nil, err
+	}
+	for _, status := range pods[len(pods)-1].Status.ContainerStatuses {
+		if status.Name == "client" {
+			return &status, nil
+		}
+	}
+	return nil, nil
+}
+
+// shellExports returns the shell commands which export the environment, quoting each value
+func shellExports(env map[string]string) string {
+	var exports strings.Builder
+	for _, key := range sortedKeys(env) {
+		exports.WriteString("export " + key + "='" + strings.ReplaceAll(env[key], "'", `'\''`) + "'\n")
+	}
+	return exports.String()
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// postgresClient returns a script which runs against the Postgres server used by the tenants
+func (t Tenant) postgresClient(modela *managementv1.Modela, action string, script string, admin map[string]string) clientScript {
+	client := postgresClient(modela, fmt.Sprintf("%s-postgres-%s", t.Name, action), script, admin)
+	client.Env["TENANT"] = t.postgresIdentifier()
+	return client
+}
+
+// postgresClient returns a script which runs against the Postgres server used by the tenants as the admin user
+// of the server
+func postgresClient(modela *managementv1.Modela, name string, script string, admin map[string]string) clientScript {
+	postgres := NewPostgresDatabase()
+	client := clientScript{
+		Name:      name,
+		Script:    script,
+		Env:       map[string]string{},
+		Namespace: postgres.Namespace,
+		Pod:       postgres.Primary(modela) + "-0",
+		Container: "postgresql",
+	}
+
+	service, ok := externalService(modela, "postgres")
+	if !ok {
+		client.Env["PGUSER"], client.Env["PGPASSWORD"] = "postgres", admin["postgres-password"]
+		return client
+	}
+
+	client.Image = postgresClientImage
+	client.Env["PGHOST"], client.Env["PGPORT"] = service.Spec.Host, strconv.Itoa(int(service.Port()))
+	client.Env["PGUSER"], client.Env["PGPASSWORD"] = admin["username"], admin["password"]
+	client.Env["PGDATABASE"], client.Env["PGSSLMODE"] = "postgres", "prefer"
+	if service.Spec.TLS.Enabled {
+		client.Env["PGSSLMODE"] = "require"
+		if client.CA = service.Spec.TLS.CASecretRef; client.CA != nil {
+			client.Env["PGSSLMODE"], client.Env["PGSSLROOTCERT"] = "verify-full", externalCAPath
+		}
+	}
+	return client
+}
+
+// mongoClient returns a script which runs against the MongoDB server used by the tenants
+func (t Tenant) mongoClient(modela *managementv1.Modela, action string, script string, admin map[string]string) clientScript {
+	client := mongoClient(modela, fmt.Sprintf("%s-mongodb-%s", t.Name, action), script, admin)
+	client.Env["TENANT"] = t.Name
+	return client
+}
+
+// mongoClient returns a script which runs against the MongoDB server used by the tenants as the admin user of the
+// server. Inside a replica set, the shell connects to the primary through the seed list of the replica set.
+func mongoClient(modela *managementv1.Modela, name string, script string, admin map[string]string) clientScript {
+	mongo := NewMongoDatabase()
+	client := clientScript{
+		Name:      name,
+		Script:    script,
+		Env:       map[string]string{},
+		Namespace: mongo.Namespace,
+		Pod:       mongo.ReleaseName + "-0",
+		Container: "mongodb",
+	}
+
+	service, ok := externalService(modela, "mongodb")
+	if !ok {
+		client.Env["MONGO_USER"], client.Env["MONGO_PASSWORD"] = "root", admin["mongodb-root-password"]
+		if modela.Spec.Database.HA.Enabled {
+			client.Env["MONGO_HOST"] = mongo.ShellHost(modela)
+		}
+		return client
+	}
+
+	client.Image = mongoClientImage
+	client.Env["MONGO_HOST"], client.Env["MONGO_PORT"] = service.Spec.Host, strconv.Itoa(int(service.Port()))
+	client.Env["MONGO_USER"], client.Env["MONGO_PASSWORD"] = admin["username"], admin["password"]
+	if service.Spec.TLS.Enabled {
+		client.Env["MONGO_TLS_OPTIONS"] = "--tls"
+		if client.CA = service.Spec.TLS.CASecretRef; client.CA != nil {
+			client.Env["MONGO_TLS_OPTIONS"] = "--tls --tlsCAFile " + externalCAPath
+		}
+	}
+	return client
+}
+
+// minioClient returns a script which runs against the object storage used by the tenants
+func (t Tenant) minioClient(modela *managementv1.Modela, action string, script string, admin map[string]string) (clientScript, error) {
+	client := clientScript{
+		Name:      fmt.Sprintf("%s-storage-%s", t.Name, action),
+		Script:    script,
+		Env:       map[string]string{"TENANT": t.Name, "BUCKET": t.bucket(modela)},
+		Namespace: NewObjectStorage().Namespace,
+		Container: "minio",
+	}
+
+	service, ok := externalService(modela, "object storage")
+	if !ok {
+		pod, err := minioPod()
+		if err != nil {
+			return client, err
+		}
+		client.Pod = pod
+		client.Env["MINIO_URL"] = "http://localhost:9000"
+		client.Env["MINIO_ACCESS_KEY"], client.Env["MINIO_SECRET_KEY"] = admin["root-user"], admin["root-password"]
+		return client, nil
+	}
+
+	client.Image = minioClientImage
+	client.Env["MINIO_URL"] = objectStorageURL(service)
+	if client.CA = service.Spec.TLS.CASecretRef; client.CA != nil {
+		client.Env["MINIO_CA"] = externalCAPath
+	}
+	client.Env["MINIO_ACCESS_KEY"], client.Env["MINIO_SECRET_KEY"] = admin["accessKey"], admin["secretKey"]
+	client.Env["MINIO_REGION"] = modela.Spec.ObjectStore.External.Region
+	return client, nil
+}
+
+// provisionPostgres creates the database and role of the tenant, and enables pgvector inside the database when
+// pgvector is installed
+func (t Tenant) provisionPostgres(ctx context.Context, modela *managementv1.Modela, admin map[string]string, password string) error {
+	client := t.postgresClient(modela, "provision", postgresProvisionScript, admin)
+	client.Env["TENANT_PASSWORD"] = password
+	if err := client.run(ctx); err != nil {
+		return errors.Wrapf(err, "Failed to provision the Postgres database of tenant %s", t.Name)
+	}
+
+	if modela.Spec.Database.InstallPgvector {
+		if err := t.postgresClient(modela, "pgvector", postgresVectorScript, admin).run(ctx); err != nil {
+			return errors.Wrapf(err, "Failed to enable pgvector for tenant %s", t.Name)
+		}
+	}
+	return nil
+}
+
+func (t Tenant) dropPostgres(ctx context.Context, modela *managementv1.Modela, admin map[string]string) error {
+	if err := t.postgresClient(modela, "drop", postgresDropScript, admin).run(ctx); err != nil {
+		return errors.Wrapf(err, "Failed to drop the Postgres database of tenant %s", t.Name)
+	}
+	return nil
+}
+
+// provisionMongo creates the database user of the tenant, which owns the database named after the tenant
+func (t Tenant) provisionMongo(ctx context.Context, modela *managementv1.Modela, admin map[string]string, password string) error {
+	client := t.mongoClient(modela, "provision", mongoProvisionScript, admin)
+	client.Env["TENANT_PASSWORD"] = password
+	if err := client.run(ctx); err != nil {
+		return errors.Wrapf(err, "Failed to provision the MongoDB database of tenant %s", t.Name)
+	}
+	return nil
+}
+
+func (t Tenant) dropMongo(ctx context.Context, modela *managementv1.Modela, admin map[string]string) error {
+	if err := t.mongoClient(modela, "drop", mongoDropScript, admin).run(ctx); err != nil {
+		return errors.Wrapf(err, "Failed to drop the MongoDB database of tenant %s", t.Name)
+	}
+	return nil
+}
+
+// provisionMinio creates the bucket of the tenant. Inside MinIO, an access key named after the tenant is created
+// whose policy only grants access to the bucket.
+func (t Tenant) provisionMinio(ctx context.Context, modela *managementv1.Modela, admin map[string]string, secretKey string) error {
+	script := minioProvisionScript
+	if !scopedObjectStorage(modela) {
+		script = minioBucketScript
+	}
+	client, err := t.minioClient(modela, "provision", script, admin)
+	if err != nil {
+		return err
+	}
+	client.Env["TENANT_PASSWORD"] = secretKey
+	if err := client.run(ctx); err != nil {
+		return errors.Wrapf(err, "Failed to provision the bucket of tenant %s", t.Name)
+	}
+	return nil
+}
+
+func (t Tenant) dropMinio(ctx context.Context, modela *managementv1.Modela, admin map[string]string) error {
+	script := minioDropScript
+	if !scopedObjectStorage(modela) {
+		script = minioBucketDropScript
+	}
+	client, err := t.minioClient(modela, "drop", script, admin)
+	if err != nil {
+		return err
+	}
+	if err := client.run(ctx); err != nil {
+		return errors.Wrapf(err, "Failed to drop the bucket of tenant %s", t.Name)
+	}
+	return nil
+}
+
+// scopedObjectStorage returns true if the object storage supports an access key for each tenant, which is only the
+// case for MinIO
+func scopedObjectStorage(modela *managementv1.Modela) bool {
+	external := modela.Spec.ObjectStore.External
+	return external == nil || external.Provider == managementv1.ObjectStorageProviderMinio
+}
+
+// minioPod returns the name of a running pod of the MinIO release
+func minioPod() (string, error) {
+	objectStorage := NewObjectStorage()
+	pods, err := kube.ListPods(objectStorage.Namespace, "app.kubernetes.io/instance="+objectStorage.ReleaseName)
+	if err != nil {
+		return "", err
+	}
+	for _, pod := range pods {
+		if pod.Status.Phase == v1.PodRunning {
+			return pod.Name, nil
+		}
+	}
+	return "", errors.New("No MinIO pod is running")
+}
+
+// DropConnections drops the scoped databases, users and bucket of the tenant, and deletes its scoped credentials
+func (t Tenant) DropConnections(ctx context.Context, modela *managementv1.Modela) error {
+	for _, source := range connectionSources(modela) {
+		if source.Drop == nil {
+			continue
+		}
+		admin, err := kube.GetSecretValuesAsString("modela-system", source.Secret)
+		if k8serr.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		log.FromContext(ctx).Info("Dropping tenant connection", "tenant", t.Name, "secret", source.Secret)
+		if err := source.Drop(ctx, t, admin); err != nil {
+			return err
+		}
+	}
+
+	return kube.DeleteSecret(modela.Namespace, TenantCredentialsSecretName(t.Name))
+}
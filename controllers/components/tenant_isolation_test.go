@@ -0,0 +1,20 @@
+package components
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+var _ = Describe("Tenant isolation", func() {
+	It("Should quote the environment of a client script", func() {
+		Expect(shellExports(map[string]string{"PGUSER": "postgres", "PGPASSWORD": "it's secret"})).To(Equal(
+			"export PGPASSWORD='it'\\''s secret'\nexport PGUSER='postgres'\n"))
+	})
+
+	It("Should detect a running client job through wrapped errors", func() {
+		err := errors.Wrapf(&ClientJobRunningError{Job: "analytics-postgres-provision"}, "Failed to provision tenant")
+		Expect(IsClientJobRunning(err)).To(BeTrue())
+		Expect(IsClientJobRunning(errors.New("Job analytics-postgres-provision failed"))).To(BeFalse())
+	})
+})
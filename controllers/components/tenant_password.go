@@ -0,0 +1,117 @@
+package components
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	"github.com/metaprov/modela-operator/pkg/secrets"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+	v1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+)
+
+// AdminPasswordKey is the key of the generated admin password inside its Secret
+const AdminPasswordKey = "password"
+
+// AdminPasswordSecretName returns the name of the Secret which holds the generated admin password of a tenant
+func AdminPasswordSecretName(tenant string) string {
+	return tenant + "-admin-password"
+}
+
+// ApplyAdminPassword hashes the admin password of the tenant and writes it to the secret store when the password
+// differs from the applied version. A random password is generated when the tenant does not define one.
+func (t Tenant) ApplyAdminPassword(ctx context.Context, modela *managementv1.Modela, config managementv1.TenantConfig,
+	applied *managementv1.TenantAdminPasswordStatus) (*managementv1.TenantAdminPasswordStatus, error) {
+	password, version, err := t.adminPassword(modela, config)
+	if err != nil {
+		return applied, err
+	}
+
+	cost := passwordHashCost(config)
+	version = fmt.Sprintf("%s@%d", version, cost)
+	if applied != nil && applied.Version == version {
+		return applied, nil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if err != nil {
+		return applied, errors.Wrapf(err, "Failed to hash the admin password of tenant %s", t.Name)
+	}
+
+	store, err := secrets.GetSecretStore(modela)
+	if err != nil {
+		return applied, err
+	}
+
+	if err := store.ApplySecret(fmt.Sprintf("tenant/%s/accounts/admin", t.Name), map[string]interface{}{
+		"password": string(hash),
+	}); err != nil {
+		return applied, err
+	}
+
+	log.FromContext(ctx).Info("Applied admin password", "tenant", t.Name)
+	return &managementv1.TenantAdminPasswordStatus{Version: version, LastUpdateTime: metav1.Now()}, nil
+}
+
+// adminPassword returns the admin password of the tenant and a version which changes with the password
+func (t Tenant) adminPassword(modela *managementv1.Modela, config managementv1.TenantConfig) (string, string, error) {
+	if ref := config.AdminPasswordSecretRef; ref != nil {
+		return secretPassword(modela.Namespace, ref)
+	}
+
+	if config.AdminPassword != nil {
+		checksum := sha256.Sum256([]byte(*config.AdminPassword))
+		return *config.AdminPassword, "spec/" + hex.EncodeToString(checksum[:8]), nil
+	}
+
+	name := AdminPasswordSecretName(t.Name)
+	secret, err := kube.GetSecret(modela.Namespace, name)
+	if err != nil && !k8serr.IsNotFound(err) {
+		return "", "", err
+	} else if err != nil || len(secret.Data[AdminPasswordKey]) == 0 {
+		key := make([]byte, 18)
+		if _, err := rand.Read(key); err != nil {
+			return "", "", err
+		}
+		if err := kube.CreateOrUpdateLabeledSecret(modela.Namespace, name, map[string]string{
+			"management.modela.ai/operator": modela.Name,
+			"management.modela.ai/tenant":   t.Name,
+		}, map[string]string{AdminPasswordKey: base64.RawURLEncoding.EncodeToString(key)}); err != nil {
+			return "", "", err
+		}
+		if secret, err = kube.GetSecret(modela.Namespace, name); err != nil {
+			return "", "", err
+		}
+	}
+
+	return string(secret.Data[AdminPasswordKey]), fmt.Sprintf("secret/%s/%s", name, secret.ResourceVersion), nil
+}
+
+// secretPassword returns the password stored in the key of a Secret, and a version which changes with the Secret
+func secretPassword(namespace string, ref *v1.SecretKeySelector) (string, string, error) {
+	secret, err := kube.GetSecret(namespace, ref.Name)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "Failed to get password secret %s", ref.Name)
+	}
+	password, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", "", errors.Errorf("Password secret %s has no key %s", ref.Name, ref.Key)
+	}
+	return string(password), fmt.Sprintf("secret/%s/%s", ref.Name, secret.ResourceVersion), nil
+}
+
+func passwordHashCost(config managementv1.TenantConfig) int {
+	if config.PasswordHashCost < bcrypt.MinCost || config.PasswordHashCost > bcrypt.MaxCost {
+		return bcrypt.DefaultCost
+	}
+	return config.PasswordHashCost
+}
@@ -0,0 +1,20 @@
+package components
+
+import (
+	"github.com/metaprov/modela-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var _ = Describe("Tenant admin password", func() {
+	It("Should fall back to the default cost when the cost is out of range", func() {
+		Expect(passwordHashCost(v1alpha1.TenantConfig{})).To(Equal(bcrypt.DefaultCost))
+		Expect(passwordHashCost(v1alpha1.TenantConfig{PasswordHashCost: 40})).To(Equal(bcrypt.DefaultCost))
+		Expect(passwordHashCost(v1alpha1.TenantConfig{PasswordHashCost: 12})).To(Equal(12))
+	})
+
+	It("Should name the generated password secret after the tenant", func() {
+		Expect(AdminPasswordSecretName("analytics")).To(Equal("analytics-admin-password"))
+	})
+})
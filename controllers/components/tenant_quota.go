@@ -0,0 +1,136 @@
+package components
+
+import (
+	"context"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+)
+
+const (
+	// TenantQuotaName is the name of the ResourceQuota maintained in the namespace of each tenant
+	TenantQuotaName = "modela-tenant-quota"
+	// TenantLimitRangeName is the name of the LimitRange maintained in the namespace of each tenant
+	TenantLimitRangeName = "modela-tenant-limits"
+
+	resourceRequestsGPU v1.ResourceName = "requests.nvidia.com/gpu"
+)
+
+var (
+	defaultContainerCPU           = resource.MustParse("1")
+	defaultContainerMemory        = resource.MustParse("1Gi")
+	defaultContainerRequestCPU    = resource.MustParse("100m")
+	defaultContainerRequestMemory = resource.MustParse("128Mi")
+)
+
+// ApplyQuota reconciles the ResourceQuota and LimitRange of the tenant with its quota profile. Both are removed
+// when the tenant has no quota profile.
+func (t Tenant) ApplyQuota(ctx context.Context, modela *managementv1.Modela, quota *managementv1.TenantQuotaSpec) error {
+	if quota == nil {
+		if err := kube.DeleteResourceQuota(t.Name, TenantQuotaName); err != nil {
+			return err
+		}
+		return kube.DeleteLimitRange(t.Name, TenantLimitRangeName)
+	}
+
+	if err := kube.ApplyResourceQuota(t.resourceQuota(modela, quota)); err != nil {
+		return err
+	}
+	return kube.ApplyLimitRange(t.limitRange(modela, quota))
+}
+
+// QuotaStatus returns the limits and usage of the ResourceQuota of the tenant, or nil if it has no quota
+func (t Tenant) QuotaStatus(ctx context.Context) (*managementv1.TenantQuotaStatus, error) {
+	quota, err := kube.GetResourceQuota(t.Name, TenantQuotaName)
+	if err != nil || quota == nil {
+		return nil, err
+	}
+
+	return &managementv1.TenantQuotaStatus{
+		Hard: quota.Status.Hard,
+		Used: quota.Status.Used,
+	}, nil
+}
+
+func (t Tenant) resourceQuota(modela *managementv1.Modela, quota *managementv1.TenantQuotaSpec) *v1.ResourceQuota {
+	hard := v1.ResourceList{}
+	if quota.CPU != nil {
+		hard[v1.ResourceRequestsCPU] = *quota.CPU
+		hard[v1.ResourceLimitsCPU] = *quota.CPU
+	}
+	if quota.Memory != nil {
+		hard[v1.ResourceRequestsMemory] = *quota.Memory
+		hard[v1.ResourceLimitsMemory] = *quota.Memory
+	}
+	if quota.GPU != nil {
+		hard[resourceRequestsGPU] = *quota.GPU
+	}
+	if quota.Storage != nil {
+		hard[v1.ResourceRequestsStorage] = *quota.Storage
+	}
+
+	for name, count := range map[v1.ResourceName]*int64{
+		v1.ResourcePersistentVolumeClaims: quota.PersistentVolumeClaims,
+		v1.ResourcePods:                   quota.Pods,
+		v1.ResourceServices:               quota.Services,
+		v1.ResourceConfigMaps:             quota.ConfigMaps,
+		v1.ResourceSecrets:                quota.Secrets,
+	} {
+		if count != nil {
+			hard[name] = *resource.NewQuantity(*count, resource.DecimalSI)
+		}
+	}
+
+	return &v1.ResourceQuota{
+		ObjectMeta: t.quotaObjectMeta(TenantQuotaName, modela),
+		Spec:       v1.ResourceQuotaSpec{Hard: hard},
+	}
+}
+
+func (t Tenant) limitRange(modela *managementv1.Modela, quota *managementv1.TenantQuotaSpec) *v1.LimitRange {
+	limits := quota.Limits
+	item := v1.LimitRangeItem{
+		Type: v1.LimitTypeContainer,
+		Default: v1.ResourceList{
+			v1.ResourceCPU:    quantityOrDefault(limits.DefaultCPU, defaultContainerCPU),
+			v1.ResourceMemory: quantityOrDefault(limits.DefaultMemory, defaultContainerMemory),
+		},
+		DefaultRequest: v1.ResourceList{
+			v1.ResourceCPU:    quantityOrDefault(limits.DefaultRequestCPU, defaultContainerRequestCPU),
+			v1.ResourceMemory: quantityOrDefault(limits.DefaultRequestMemory, defaultContainerRequestMemory),
+		},
+	}
+
+	if limits.MaxCPU != nil || limits.MaxMemory != nil {
+		item.Max = v1.ResourceList{}
+		if limits.MaxCPU != nil {
+			item.Max[v1.ResourceCPU] = *limits.MaxCPU
+		}
+		if limits.MaxMemory != nil {
+			item.Max[v1.ResourceMemory] = *limits.MaxMemory
+		}
+	}
+
+	return &v1.LimitRange{
+		ObjectMeta: t.quotaObjectMeta(TenantLimitRangeName, modela),
+		Spec:       v1.LimitRangeSpec{Limits: []v1.LimitRangeItem{item}},
+	}
+}
+
+func (t Tenant) quotaObjectMeta(name string, modela *managementv1.Modela) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: t.Name,
+		Labels:    map[string]string{"management.modela.ai/operator": modela.Name},
+	}
+}
+
+func quantityOrDefault(quantity *resource.Quantity, defaultQuantity resource.Quantity) resource.Quantity {
+	if quantity != nil {
+		return *quantity
+	}
+	return defaultQuantity
+}
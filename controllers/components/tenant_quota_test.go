@@ -0,0 +1,40 @@
+package components
+
+import (
+	"github.com/metaprov/modela-operator/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var _ = Describe("Tenant quota", func() {
+	modela := &v1alpha1.Modela{}
+	modela.Name = "modela"
+
+	It("Should limit the requests and limits of the tenant", func() {
+		cpu, gpu := resource.MustParse("8"), resource.MustParse("2")
+		pods := int64(20)
+		quota := NewTenant("analytics").resourceQuota(modela, &v1alpha1.TenantQuotaSpec{CPU: &cpu, GPU: &gpu, Pods: &pods})
+
+		Expect(quota.Namespace).To(Equal("analytics"))
+		Expect(quota.Spec.Hard).To(HaveLen(4))
+		Expect(quota.Spec.Hard[v1.ResourceRequestsCPU]).To(Equal(cpu))
+		Expect(quota.Spec.Hard[v1.ResourceLimitsCPU]).To(Equal(cpu))
+		Expect(quota.Spec.Hard[resourceRequestsGPU]).To(Equal(gpu))
+		Expect(quota.Spec.Hard.Pods().Value()).To(Equal(pods))
+	})
+
+	It("Should default the resources of containers", func() {
+		maxMemory := resource.MustParse("16Gi")
+		limitRange := NewTenant("analytics").limitRange(modela, &v1alpha1.TenantQuotaSpec{
+			Limits: v1alpha1.TenantLimitRangeSpec{MaxMemory: &maxMemory},
+		})
+
+		item := limitRange.Spec.Limits[0]
+		Expect(item.Default[v1.ResourceCPU]).To(Equal(defaultContainerCPU))
+		Expect(item.DefaultRequest[v1.ResourceMemory]).To(Equal(defaultContainerRequestMemory))
+		Expect(item.Max).To(HaveLen(1))
+		Expect(item.Max[v1.ResourceMemory]).To(Equal(maxMemory))
+	})
+})
@@ -0,0 +1,153 @@
+package components
+
+import (
+	"context"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	"github.com/metaprov/modela-operator/pkg/secrets"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"time"
+
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+)
+
+const (
+	// TenantRetainedLabel marks the namespaces of removed tenants which are retained by the operator
+	TenantRetainedLabel = "management.modela.ai/retained"
+	// TenantRetainedAtAnnotation records the time at which the namespace of a removed tenant was retained
+	TenantRetainedAtAnnotation = "management.modela.ai/retained-at"
+	// TenantDeletionPolicyAnnotation records the deletion policy of a tenant on its namespace, so that the policy
+	// is known once the tenant is removed from the spec
+	TenantDeletionPolicyAnnotation = "management.modela.ai/deletion-policy"
+
+	defaultTenantGracePeriod = 7 * 24 * time.Hour
+)
+
+// ApplyDeletionPolicy records the deletion policy of the tenant on its namespace
+func (t Tenant) ApplyDeletionPolicy(ctx context.Context, policy managementv1.TenantDeletionPolicy) error {
+	if policy == "" {
+		policy = managementv1.TenantDeletionPolicyRetain
+	}
+
+	namespace, err := kube.GetNamespace(t.Name)
+	if err != nil || namespace == nil {
+		return err
+	}
+	if namespace.Annotations[TenantDeletionPolicyAnnotation] == string(policy) {
+		return nil
+	}
+
+	if namespace.Annotations == nil {
+		namespace.Annotations = make(map[string]string)
+	}
+	namespace.Annotations[TenantDeletionPolicyAnnotation] = string(policy)
+	return kube.UpdateNamespace(namespace)
+}
+
+// Remove uninstalls a removed tenant when its deletion policy is Delete or its deletion has been confirmed, and
+// otherwise retains its namespace and secrets. It returns true if the tenant was retained.
+func (t Tenant) Remove(ctx context.Context, modela *managementv1.Modela, confirmed bool) (bool, error) {
+	namespace, err := kube.GetNamespace(t.Name)
+	if err != nil {
+		return false, err
+	} else if namespace == nil {
+		return false, nil
+	}
+
+	if confirmed || namespace.Annotations[TenantDeletionPolicyAnnotation] == string(managementv1.TenantDeletionPolicyDelete) {
+		if err := t.Uninstall(ctx, modela); err != nil && !errors.Is(err, managementv1.ComponentNotInstalledByModelaError) {
+			return false, err
+		}
+		return false, nil
+	}
+
+	if namespace.Labels[TenantRetainedLabel] == "true" {
+		return true, nil
+	}
+
+	log.FromContext(ctx).Info("Retaining the namespace of removed tenant", "tenant", t.Name)
+	if namespace.Labels == nil {
+		namespace.Labels = make(map[string]string)
+	}
+	if namespace.Annotations == nil {
+		namespace.Annotations = make(map[string]string)
+	}
+	namespace.Labels[TenantRetainedLabel] = "true"
+	namespace.Annotations[TenantRetainedAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	return true, kube.UpdateNamespace(namespace)
+}
+
+// adopt re-adopts the namespace of a retained tenant. It returns true if the namespace was retained.
+func (t Tenant) adopt(ctx context.Context) (bool, error) {
+	namespace, err := kube.GetNamespace(t.Name)
+	if err != nil || namespace == nil || namespace.Labels[TenantRetainedLabel] != "true" {
+		return false, err
+	}
+
+	log.FromContext(ctx).Info("Re-adopting the namespace of retained tenant", "tenant", t.Name)
+	delete(namespace.Labels, TenantRetainedLabel)
+	delete(namespace.Annotations, TenantRetainedAtAnnotation)
+	return true, kube.UpdateNamespace(namespace)
+}
+
+// ExpireRetainedTenants destroys the secrets of the retained tenants whose grace period has expired, and leaves
+// their namespace unmanaged. It returns the names of the tenants which are still retained.
+func ExpireRetainedTenants(ctx context.Context, modela *managementv1.Modela) ([]string, error) {
+	namespaces, err := kube.ListNamespaces("management.modela.ai/operator=" + modela.Name + "," + TenantRetainedLabel + "=true")
+	if err != nil {
+		return nil, err
+	}
+
+	gracePeriod := defaultTenantGracePeriod
+	if modela.Spec.TenantGracePeriod != nil {
+		gracePeriod = modela.Spec.TenantGracePeriod.Duration
+	}
+
+	var retained []string
+	for _, namespace := range namespaces {
+		// A namespace without a valid retention time is retained for a new grace period rather than expired
+		retainedAt, err := time.Parse(time.RFC3339, namespace.Annotations[TenantRetainedAtAnnotation])
+		if err != nil {
+			log.FromContext(ctx).Info("Restarting the grace period of retained tenant with an invalid retention time",
+				"tenant", namespace.Name, "retainedAt", namespace.Annotations[TenantRetainedAtAnnotation])
+			if namespace.Annotations == nil {
+				namespace.Annotations = make(map[string]string)
+			}
+			namespace.Annotations[TenantRetainedAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
+			if err := kube.UpdateNamespace(&namespace); err != nil {
+				return nil, err
+			}
+			retained = append(retained, namespace.Name)
+			continue
+		}
+		if time.Since(retainedAt) < gracePeriod {
+			retained = append(retained, namespace.Name)
+			continue
+		}
+
+		log.FromContext(ctx).Info("Grace period of retained tenant expired", "tenant", namespace.Name)
+		store, err := secrets.GetSecretStore(modela)
+		if err != nil {
+			return nil, err
+		}
+		if err := store.DeleteTenant(namespace.Name); err != nil {
+			return nil, err
+		}
+		if err := kube.DeleteSecret(modela.Namespace, AdminPasswordSecretName(namespace.Name)); err != nil {
+			return nil, err
+		}
+		if err := NewTenant(namespace.Name).DropConnections(ctx, modela); err != nil {
+			return nil, err
+		}
+
+		delete(namespace.Labels, "management.modela.ai/operator")
+		delete(namespace.Labels, TenantRetainedLabel)
+		delete(namespace.Annotations, TenantRetainedAtAnnotation)
+		delete(namespace.Annotations, TenantDeletionPolicyAnnotation)
+		if err := kube.UpdateNamespace(&namespace); err != nil {
+			return nil, err
+		}
+	}
+
+	return retained, nil
+}
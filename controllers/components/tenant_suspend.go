@@ -0,0 +1,66 @@
+package components
+
+import (
+	"context"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	"github.com/metaprov/modela-operator/pkg/secrets"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+)
+
+// TenantSuspendedQuotaName is the name of the ResourceQuota which blocks new pods while a tenant is suspended. It
+// is maintained separately from the quota profile of the tenant, which is left untouched by a suspension.
+const TenantSuspendedQuotaName = "modela-tenant-suspended"
+
+// Suspend freezes the tenant. The workloads of the tenant are scaled to zero, new pods are blocked by a
+// ResourceQuota of zero pods and the workloads of the tenant lose access to the secret store.
+func (t Tenant) Suspend(ctx context.Context, modela *managementv1.Modela) error {
+	quota := &v1.ResourceQuota{
+		ObjectMeta: t.quotaObjectMeta(TenantSuspendedQuotaName, modela),
+		Spec: v1.ResourceQuotaSpec{
+			Hard: v1.ResourceList{v1.ResourcePods: resource.MustParse("0")},
+		},
+	}
+	if err := kube.ApplyResourceQuota(quota); err != nil {
+		return err
+	}
+
+	if err := kube.SuspendWorkloads(t.Name); err != nil {
+		return err
+	}
+
+	store, err := secrets.GetSecretStore(modela)
+	if err != nil {
+		return err
+	}
+
+	log.FromContext(ctx).Info("Suspended tenant", "tenant", t.Name)
+	return store.SuspendTenant(t.Name)
+}
+
+// Resume restores the access of the tenant to the secret store, removes the quota which blocks new pods and scales
+// each workload of the tenant back to its replicas before the suspension
+func (t Tenant) Resume(ctx context.Context, modela *managementv1.Modela) error {
+	store, err := secrets.GetSecretStore(modela)
+	if err != nil {
+		return err
+	}
+
+	if err := store.ApplyTenant(t.Name); err != nil {
+		return err
+	}
+
+	if err := kube.DeleteResourceQuota(t.Name, TenantSuspendedQuotaName); err != nil {
+		return err
+	}
+
+	if err := kube.ResumeWorkloads(t.Name); err != nil {
+		return err
+	}
+
+	log.FromContext(ctx).Info("Resumed tenant", "tenant", t.Name)
+	return nil
+}
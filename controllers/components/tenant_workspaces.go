@@ -0,0 +1,225 @@
+package components
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	"github.com/pkg/errors"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+)
+
+var (
+	tenantResource      = schema.GroupVersionResource{Group: "infra.modela.ai", Version: "v1alpha1", Resource: "tenants"}
+	dataProductResource = schema.GroupVersionResource{Group: "data.modela.ai", Version: "v1alpha1", Resource: "dataproducts"}
+)
+
+// ApplyWorkspaces reconciles the labs and serving sites of the tenant. The Lab and ServingSite resources are
+// applied when an entry is added or changed, the Tenant and its default DataProduct are pointed at a new default,
+// and the labs and serving sites removed from the spec are deleted. The lab and serving site created by default
+// when the tenant does not declare any are left untouched.
+func (t Tenant) ApplyWorkspaces(ctx context.Context, modela *managementv1.Modela, config managementv1.TenantConfig) error {
+	logger := log.FromContext(ctx)
+
+	filter, err := workspaceFilter(config)
+	if err != nil {
+		return err
+	}
+	if len(filter.Labs) == 0 && len(filter.ServingSites) == 0 {
+		return nil
+	}
+
+	labs, err := kube.ListLabs(t.Name)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to list the labs of tenant %s", t.Name)
+	}
+	appliedLabs := make(map[string]map[string]string, len(labs))
+	for _, lab := range labs {
+		if lab.Labels[kube.DeclarativeWorkspaceLabel] == "true" {
+			appliedLabs[lab.Name] = lab.Annotations
+		}
+	}
+
+	sites, err := kube.ListServingSites(t.Name)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to list the serving sites of tenant %s", t.Name)
+	}
+	appliedSites := make(map[string]map[string]string, len(sites))
+	for _, site := range sites {
+		if site.Labels[kube.DeclarativeWorkspaceLabel] == "true" {
+			appliedSites[site.Name] = site.Annotations
+		}
+	}
+
+	labsChanged, labDefaultChanged := workspacesChanged(filter.Labs, appliedLabs)
+	sitesChanged, siteDefaultChanged := workspacesChanged(filter.ServingSites, appliedSites)
+	if labsChanged || sitesChanged {
+		yaml, _, err := kube.LoadResources(t.ManifestPath, []kio.Filter{
+			kube.LabelFilter{Labels: map[string]string{"management.modela.ai/operator": modela.Name}},
+			kube.NamespaceFilter{Namespace: t.Name},
+			kube.TenantFilter{TenantName: t.Name},
+			filter,
+			kube.KindFilter{Kinds: []string{"Lab", "ServingSite"}},
+		}, true)
+		if err != nil {
+			return err
+		}
+
+		logger.Info("Applying tenant labs and serving sites", "tenant", t.Name,
+			"labs", len(filter.Labs), "servingSites", len(filter.ServingSites))
+		if err := kube.ApplyYaml(string(yaml)); err != nil {
+			return err
+		}
+	}
+
+	if labDefaultChanged || siteDefaultChanged {
+		if err := t.applyWorkspaceDefaults(ctx, filter); err != nil {
+			return err
+		}
+	}
+
+	// Remove the labs and serving sites which no longer exist in the spec
+	for _, lab := range filter.Labs {
+		delete(appliedLabs, lab.Name)
+	}
+	for name := range appliedLabs {
+		logger.Info("Removing tenant lab", "tenant", t.Name, "lab", name)
+		if err := kube.DeleteLab(t.Name, name); err != nil {
+			return err
+		}
+	}
+	for _, site := range filter.ServingSites {
+		delete(appliedSites, site.Name)
+	}
+	for name := range appliedSites {
+		logger.Info("Removing tenant serving site", "tenant", t.Name, "servingSite", name)
+		if err := kube.DeleteServingSite(t.Name, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyWorkspaceDefaults points the Tenant and its default DataProduct at the default lab and serving site
+func (t Tenant) applyWorkspaceDefaults(ctx context.Context, filter kube.WorkspaceFilter) error {
+	tenantSpec, productSpec := map[string]interface{}{}, map[string]interface{}{}
+	if lab := kube.DefaultWorkspace(filter.Labs); lab != nil {
+		tenantSpec["defaultLabName"], productSpec["defaultLabName"] = lab.Name, lab.Name
+		if len(lab.WorkloadClasses) > 0 {
+			productSpec["trainingResources"] = map[string]interface{}{"workloadName": lab.WorkloadClasses[0]}
+		}
+	}
+	if site := kube.DefaultWorkspace(filter.ServingSites); site != nil {
+		tenantSpec["defaultServingSiteName"], productSpec["defaultServingSiteName"] = site.Name, site.Name
+		if len(site.WorkloadClasses) > 0 {
+			productSpec["servingResources"] = map[string]interface{}{"workloadName": site.WorkloadClasses[0]}
+		}
+	}
+
+	log.FromContext(ctx).Info("Applying default lab and serving site", "tenant", t.Name)
+	patch, _ := json.Marshal(map[string]interface{}{"spec": tenantSpec})
+	if err := kube.PatchResource(tenantResource, "modela-system", t.Name, patch); err != nil {
+		return errors.Wrapf(err, "Failed to update the defaults of tenant %s", t.Name)
+	}
+
+	patch, _ = json.Marshal(map[string]interface{}{"spec": productSpec})
+	if err := kube.PatchResource(dataProductResource, t.Name, "default-product", patch); err != nil && !k8serr.IsNotFound(err) {
+		return errors.Wrapf(err, "Failed to update the defaults of the data product of tenant %s", t.Name)
+	}
+	return nil
+}
+
+// workspacesChanged reports if any workspace is missing or differs from its applied version, and if the default
+// workspace is missing, differs from its applied version or was not the applied default
+func workspacesChanged(workspaces []kube.TenantWorkspace, applied map[string]map[string]string) (bool, bool) {
+	var changed bool
+	for _, workspace := range workspaces {
+		if annotations, exists := applied[workspace.Name]; !exists || annotations[kube.WorkspaceVersionAnnotation] != workspace.Version {
+			changed = true
+		}
+	}
+
+	defaultWorkspace := kube.DefaultWorkspace(workspaces)
+	if defaultWorkspace == nil {
+		return changed, false
+	}
+	annotations, exists := applied[defaultWorkspace.Name]
+	return changed, !exists || annotations[kube.WorkspaceVersionAnnotation] != defaultWorkspace.Version ||
+		annotations[kube.DefaultWorkspaceAnnotation] != "true"
+}
+
+// workspaceFilter validates the labs and serving sites of the tenant and returns the filter which renders them
+func workspaceFilter(config managementv1.TenantConfig) (kube.WorkspaceFilter, error) {
+	var filter kube.WorkspaceFilter
+
+	names, defaults := make(map[string]bool, len(config.Labs)), 0
+	for _, lab := range config.Labs {
+		if names[lab.Name] {
+			return filter, errors.Errorf("Lab %s is defined more than once", lab.Name)
+		}
+		names[lab.Name] = true
+		if lab.Default {
+			defaults++
+		}
+
+		workspace := kube.TenantWorkspace{
+			Name:            lab.Name,
+			Default:         lab.Default,
+			WorkloadClasses: lab.WorkloadClasses,
+			Version:         workspaceVersion(lab),
+		}
+		workspace.MaxCPU, workspace.MaxMemory, workspace.MaxPods = workspaceLimits(lab.Limits)
+		filter.Labs = append(filter.Labs, workspace)
+	}
+	if defaults > 1 {
+		return filter, errors.New("Only one lab may be marked as the default")
+	}
+
+	names, defaults = make(map[string]bool, len(config.ServingSites)), 0
+	for _, site := range config.ServingSites {
+		if names[site.Name] {
+			return filter, errors.Errorf("Serving site %s is defined more than once", site.Name)
+		}
+		names[site.Name] = true
+		if site.Default {
+			defaults++
+		}
+
+		workspace := kube.TenantWorkspace{
+			Name:            site.Name,
+			Default:         site.Default,
+			WorkloadClasses: site.WorkloadClasses,
+			FQDN:            site.FQDN,
+			Version:         workspaceVersion(site),
+		}
+		workspace.MaxCPU, workspace.MaxMemory, workspace.MaxPods = workspaceLimits(site.Limits)
+		filter.ServingSites = append(filter.ServingSites, workspace)
+	}
+	if defaults > 1 {
+		return filter, errors.New("Only one serving site may be marked as the default")
+	}
+
+	return filter, nil
+}
+
+func workspaceLimits(limits *managementv1.TenantWorkspaceLimitSpec) (cpu, memory *resource.Quantity, pods *int32) {
+	if limits == nil {
+		return nil, nil, nil
+	}
+	return limits.CPU, limits.Memory, limits.Pods
+}
+
+// workspaceVersion returns a version which changes with the spec of a lab or serving site
+func workspaceVersion(spec interface{}) string {
+	data, _ := json.Marshal(spec)
+	checksum := sha256.Sum256(data)
+	return hex.EncodeToString(checksum[:8])
+}
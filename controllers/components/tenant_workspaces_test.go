@@ -0,0 +1,65 @@
+package components
+
+import (
+	"github.com/metaprov/modela-operator/api/v1alpha1"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+var _ = Describe("Tenant labs and serving sites", func() {
+	It("Should reject duplicate labs and more than one default", func() {
+		_, err := workspaceFilter(v1alpha1.TenantConfig{Labs: []v1alpha1.TenantLabSpec{{Name: "gpu"}, {Name: "gpu"}}})
+		Expect(err).To(HaveOccurred())
+
+		_, err = workspaceFilter(v1alpha1.TenantConfig{ServingSites: []v1alpha1.TenantServingSiteSpec{
+			{Name: "staging", Default: true},
+			{Name: "production", Default: true},
+		}})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should use the first workspace when none is marked as the default", func() {
+		filter, err := workspaceFilter(v1alpha1.TenantConfig{Labs: []v1alpha1.TenantLabSpec{{Name: "gpu"}, {Name: "cpu"}}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kube.DefaultWorkspace(filter.Labs).Name).To(Equal("gpu"))
+	})
+
+	It("Should detect added workspaces and a changed default", func() {
+		filter, err := workspaceFilter(v1alpha1.TenantConfig{Labs: []v1alpha1.TenantLabSpec{{Name: "gpu"}, {Name: "cpu", Default: true}}})
+		Expect(err).NotTo(HaveOccurred())
+
+		applied := map[string]map[string]string{
+			"gpu": {kube.WorkspaceVersionAnnotation: filter.Labs[0].Version, kube.DefaultWorkspaceAnnotation: "true"},
+		}
+		changed, defaultChanged := workspacesChanged(filter.Labs, applied)
+		Expect(changed).To(BeTrue())
+		Expect(defaultChanged).To(BeTrue())
+
+		applied["cpu"] = map[string]string{kube.WorkspaceVersionAnnotation: filter.Labs[1].Version, kube.DefaultWorkspaceAnnotation: "true"}
+		changed, defaultChanged = workspacesChanged(filter.Labs, applied)
+		Expect(changed).To(BeFalse())
+		Expect(defaultChanged).To(BeFalse())
+	})
+
+	It("Should set the workload classes of each rendered lab", func() {
+		filter, err := workspaceFilter(v1alpha1.TenantConfig{Labs: []v1alpha1.TenantLabSpec{
+			{Name: "gpu", WorkloadClasses: []string{"gpu-large", "gpu-small"}},
+			{Name: "cpu"},
+		}})
+		Expect(err).NotTo(HaveOccurred())
+
+		nodes, err := kio.FromBytes([]byte("apiVersion: infra.modela.ai/v1alpha1\nkind: Lab\nmetadata:\n  name: default-lab\nspec:\n  owner: admin\n"))
+		Expect(err).NotTo(HaveOccurred())
+		nodes, err = filter.Filter(nodes)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodes).To(HaveLen(2))
+
+		classes, err := nodes[0].GetSlice("spec.workloadClasses")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(classes).To(Equal([]interface{}{"gpu-large", "gpu-small"}))
+		_, err = nodes[1].GetSlice("spec.workloadClasses")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -9,10 +9,10 @@ import (
 	"github.com/metaprov/modela-operator/pkg/vault"
 	"github.com/pkg/errors"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/klog/v2"
-	"os"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/log"
-	"time"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"strings"
 
 	"github.com/hashicorp/vault/api"
 )
@@ -23,12 +23,92 @@ path "%s/*" {
 }
 `
 
+// OperatorPolicyTemplate grants the Modela Operator access to the secrets which it manages, and nothing else. The
+// roles of the tenants may only grant the tenant policy, which is written once with the root token.
+const OperatorPolicyTemplate = `
+path "%[1]s/data/jwt-secret" {
+  capabilities = ["create", "read", "update", "patch"]
+}
+
+path "%[1]s/data/tenant/+/accounts/*" {
+  capabilities = ["create", "read", "update", "patch"]
+}
+
+path "%[1]s/data/tenant/+/connections/*" {
+  capabilities = ["create", "read", "update", "patch"]
+}
+
+path "%[1]s/data/tenant/+/api-key-secret" {
+  capabilities = ["create", "read", "update", "patch"]
+}
+
+path "%[1]s/metadata/tenant/*" {
+  capabilities = ["read", "list", "delete"]
+}
+
+path "auth/kubernetes/role/modela-tenant-*" {
+  capabilities = ["create", "read", "update", "delete"]
+  allowed_parameters = {
+    "bound_service_account_names" = []
+    "bound_service_account_namespaces" = []
+    "token_policies" = ["modela-tenant"]
+  }
+}
+`
+
+// ListenerConfig is the default listener configuration of the Vault chart
+const ListenerConfig = `
+listener "tcp" {
+  tls_disable = 1
+  address = "[::]:8200"
+  cluster_address = "[::]:8201"
+}
+`
+
+// TLSListenerConfig is the listener configuration of the Vault chart with TLS enabled through the certificate
+// issued to the Vault service
+const TLSListenerConfig = `
+listener "tcp" {
+  address = "[::]:8200"
+  cluster_address = "[::]:8201"
+  tls_cert_file = "/vault/userconfig/modela-vault-tls/tls.crt"
+  tls_key_file = "/vault/userconfig/modela-vault-tls/tls.key"
+  tls_client_ca_file = "/vault/userconfig/modela-vault-tls/ca.crt"
+}
+`
+
+// FileStorageConfig is the storage configuration of the Vault chart in standalone mode
+const FileStorageConfig = `
+storage "file" {
+  path = "/vault/data"
+}
+`
+
+// RaftStorageConfig is the storage configuration of the Vault chart in high-availability mode
+const RaftStorageConfig = `
+storage "raft" {
+  path = "/vault/data"
+}
+
+service_registration "kubernetes" {}
+`
+
+const TransitSealTemplate = `
+seal "transit" {
+  address = "%s"
+  mount_path = "%s"
+  key_name = "%s"
+  tls_skip_verify = "%t"
+}
+`
+
 // Modela system represent the model core system
 type Vault struct {
 	Namespace     string
 	Name          string
 	ReleaseName   string
 	PodNamePrefix string
+	ManifestPath  string
 }
 
 func NewVault() *Vault {
@@ -37,6 +117,7 @@ func NewVault() *Vault {
 		Name:          "vault",
 		ReleaseName:   "modela-vault",
 		PodNamePrefix: "vault",
+		ManifestPath:  "vault-tls",
 	}
 }
 
@@ -79,11 +160,135 @@ func (v Vault) Install(ctx context.Context, modela *managementv1.Modela) error {
 		}
 	}
 
+	if err := v.applyServerConfig(values, modela); err != nil {
+		return err
+	}
+
+	if modela.Spec.Vault.TLS.Enabled {
+		if err := v.InstallCertificate(ctx, modela); err != nil {
+			return err
+		}
+		if err := v.applyTLS(values); err != nil {
+			return err
+		}
+	}
+
+	if modela.Spec.Vault.KeyCustody.Backend == managementv1.VaultKeyCustodyTransit && modela.Spec.Vault.KeyCustody.Transit != nil {
+		if err := v.applyTransitSeal(values, modela); err != nil {
+			return err
+		}
+	}
+
 	return helm.InstallChart(ctx, v.Name, v.Namespace, v.ReleaseName, values)
 }
 
+// InstallCertificate issues the certificate of the Vault service through cert-manager
+func (v Vault) InstallCertificate(ctx context.Context, modela *managementv1.Modela) error {
+	if _, err := kube.GetCRDVersion("issuers.cert-manager.io"); k8serr.IsNotFound(err) {
+		return errors.New("cert-manager must be installed to enable TLS on Vault")
+	} else if err != nil {
+		return err
+	}
+
+	yaml, _, err := kube.LoadResources(v.ManifestPath, []kio.Filter{
+		kube.LabelFilter{Labels: map[string]string{"management.modela.ai/operator": modela.Name}},
+		kube.OwnerReferenceFilter{Owner: modela.GetName(), OwnerNamespace: modela.GetNamespace(), UID: string(modela.GetUID())},
+	}, false)
+	if err != nil {
+		return err
+	}
+
+	log.FromContext(ctx).Info("Applying Vault certificate resources", "length", len(yaml))
+	return kube.ApplyYaml(string(yaml))
+}
+
+// configPath returns the path of the chart value which contains the server configuration
+func (v Vault) configPath(modela *managementv1.Modela) []string {
+	if modela.Spec.Vault.HA.Enabled {
+		return []string{"server", "ha", "raft", "config"}
+	}
+	return []string{"server", "standalone", "config"}
+}
+
+// applyServerConfig configures the chart in standalone or high-availability mode. The server configuration is
+// generated from the TLS and storage settings unless it has been specified in the chart values.
+func (v Vault) applyServerConfig(values map[string]interface{}, modela *managementv1.Modela) error {
+	ha := modela.Spec.Vault.HA
+	if ha.Enabled {
+		replicas := ha.Replicas
+		if replicas < 1 {
+			replicas = 3
+		}
+		for field, value := range map[string]interface{}{"enabled": true, "replicas": int64(replicas)} {
+			if err := unstructured.SetNestedField(values, value, "server", "ha", field); err != nil {
+				return err
+			}
+		}
+		for field, value := range map[string]interface{}{"enabled": true, "setNodeId": true} {
+			if err := unstructured.SetNestedField(values, value, "server", "ha", "raft", field); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, found, _ := unstructured.NestedString(values, v.configPath(modela)...); found {
+		return nil
+	}
+
+	listener, storage := ListenerConfig, FileStorageConfig
+	if modela.Spec.Vault.TLS.Enabled {
+		listener = TLSListenerConfig
+	}
+	if ha.Enabled {
+		storage = RaftStorageConfig
+	}
+	return unstructured.SetNestedField(values, "\nui = true\n"+listener+storage, v.configPath(modela)...)
+}
+
+// applyTLS mounts the certificate of the Vault service into the server
+func (v Vault) applyTLS(values map[string]interface{}) error {
+	if err := unstructured.SetNestedField(values, false, "global", "tlsDisable"); err != nil {
+		return err
+	}
+
+	volumes, _, _ := unstructured.NestedSlice(values, "server", "extraVolumes")
+	volumes = append(volumes, map[string]interface{}{"type": "secret", "name": vault.TLSSecretName})
+	if err := unstructured.SetNestedSlice(values, volumes, "server", "extraVolumes"); err != nil {
+		return err
+	}
+
+	return unstructured.SetNestedField(values, "/vault/userconfig/"+vault.TLSSecretName+"/ca.crt",
+		"server", "extraEnvironmentVars", "VAULT_CACERT")
+}
+
+// applyTransitSeal adds the transit seal stanza to the server configuration and mounts the transit token
+func (v Vault) applyTransitSeal(values map[string]interface{}, modela *managementv1.Modela) error {
+	transit := modela.Spec.Vault.KeyCustody.Transit
+	config, _, _ := unstructured.NestedString(values, v.configPath(modela)...)
+
+	mountPath, keyName := transit.MountPath, transit.KeyName
+	if mountPath == "" {
+		mountPath = "transit/"
+	}
+	if keyName == "" {
+		keyName = "modela-autounseal"
+	}
+	config += fmt.Sprintf(TransitSealTemplate, transit.Address, mountPath, keyName, transit.TLSSkipVerify)
+	if err := unstructured.SetNestedField(values, config, v.configPath(modela)...); err != nil {
+		return err
+	}
+
+	return unstructured.SetNestedSlice(values, []interface{}{
+		map[string]interface{}{
+			"envName":    "VAULT_TOKEN",
+			"secretName": transit.TokenSecretRef.Name,
+			"secretKey":  transit.TokenSecretRef.Key,
+		},
+	}, "server", "extraSecretEnvironmentVars")
+}
+
 func (v Vault) ConfigureVault(ctx context.Context, modela *managementv1.Modela) error {
-	client, err := vault.GetUnauthenticatedClient(modela)
+	client, err := v.leaderClient(modela)
 	if err != nil {
 		return err
 	}
@@ -94,74 +299,326 @@ func (v Vault) ConfigureVault(ctx context.Context, modela *managementv1.Modela)
 		return err
 	}
 
+	var keys []string
 	if !initialized {
-		initResponse, err := sys.Init(&api.InitRequest{
-			SecretShares:    1,
-			SecretThreshold: 1,
-		})
+		if keys, err = v.initialize(ctx, client, modela); err != nil {
+			return err
+		}
+	} else if keys, err = v.completeInitialization(ctx, client, modela); err != nil {
+		return err
+	}
+
+	if err := v.joinFollowers(ctx, modela, keys); err != nil {
+		return err
+	}
+
+	// The root token is only kept until the bootstrap of Vault has completed
+	secret, err := kube.GetSecret(v.Namespace, "vault-root-token")
+	if k8serr.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	token, ok := secret.Data["token"]
+	if !ok {
+		return nil
+	}
+
+	client.SetToken(string(token))
+	if err := v.bootstrap(ctx, client, modela); err != nil {
+		return err
+	}
+
+	return v.revokeRootToken(ctx, client)
+}
+
+// leaderClient returns a client for the server which is initialized by the operator. In high-availability mode,
+// the first server of the stateful set is initialized and the other servers join its Raft cluster.
+func (v Vault) leaderClient(modela *managementv1.Modela) (*api.Client, error) {
+	if !modela.Spec.Vault.Install || !modela.Spec.Vault.HA.Enabled {
+		return vault.GetUnauthenticatedClient(modela)
+	}
+
+	clients, err := vault.GetUnauthenticatedServerClients(modela)
+	if err != nil {
+		return nil, err
+	}
+	client, ok := clients[v.ReleaseName+"-0"]
+	if !ok {
+		return nil, errors.Errorf("Vault server %s-0 is not running", v.ReleaseName)
+	}
+	return client, nil
+}
+
+// joinFollowers joins the uninitialized servers of a high-availability deployment to the Raft cluster of the
+// first server. When the key shares are known, the joined servers are unsealed; otherwise they are left to the
+// auto-unseal of the operator.
+func (v Vault) joinFollowers(ctx context.Context, modela *managementv1.Modela, keys []string) error {
+	if !modela.Spec.Vault.Install || !modela.Spec.Vault.HA.Enabled {
+		return nil
+	}
 
+	clients, err := vault.GetUnauthenticatedServerClients(modela)
+	if err != nil {
+		return err
+	}
+
+	leader := v.ReleaseName + "-0"
+	request := &api.RaftJoinRequest{LeaderAPIAddr: fmt.Sprintf("http://%s.%s-internal:8200", leader, v.ReleaseName)}
+	if modela.Spec.Vault.TLS.Enabled {
+		secret, err := kube.GetSecret(v.Namespace, vault.TLSSecretName)
 		if err != nil {
-			return errors.Wrap(err, "Failed to initialize Vault server")
+			return err
 		}
+		request.LeaderAPIAddr = fmt.Sprintf("https://%s.%s-internal:8200", leader, v.ReleaseName)
+		request.LeaderCACert = string(secret.Data["ca.crt"])
+	}
 
-		if err := kube.CreateOrUpdateSecret("modela-system", "vault-keys", map[string]string{
-			"key": initResponse.Keys[0],
-		}); err != nil {
-			return errors.Wrap(err, "Failed to create Vault keys secret")
+	for name, client := range clients {
+		if name == leader {
+			continue
 		}
 
-		if err := kube.CreateOrUpdateSecret("modela-system", "vault-root-token", map[string]string{
-			"token": initResponse.RootToken,
-		}); err != nil {
-			return errors.Wrap(err, "Failed to create Vault keys secret")
+		status, err := client.Sys().SealStatus()
+		if err != nil {
+			return errors.Wrapf(err, "Failed to get status of Vault server %s", name)
+		}
+		if status.Initialized {
+			continue
 		}
 
-		// Unseal the vault
-		if _, err := sys.Unseal(initResponse.Keys[0]); err != nil {
-			return errors.Wrap(err, "Failed to unseal Vault")
+		log.FromContext(ctx).Info("Joining Vault server to Raft cluster", "server", name)
+		if _, err := client.Sys().RaftJoin(request); err != nil {
+			return errors.Wrapf(err, "Failed to join Vault server %s to Raft cluster", name)
 		}
 
-		client.SetToken(initResponse.RootToken)
+		for _, key := range keys {
+			if status, err := client.Sys().Unseal(key); err != nil {
+				return errors.Wrapf(err, "Failed to unseal Vault server %s", name)
+			} else if !status.Sealed {
+				break
+			}
+		}
+	}
+
+	return nil
+}
 
-		// Mount the KVv2 secret engine
+// VaultInitFallbackSecret holds the key shares and the root token of an initialized Vault when they could not be
+// handed to the key custodian. They cannot be recovered from Vault, so they are kept until the custodian stores them.
+const VaultInitFallbackSecret = "vault-init-fallback"
+
+// initialize initializes the Vault server, hands the resulting key shares to the configured key custodian and
+// unseals the server. The root token is stored until the bootstrap of Vault is complete. The key shares are
+// returned so that they can be used to unseal the other servers of a high-availability deployment.
+func (v Vault) initialize(ctx context.Context, client *api.Client, modela *managementv1.Modela) ([]string, error) {
+	logger := log.FromContext(ctx)
+
+	shares, threshold := modela.Spec.Vault.SecretShares, modela.Spec.Vault.SecretThreshold
+	if shares < 1 {
+		shares = 1
+	}
+	if threshold < 1 {
+		threshold = 1
+	}
+	if threshold > shares {
+		return nil, errors.Errorf("Vault secret threshold (%d) must not exceed the number of secret shares (%d)", threshold, shares)
+	}
+
+	// The custodian is verified first, as the key shares are lost if they cannot be stored once Vault is initialized
+	custodian, err := vault.GetKeyCustodian(modela)
+	if err != nil {
+		return nil, err
+	}
+	if err := custodian.Verify(); err != nil {
+		return nil, errors.Wrap(err, "Failed to verify the Vault key custody")
+	}
+	if err := kube.VerifySecretWritable(v.Namespace, VaultInitFallbackSecret); err != nil {
+		return nil, err
+	}
+
+	var request = &api.InitRequest{SecretShares: shares, SecretThreshold: threshold}
+	transit := modela.Spec.Vault.KeyCustody.Backend == managementv1.VaultKeyCustodyTransit
+	if transit {
+		request = &api.InitRequest{RecoveryShares: shares, RecoveryThreshold: threshold}
+	}
+
+	logger.Info("Initializing Vault server", "shares", shares, "threshold", threshold,
+		"custody", modela.Spec.Vault.KeyCustody.Backend)
+	initResponse, err := client.Sys().Init(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to initialize Vault server")
+	}
+
+	keys := initResponse.Keys
+	if transit {
+		keys = initResponse.RecoveryKeys
+	}
+	return v.storeInitialization(ctx, client, modela, custodian, keys, initResponse.RootToken)
+}
+
+// storeInitialization hands the key shares to the custodian, stores the root token and unseals the server. When
+// the key shares cannot be stored, they are written with the root token to the fallback secret, from which the
+// initialization is completed by a later reconcile.
+func (v Vault) storeInitialization(ctx context.Context, client *api.Client, modela *managementv1.Modela,
+	custodian vault.KeyCustodian, keys []string, rootToken string) ([]string, error) {
+	if err := custodian.StoreKeys(keys); err != nil {
+		fallback := map[string]string{"root-token": rootToken}
+		for i, key := range keys {
+			fallback[fmt.Sprintf("key-%d", i)] = key
+		}
+		if fallbackErr := kube.CreateOrUpdateSecret(v.Namespace, VaultInitFallbackSecret, fallback); fallbackErr != nil {
+			log.FromContext(ctx).Error(fallbackErr, "Failed to write the Vault key shares to the fallback secret")
+			return nil, errors.Wrapf(err, "Failed to store the Vault key shares, which are not persisted")
+		}
+		return nil, errors.Wrapf(err, "Failed to store the Vault key shares, which were kept in the %s secret", VaultInitFallbackSecret)
+	}
+
+	if err := kube.CreateOrUpdateSecret(v.Namespace, "vault-root-token", map[string]string{
+		"token": rootToken,
+	}); err != nil {
+		return nil, errors.Wrap(err, "Failed to create Vault root token secret")
+	}
+
+	// Unseal the vault with the key shares held in memory, as the custodian may not be able to return them
+	if modela.Spec.Vault.KeyCustody.Backend == managementv1.VaultKeyCustodyTransit {
+		return nil, nil
+	}
+	for _, key := range keys {
+		if status, err := client.Sys().Unseal(key); err != nil {
+			return nil, errors.Wrap(err, "Failed to unseal Vault")
+		} else if !status.Sealed {
+			break
+		}
+	}
+	return keys, nil
+}
+
+// completeInitialization hands the key shares kept in the fallback secret to the custodian, once Vault was
+// initialized but its key shares could not be stored. The fallback secret is deleted once they are stored.
+func (v Vault) completeInitialization(ctx context.Context, client *api.Client, modela *managementv1.Modela) ([]string, error) {
+	values, err := kube.GetSecretValuesAsString(v.Namespace, VaultInitFallbackSecret)
+	if k8serr.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	custodian, err := vault.GetKeyCustodian(modela)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for i := 0; values[fmt.Sprintf("key-%d", i)] != ""; i++ {
+		keys = append(keys, values[fmt.Sprintf("key-%d", i)])
+	}
+
+	log.FromContext(ctx).Info("Storing the Vault key shares kept in the fallback secret")
+	if keys, err = v.storeInitialization(ctx, client, modela, custodian, keys, values["root-token"]); err != nil {
+		return nil, err
+	}
+	return keys, kube.DeleteSecret(v.Namespace, VaultInitFallbackSecret)
+}
+
+// bootstrap configures the secret engine, policies and authentication methods required by Modela. It must be
+// called with a client authenticated by the root token, and is safe to call more than once.
+func (v Vault) bootstrap(ctx context.Context, client *api.Client, modela *managementv1.Modela) error {
+	log.FromContext(ctx).Info("Configuring Vault server")
+	sys := client.Sys()
+
+	// Mount the KVv2 secret engine
+	mounts, err := sys.ListMounts()
+	if err != nil {
+		return errors.Wrap(err, "Failed to list secret engines")
+	}
+	if _, ok := mounts[strings.Trim(modela.Spec.Vault.MountPath, "/")+"/"]; !ok {
 		if err := sys.Mount(modela.Spec.Vault.MountPath, &api.MountInput{
 			Type:    "kv",
 			Options: map[string]string{"version": "2"},
 		}); err != nil {
 			return errors.Wrap(err, "Failed to mount secret engine")
 		}
+	}
 
-		// Create the policy for the mount
-		var policy = fmt.Sprintf(PolicyTemplate, modela.Spec.Vault.MountPath)
-		if err := sys.PutPolicy("modela-policy", policy); err != nil {
-			return errors.Wrap(err, "Failed to create policy")
-		}
+	// Create the policy for the mount
+	var policy = fmt.Sprintf(PolicyTemplate, modela.Spec.Vault.MountPath)
+	if err := sys.PutPolicy("modela-policy", policy); err != nil {
+		return errors.Wrap(err, "Failed to create policy")
+	}
+
+	// Create the policy for the operator, which is limited to the paths it writes
+	policy = fmt.Sprintf(OperatorPolicyTemplate, strings.Trim(modela.Spec.Vault.MountPath, "/"))
+	if err := sys.PutPolicy("modela-operator-policy", policy); err != nil {
+		return errors.Wrap(err, "Failed to create operator policy")
+	}
 
-		// Configure Kubernetes authentication
+	// Configure Kubernetes authentication
+	auths, err := sys.ListAuth()
+	if err != nil {
+		return errors.Wrap(err, "Failed to list authentication methods")
+	}
+	if _, ok := auths["kubernetes/"]; !ok {
 		if err := sys.EnableAuthWithOptions("kubernetes", &api.EnableAuthOptions{Type: "kubernetes"}); err != nil {
 			return errors.Wrap(err, "Failed to enable Kubernetes authentication")
 		}
+	}
 
-		c := client.Logical()
-		if _, err := c.Write("/auth/kubernetes/config", map[string]interface{}{
-			"kubernetes_host": "https://kubernetes.default.svc",
-		}); err != nil {
-			return errors.Wrap(err, "Failed to configure Kubernetes authentication")
-		}
+	// Create the policy of the tenants, which is keyed on the namespace of the service account of the workload
+	if auths, err = sys.ListAuth(); err != nil {
+		return errors.Wrap(err, "Failed to list authentication methods")
+	} else if auths["kubernetes/"] == nil {
+		return errors.New("The Kubernetes authentication method is not enabled")
+	}
+	policy = fmt.Sprintf(vault.TenantPolicyTemplate, strings.Trim(modela.Spec.Vault.MountPath, "/"), auths["kubernetes/"].Accessor)
+	if err := sys.PutPolicy(vault.TenantPolicyName, policy); err != nil {
+		return errors.Wrap(err, "Failed to create tenant policy")
+	}
 
-		// Configure the Kubernetes auth method role
-		if _, err := c.Write("/auth/kubernetes/role/modela", map[string]interface{}{
-			"name": "modela",
-			"bound_service_account_names": []string{"lab-job-sa", "modela-api-gateway", "modela-data-plane",
-				"modela-control-plane", "servingsite-job-sa", "modela-operator-controller-manager"},
-			"bound_service_account_namespaces": []string{"*"},
-			"policies":                         []string{"modela-policy"},
-		}); err != nil {
-			return errors.Wrap(err, "Failed to configure Kubernetes authentication roles")
-		}
+	c := client.Logical()
+	if _, err := c.Write("/auth/kubernetes/config", map[string]interface{}{
+		"kubernetes_host": "https://kubernetes.default.svc",
+	}); err != nil {
+		return errors.Wrap(err, "Failed to configure Kubernetes authentication")
 	}
 
-	return nil
+	// Configure the Kubernetes auth method role of the system workloads. Workloads inside tenant namespaces
+	// authenticate through the role of their tenant, which is created when the tenant is installed.
+	if _, err := c.Write("/auth/kubernetes/role/modela", map[string]interface{}{
+		"name": "modela",
+		"bound_service_account_names": []string{"lab-job-sa", "modela-api-gateway", "modela-data-plane",
+			"modela-control-plane", "servingsite-job-sa"},
+		"bound_service_account_namespaces": []string{v.Namespace},
+		"policies":                         []string{"modela-policy"},
+	}); err != nil {
+		return errors.Wrap(err, "Failed to configure Kubernetes authentication roles")
+	}
+
+	// Configure the dedicated role of the operator
+	if _, err := c.Write("/auth/kubernetes/role/"+vault.OperatorRole, map[string]interface{}{
+		"name":                             vault.OperatorRole,
+		"bound_service_account_names":      []string{"modela-operator-controller-manager"},
+		"bound_service_account_namespaces": []string{v.Namespace},
+		"policies":                         []string{"modela-operator-policy"},
+		"token_period":                     "24h",
+	}); err != nil {
+		return errors.Wrap(err, "Failed to configure Kubernetes authentication roles")
+	}
+
+	// Configure the roles used by the jobs which take and restore snapshots of the Raft storage, and which back up
+	// and restore the secrets of the mount
+	return NewVaultSnapshot().ConfigureRoles(client, modela.Spec.Vault.MountPath)
+}
+
+// revokeRootToken revokes the root token of the client and removes it from the cluster. The operator
+// authenticates through the Kubernetes auth method from then on.
+func (v Vault) revokeRootToken(ctx context.Context, client *api.Client) error {
+	log.FromContext(ctx).Info("Revoking Vault root token")
+	if err := client.Auth().Token().RevokeSelf(""); err != nil {
+		return errors.Wrap(err, "Failed to revoke Vault root token")
+	}
+
+	return kube.DeleteSecret(v.Namespace, "vault-root-token")
 }
 
 // Check if we are still installing the database
@@ -189,53 +646,3 @@ func (v Vault) Ready(ctx context.Context) (bool, error) {
 func (v Vault) Uninstall(ctx context.Context, modela *managementv1.Modela) error {
 	return helm.UninstallChart(ctx, v.Name, v.Namespace, v.ReleaseName, map[string]interface{}{})
 }
-
-func performAutoUnseal() {
-	// Check if we are running inside the cluster. If not, abort as we have no way to communicate with Vault
-	if _, err := os.Stat("/var/run/secrets/kubernetes.io/serviceaccount/token"); errors.Is(err, os.ErrNotExist) {
-		return
-	}
-
-	// Check if we have the Vault keys
-	if exists, err := kube.IsNamespaceCreated("modela-system"); !exists || err != nil {
-		return
-	}
-
-	secret, err := kube.GetSecret("modela-system", "vault-keys")
-	if err != nil {
-		return
-	}
-
-	key, ok := secret.Data["key"]
-	if !ok {
-		return
-	}
-
-	client, err := vault.GetUnauthenticatedClientInCluster()
-	if err != nil {
-		return
-	}
-
-	sys := client.Sys()
-	sealed, err := sys.SealStatus()
-	if err != nil {
-		klog.ErrorS(err, "Failed to check if Vault is sealed")
-		return
-	}
-
-	if sealed.Sealed {
-		klog.Info("Attempting to unseal Vault server")
-		_, _ = sys.Unseal(string(key))
-	}
-}
-
-func StartAutoUnseal(ctx context.Context) {
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-time.After(5 * time.Second):
-			performAutoUnseal()
-		}
-	}
-}
@@ -0,0 +1,350 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/vault/api"
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	"github.com/metaprov/modela-operator/pkg/vault"
+	"github.com/metaprov/modelaapi/pkg/util"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"regexp"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strings"
+)
+
+const (
+	// SnapshotPolicyTemplate grants the snapshot and backup jobs permission to take snapshots of the Raft storage,
+	// and to export the secrets of the mount when Vault does not run in high-availability mode
+	SnapshotPolicyTemplate = `
+path "sys/storage/raft/snapshot" {
+  capabilities = ["read"]
+}
+
+path "%[1]s/data/*" {
+  capabilities = ["read"]
+}
+
+path "%[1]s/metadata/*" {
+  capabilities = ["read", "list"]
+}
+`
+
+	// RestorePolicyTemplate grants the restore jobs permission to restore snapshots of the Raft storage, and to
+	// import the exported secrets of the mount
+	RestorePolicyTemplate = `
+path "sys/storage/raft/snapshot-force" {
+  capabilities = ["update"]
+}
+
+path "%[1]s/data/*" {
+  capabilities = ["create", "update"]
+}
+`
+
+	vaultLoginScript = `export VAULT_TOKEN=$(vault write -field=token auth/kubernetes/login role=$VAULT_ROLE ` +
+		`jwt=@/var/run/secrets/kubernetes.io/serviceaccount/token)`
+
+	snapshotScript = `set -e
+` + vaultLoginScript + `
+vault operator raft snapshot save /snapshots/vault-$(date +%Y%m%d%H%M%S).snap
+`
+
+	restoreScript = `set -e
+` + vaultLoginScript + `
+vault operator raft snapshot restore -force /snapshots/$SNAPSHOT
+`
+
+	pruneVolumeScript = `set -e
+ls -1 /snapshots/vault-*.snap | sort -r | tail -n +$((RETENTION + 1)) | xargs -r rm -f
+`
+
+	minioAliasScript = `set -e
+mc alias set modela http://modela-storage-minio.modela-system.svc.cluster.local:9000 "$MINIO_ROOT_USER" "$MINIO_ROOT_PASSWORD"
+`
+
+	uploadScript = minioAliasScript + `mc mb --ignore-existing modela/$BUCKET
+mc cp /snapshots/vault-*.snap modela/$BUCKET/
+mc ls modela/$BUCKET | awk '{print $NF}' | grep '^vault-.*\.snap$' | sort -r | tail -n +$((RETENTION + 1)) | \
+  while read snapshot; do mc rm modela/$BUCKET/$snapshot; done
+`
+
+	downloadScript = minioAliasScript + `mc cp modela/$BUCKET/$SNAPSHOT /snapshots/$SNAPSHOT
+`
+)
+
+// VaultSnapshot takes scheduled snapshots of the Raft storage of Vault in high-availability mode, and restores them
+// on demand. Snapshots are taken by a cron job which authenticates with Vault through a dedicated Kubernetes auth
+// role, and are stored either in a bucket of the bundled Minio server or in a persistent volume claim.
+type VaultSnapshot struct {
+	Namespace      string
+	Name           string
+	ServiceAccount string
+	SnapshotRole   string
+	RestoreRole    string
+	VaultImage     string
+	MinioImage     string
+}
+
+func NewVaultSnapshot() *VaultSnapshot {
+	return &VaultSnapshot{
+		Namespace:      "modela-system",
+		Name:           "modela-vault-snapshot",
+		ServiceAccount: "modela-vault-snapshot",
+		SnapshotRole:   "modela-vault-snapshot",
+		RestoreRole:    "modela-vault-restore",
+		VaultImage:     "hashicorp/vault:1.13.1",
+		MinioImage:     "docker.io/bitnami/minio-client:2023.5.4",
+	}
+}
+
+// IsEnabled returns true if scheduled snapshots are configured
+func (s VaultSnapshot) IsEnabled(modela managementv1.Modela) bool {
+	return modela.Spec.Vault.Install && modela.Spec.Vault.HA.Enabled && modela.Spec.Vault.HA.Snapshots.Schedule != ""
+}
+
+// ConfigureRoles creates the policies and Kubernetes auth roles of the snapshot and restore jobs, which are also
+// used by the backup jobs. It must be called with a client authenticated by the root token.
+func (s VaultSnapshot) ConfigureRoles(client *api.Client, mountPath string) error {
+	mountPath = strings.Trim(mountPath, "/")
+	for role, policy := range map[string]string{
+		s.SnapshotRole: fmt.Sprintf(SnapshotPolicyTemplate, mountPath),
+		s.RestoreRole:  fmt.Sprintf(RestorePolicyTemplate, mountPath),
+	} {
+		if err := client.Sys().PutPolicy(role, policy); err != nil {
+			return errors.Wrapf(err, "Failed to create policy %s", role)
+		}
+
+		if _, err := client.Logical().Write("/auth/kubernetes/role/"+role, map[string]interface{}{
+			"name":                             role,
+			"bound_service_account_names":      []string{s.ServiceAccount, NewBackup().ServiceAccount},
+			"bound_service_account_namespaces": []string{s.Namespace},
+			"policies":                         []string{role},
+			"token_ttl":                        "15m",
+		}); err != nil {
+			return errors.Wrapf(err, "Failed to configure Kubernetes authentication role %s", role)
+		}
+	}
+
+	return nil
+}
+
+// Apply creates or updates the snapshot cron job, or removes it when snapshots are disabled
+func (s VaultSnapshot) Apply(ctx context.Context, modela *managementv1.Modela) error {
+	if !s.IsEnabled(*modela) {
+		return kube.DeleteCronJob(s.Namespace, s.Name)
+	}
+
+	if err := s.prepare(modela); err != nil {
+		return err
+	}
+
+	spec := modela.Spec.Vault.HA.Snapshots
+	snapshot := s.vaultContainer(modela, "snapshot", snapshotScript, s.SnapshotRole)
+	var podSpec v1.PodSpec
+	if spec.Destination == managementv1.VaultSnapshotPersistentVolume {
+		podSpec = s.podSpec(modela, []v1.Container{snapshot}, v1.Container{
+			Name:    "prune",
+			Image:   s.VaultImage,
+			Command: []string{"sh", "-c", pruneVolumeScript},
+			Env:     []v1.EnvVar{{Name: "RETENTION", Value: fmt.Sprint(s.retention(modela))}},
+		})
+	} else {
+		podSpec = s.podSpec(modela, []v1.Container{snapshot}, s.minioContainer(modela, "upload", uploadScript))
+	}
+
+	log.FromContext(ctx).Info("Applying Vault snapshot schedule", "schedule", spec.Schedule, "destination", spec.Destination)
+	return kube.ApplyCronJob(&batchv1.CronJob{
+		ObjectMeta: s.objectMeta(modela, s.Name),
+		Spec: batchv1.CronJobSpec{
+			Schedule:          spec.Schedule,
+			ConcurrencyPolicy: batchv1.ForbidConcurrent,
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					BackoffLimit: util.Int32Ptr(2),
+					Template:     v1.PodTemplateSpec{Spec: podSpec},
+				},
+			},
+		},
+	})
+}
+
+// Restore restores a snapshot into Vault through a job. It returns true once the job has completed successfully.
+func (s VaultSnapshot) Restore(ctx context.Context, modela *managementv1.Modela, snapshot string) (bool, error) {
+	name := s.restoreJobName(snapshot)
+	job, err := kube.GetJob(s.Namespace, name)
+	if err != nil {
+		return false, err
+	}
+
+	if job != nil {
+		if finished, condition := kube.JobFinished(job); !finished {
+			return false, nil
+		} else if condition == batchv1.JobFailed {
+			return false, errors.Errorf("Failed to restore Vault snapshot %s, see the logs of job %s", snapshot, name)
+		}
+
+		// The token of the operator may not exist in the restored storage
+		if client, err := vault.GetAuthenticatedClient(modela); err == nil {
+			vault.InvalidateAuthenticatedClient(client)
+		}
+		return true, nil
+	}
+
+	if err := s.prepare(modela); err != nil {
+		return false, err
+	}
+
+	restore := s.vaultContainer(modela, "restore", restoreScript, s.RestoreRole)
+	restore.Env = append(restore.Env, v1.EnvVar{Name: "SNAPSHOT", Value: snapshot})
+	var initContainers []v1.Container
+	if modela.Spec.Vault.HA.Snapshots.Destination != managementv1.VaultSnapshotPersistentVolume {
+		download := s.minioContainer(modela, "download", downloadScript)
+		download.Env = append(download.Env, v1.EnvVar{Name: "SNAPSHOT", Value: snapshot})
+		initContainers = append(initContainers, download)
+	}
+
+	log.FromContext(ctx).Info("Restoring Vault snapshot", "snapshot", snapshot)
+	return false, kube.CreateJob(&batchv1.Job{
+		ObjectMeta: s.objectMeta(modela, name),
+		Spec: batchv1.JobSpec{
+			BackoffLimit: util.Int32Ptr(0),
+			Template:     v1.PodTemplateSpec{Spec: s.podSpec(modela, initContainers, restore)},
+		},
+	})
+}
+
+// prepare creates the service account of the jobs, and the persistent volume claim which stores snapshots
+func (s VaultSnapshot) prepare(modela *managementv1.Modela) error {
+	labels := map[string]string{"management.modela.ai/operator": modela.Name}
+	if err := kube.CreateServiceAccount(s.Namespace, s.ServiceAccount, labels); err != nil {
+		return err
+	}
+
+	spec := modela.Spec.Vault.HA.Snapshots
+	if spec.Destination != managementv1.VaultSnapshotPersistentVolume {
+		return nil
+	}
+
+	size := spec.StorageSize
+	if size.IsZero() {
+		size = resource.MustParse("10Gi")
+	}
+	return kube.CreatePersistentVolumeClaim(&v1.PersistentVolumeClaim{
+		ObjectMeta: s.objectMeta(modela, s.Name),
+		Spec: v1.PersistentVolumeClaimSpec{
+			AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			StorageClassName: spec.StorageClassName,
+			Resources:        v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceStorage: size}},
+		},
+	})
+}
+
+func (s VaultSnapshot) podSpec(modela *managementv1.Modela, initContainers []v1.Container, container v1.Container) v1.PodSpec {
+	volume := v1.Volume{Name: "snapshots", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}}
+	if modela.Spec.Vault.HA.Snapshots.Destination == managementv1.VaultSnapshotPersistentVolume {
+		volume.VolumeSource = v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: s.Name}}
+	}
+
+	volumes := []v1.Volume{volume}
+	if modela.Spec.Vault.TLS.Enabled {
+		volumes = append(volumes, v1.Volume{Name: "vault-tls", VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{SecretName: vault.TLSSecretName},
+		}})
+	}
+
+	return v1.PodSpec{
+		ServiceAccountName: s.ServiceAccount,
+		RestartPolicy:      v1.RestartPolicyNever,
+		InitContainers:     initContainers,
+		Containers:         []v1.Container{container},
+		Volumes:            volumes,
+	}
+}
+
+func (s VaultSnapshot) vaultContainer(modela *managementv1.Modela, name string, script string, role string) v1.Container {
+	// The active service only exists in high-availability mode
+	address := vault.Address(modela)
+	if modela.Spec.Vault.HA.Enabled {
+		address = "http://modela-vault-active.modela-system.svc:8200"
+	}
+	mounts := []v1.VolumeMount{{Name: "snapshots", MountPath: "/snapshots"}}
+	env := []v1.EnvVar{
+		{Name: "VAULT_ROLE", Value: role},
+		{Name: "VAULT_MOUNT_PATH", Value: strings.Trim(modela.Spec.Vault.MountPath, "/")},
+	}
+	if modela.Spec.Vault.TLS.Enabled {
+		if modela.Spec.Vault.HA.Enabled {
+			address = "https://modela-vault-active.modela-system.svc:8200"
+		}
+		mounts = append(mounts, v1.VolumeMount{Name: "vault-tls", MountPath: "/vault/tls", ReadOnly: true})
+		env = append(env, v1.EnvVar{Name: "VAULT_CACERT", Value: "/vault/tls/ca.crt"})
+	}
+	env = append(env, v1.EnvVar{Name: "VAULT_ADDR", Value: address})
+
+	return v1.Container{
+		Name:         name,
+		Image:        s.VaultImage,
+		Command:      []string{"sh", "-c", script},
+		Env:          env,
+		VolumeMounts: mounts,
+	}
+}
+
+func (s VaultSnapshot) minioContainer(modela *managementv1.Modela, name string, script string) v1.Container {
+	minioSecret := func(key string) *v1.EnvVarSource {
+		return &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: "modela-storage-minio"},
+			Key:                  key,
+		}}
+	}
+
+	bucket := modela.Spec.Vault.HA.Snapshots.Bucket
+	if bucket == "" {
+		bucket = "vault-snapshots"
+	}
+
+	return v1.Container{
+		Name:    name,
+		Image:   s.MinioImage,
+		Command: []string{"bash", "-c", script},
+		Env: []v1.EnvVar{
+			{Name: "BUCKET", Value: bucket},
+			{Name: "RETENTION", Value: fmt.Sprint(s.retention(modela))},
+			{Name: "MINIO_ROOT_USER", ValueFrom: minioSecret("root-user")},
+			{Name: "MINIO_ROOT_PASSWORD", ValueFrom: minioSecret("root-password")},
+		},
+		VolumeMounts: []v1.VolumeMount{{Name: "snapshots", MountPath: "/snapshots"}},
+	}
+}
+
+func (s VaultSnapshot) objectMeta(modela *managementv1.Modela, name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: s.Namespace,
+		Labels:    map[string]string{"management.modela.ai/operator": modela.Name},
+	}
+}
+
+func (s VaultSnapshot) retention(modela *managementv1.Modela) int {
+	if modela.Spec.Vault.HA.Snapshots.Retention < 1 {
+		return 7
+	}
+	return modela.Spec.Vault.HA.Snapshots.Retention
+}
+
+var invalidJobNameCharacters = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// restoreJobName returns the name of the job which restores a snapshot, which is unique for each snapshot
+func (s VaultSnapshot) restoreJobName(snapshot string) string {
+	name := "modela-vault-restore-" + invalidJobNameCharacters.ReplaceAllString(strings.ToLower(snapshot), "-")
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return strings.TrimRight(name, "-")
+}
@@ -6,6 +6,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"os/exec"
 	"time"
 )
@@ -40,4 +41,30 @@ var _ = Describe("Vault installer", func() {
 		_ = port_forward.Process.Kill()
 
 	})
+	It("Should enable TLS on the Vault listener", func() {
+		modela := &v1alpha1.Modela{Spec: v1alpha1.ModelaSpec{Vault: v1alpha1.VaultSpec{TLS: v1alpha1.VaultTLSSpec{Enabled: true}}}}
+		values := map[string]interface{}{}
+		Expect(NewVault().applyServerConfig(values, modela)).To(Succeed())
+		Expect(NewVault().applyTLS(values)).To(Succeed())
+
+		config, _, _ := unstructured.NestedString(values, "server", "standalone", "config")
+		Expect(config).To(ContainSubstring(TLSListenerConfig))
+		tlsDisable, _, _ := unstructured.NestedBool(values, "global", "tlsDisable")
+		Expect(tlsDisable).To(BeFalse())
+		volumes, _, _ := unstructured.NestedSlice(values, "server", "extraVolumes")
+		Expect(volumes).To(HaveLen(1))
+	})
+
+	It("Should configure Raft storage in high-availability mode", func() {
+		modela := &v1alpha1.Modela{Spec: v1alpha1.ModelaSpec{Vault: v1alpha1.VaultSpec{HA: v1alpha1.VaultHASpec{Enabled: true, Replicas: 5}}}}
+		values := map[string]interface{}{}
+		Expect(NewVault().applyServerConfig(values, modela)).To(Succeed())
+
+		replicas, _, _ := unstructured.NestedInt64(values, "server", "ha", "replicas")
+		Expect(replicas).To(Equal(int64(5)))
+		config, _, _ := unstructured.NestedString(values, "server", "ha", "raft", "config")
+		Expect(config).To(ContainSubstring(RaftStorageConfig))
+		_, found, _ := unstructured.NestedString(values, "server", "standalone", "config")
+		Expect(found).To(BeFalse())
+	})
 })
@@ -29,6 +29,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"strings"
 	"sync"
 	"time"
 
@@ -39,7 +40,10 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
 	managementv1alpha1 "github.com/metaprov/modela-operator/api/v1alpha1"
@@ -100,10 +104,18 @@ func (r *ModelaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		// on deleted requests.
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	if restore, ok := modela.Annotations[managementv1alpha1.RestoreInProgressAnnotation]; ok {
+		// Removing the annotation once the restore has finished triggers a new reconciliation
+		logger.Info("Reconciliation is paused while Modela is restored", "restore", restore)
+		return ctrl.Result{}, nil
+	}
 	oldStatus := *modela.Status.DeepCopy()
 
 	result, err := r.Install(ctx, modela)
-	if err != nil {
+	if components.IsClientJobRunning(err) {
+		result, err = waitForClientJob(ctx, err), nil
+		goto updateStatus
+	} else if err != nil {
 		modela.Status.FailureMessage = util.StrPtr(err.Error())
 		modela.Status.Phase = managementv1alpha1.ModelaPhaseFailed
 		logger.Error(err, "failed to install Modela")
@@ -125,6 +137,16 @@ func (r *ModelaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		goto updateStatus
 	}
 
+	result, err = r.reconcilePlatformConfig(ctx, modela)
+	if err != nil || result.Requeue || !r.isStateEqual(modela.Status, oldStatus) {
+		goto updateStatus
+	}
+
+	result, err = r.reconcilePgvector(ctx, modela)
+	if err != nil || result.Requeue || !r.isStateEqual(modela.Status, oldStatus) {
+		goto updateStatus
+	}
+
 	result, err = r.reconcileControlPlane(ctx, modela)
 	if err != nil || result.Requeue || !r.isStateEqual(modela.Status, oldStatus) {
 		goto updateStatus
@@ -140,7 +162,25 @@ func (r *ModelaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		goto updateStatus
 	}
 
+	result, err = r.reconcileVaultSnapshots(ctx, modela)
+	if err != nil || result.Requeue || !r.isStateEqual(modela.Status, oldStatus) {
+		goto updateStatus
+	}
+
+	result, err = r.reconcileBackupSchedule(ctx, modela)
+	if err != nil || result.Requeue || !r.isStateEqual(modela.Status, oldStatus) {
+		goto updateStatus
+	}
+
+	result, err = r.reconcileSecretRotation(ctx, modela)
+	if err != nil || result.Requeue || !r.isStateEqual(modela.Status, oldStatus) {
+		goto updateStatus
+	}
+
 updateStatus:
+	if components.IsClientJobRunning(err) {
+		result, err = waitForClientJob(ctx, err), nil
+	}
 	statusResult, statusErr := r.updateStatus(ctx, oldStatus, *modela)
 	if statusResult.Requeue {
 		return statusResult, statusErr
@@ -149,6 +189,12 @@ updateStatus:
 	return result, err
 }
 
+// waitForClientJob requeues the reconciliation while the job of a client script runs against an external component
+func waitForClientJob(ctx context.Context, err error) ctrl.Result {
+	log.FromContext(ctx).Info(err.Error())
+	return ctrl.Result{Requeue: true, RequeueAfter: 5 * time.Second}
+}
+
 func (r ModelaReconciler) updateStatus(ctx context.Context, oldStatus managementv1alpha1.ModelaStatus, modela managementv1alpha1.Modela) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 	if !r.isStateEqual(modela.Status, oldStatus) {
@@ -189,13 +235,36 @@ func (r ModelaReconciler) isStateEqual(old managementv1alpha1.ModelaStatus, new
 		reflect.DeepEqual(old.FailureMessage, new.FailureMessage) &&
 		reflect.DeepEqual(old.LicenseToken, new.LicenseToken) &&
 		reflect.DeepEqual(old.Conditions, new.Conditions) &&
-		reflect.DeepEqual(old.Tenants, new.Tenants)
+		reflect.DeepEqual(old.Tenants, new.Tenants) &&
+		reflect.DeepEqual(old.SecretRotations, new.SecretRotations) &&
+		old.RestoredVaultSnapshot == new.RestoredVaultSnapshot &&
+		reflect.DeepEqual(old.RetainedTenants, new.RetainedTenants) &&
+		reflect.DeepEqual(old.SuspendedTenants, new.SuspendedTenants) &&
+		reflect.DeepEqual(old.TenantAdminPasswords, new.TenantAdminPasswords) &&
+		reflect.DeepEqual(old.TenantQuotas, new.TenantQuotas) &&
+		reflect.DeepEqual(old.TenantConnections, new.TenantConnections) &&
+		old.PlatformConfigVersion == new.PlatformConfigVersion &&
+		reflect.DeepEqual(old.Pgvector, new.Pgvector) &&
+		old.SchemaVersion == new.SchemaVersion &&
+		reflect.DeepEqual(old.SchemaMigration, new.SchemaMigration) &&
+		reflect.DeepEqual(old.DatabaseHA, new.DatabaseHA)
 
 }
 
 func (r *ModelaReconciler) Install(ctx context.Context, modela *managementv1alpha1.Modela) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
+	if result, err := r.reconcileExternalServices(ctx, modela); err != nil || result.Requeue {
+		return result, err
+	}
+
+	// The services and connections of the databases depend on their topology, which cannot be changed once they
+	// are installed
+	if installed := modela.Status.DatabaseHA; installed != nil && *installed != modela.Spec.Database.HA.Enabled {
+		return ctrl.Result{}, fmt.Errorf("spec.database.ha.enabled cannot be changed to %t once the databases are installed",
+			modela.Spec.Database.HA.Enabled)
+	}
+
 	var wg sync.WaitGroup
 	var componentsInstalled sync.Map
 	var componentList = []ModelaComponent{
@@ -206,6 +275,7 @@ func (r *ModelaReconciler) Install(ctx context.Context, modela *managementv1alph
 		components.NewPrometheus(),
 		components.NewPostgresDatabase(),
 		components.NewMongoDatabase(),
+		components.NewOnlineStore(),
 		components.NewNginx(),
 		components.NewVault(),
 	}
@@ -246,12 +316,21 @@ func (r *ModelaReconciler) Install(ctx context.Context, modela *managementv1alph
 		}
 	}
 
-	vault := components.NewVault()
-	if err := vault.ConfigureVault(ctx, modela); err != nil {
-		return ctrl.Result{
-			Requeue:      true,
-			RequeueAfter: 5 * time.Second,
-		}, err
+	if modela.Status.DatabaseHA == nil &&
+		(components.NewPostgresDatabase().IsEnabled(*modela) || components.NewMongoDatabase().IsEnabled(*modela)) {
+		enabled := modela.Spec.Database.HA.Enabled
+		modela.Status.DatabaseHA = &enabled
+	}
+
+	// Vault is only configured when it stores the secrets generated by the operator
+	if store := modela.Spec.SecretStore.Type; store == "" || store == managementv1.SecretStoreVault {
+		vault := components.NewVault()
+		if err := vault.ConfigureVault(ctx, modela); err != nil {
+			return ctrl.Result{
+				Requeue:      true,
+				RequeueAfter: 5 * time.Second,
+			}, err
+		}
 	}
 
 	modelaSystem := components.NewModelaSystem(modela.Spec.Distribution)
@@ -304,10 +383,14 @@ func (r *ModelaReconciler) Install(ctx context.Context, modela *managementv1alph
 	}
 
 	if modela.Spec.Distribution != modela.Status.InstalledVersion {
+		if result, err := r.reconcileSchemaMigration(ctx, modela); err != nil || result.Requeue {
+			return result, err
+		}
+
 		var err error
 		logger.Info("Applying new distribution", "version", modelaSystem.ModelaVersion)
 		err = modelaSystem.InstallNewVersion(ctx, modela)
-		if err != nil && modela.Spec.OnlineStore.Install {
+		if err == nil && modela.Spec.OnlineStore.Install {
 			onlineStore := components.NewOnlineStore()
 			err = onlineStore.InstallNewVersion(ctx, modela)
 		}
@@ -319,10 +402,122 @@ func (r *ModelaReconciler) Install(ctx context.Context, modela *managementv1alph
 		}
 	}
 
+	if result, err := r.reconcileDatabaseReplication(ctx, modela); err != nil || result.Requeue {
+		return result, err
+	}
+
 	modela.Status.Phase = managementv1alpha1.ModelaPhaseReady
 	return ctrl.Result{}, err
 }
 
+// reconcileSchemaMigration migrates the schemas of the databases to the distribution of the spec before its images
+// are rolled out. The upgrade is blocked while the migration job runs, and until a failed job is deleted.
+func (r *ModelaReconciler) reconcileSchemaMigration(ctx context.Context, modela *managementv1alpha1.Modela) (ctrl.Result, error) {
+	status := modela.Status.SchemaMigration
+	if status != nil && status.Distribution == modela.Spec.Distribution && status.CompletionTime != nil {
+		return ctrl.Result{}, nil
+	}
+
+	migration := components.NewSchemaMigration()
+	if status == nil || status.Distribution != modela.Spec.Distribution {
+		image, err := migration.Image(modela.Spec.Distribution)
+		if err != nil {
+			return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, err
+		}
+		now := metav1.Now()
+		status = &managementv1alpha1.SchemaMigrationStatus{
+			Distribution:     modela.Spec.Distribution,
+			FromDistribution: modela.Status.InstalledVersion,
+			Image:            image,
+			Job:              migration.JobName(modela.Spec.Distribution),
+			StartTime:        &now,
+		}
+		modela.Status.SchemaMigration = status
+	}
+
+	version, succeeded, err := migration.Run(ctx, modela, status)
+	var failed *components.JobFailedError
+	if errors.As(err, &failed) {
+		status.FailureMessage = failed.Message
+		modela.SetCondition(managementv1alpha1.SchemaMigrated, managementv1alpha1.ConditionFalse, "MigrationFailed",
+			fmt.Sprintf("%s. The upgrade to %s is blocked until the job is deleted, which retries the migration", failed.Message, status.Distribution))
+		modela.Status.Phase = managementv1alpha1.ModelaPhaseFailed
+		return ctrl.Result{Requeue: true, RequeueAfter: time.Minute}, nil
+	} else if err != nil {
+		return ctrl.Result{Requeue: true, RequeueAfter: 10 * time.Second}, err
+	} else if !succeeded {
+		if status.FailureMessage != "" {
+			// The failed job was deleted, and the migration is retried by a new job
+			now := metav1.Now()
+			status.StartTime, status.FailureMessage = &now, ""
+		}
+		message := fmt.Sprintf("Migrating the schemas from %s to %s", status.FromDistribution, status.Distribution)
+		if waiting, err := migration.Waiting(status.Job); err != nil {
+			return ctrl.Result{Requeue: true, RequeueAfter: 10 * time.Second}, err
+		} else if waiting != "" {
+			message = fmt.Sprintf("%s, the job %s is waiting: %s", message, status.Job, waiting)
+		}
+		modela.SetCondition(managementv1alpha1.SchemaMigrated, managementv1alpha1.ConditionUnknown, "Migrating", message)
+		modela.Status.Phase = managementv1alpha1.ModelaPhaseMigratingSchema
+		return ctrl.Result{Requeue: true, RequeueAfter: 10 * time.Second}, nil
+	}
+
+	now := metav1.Now()
+	status.CompletionTime = &now
+	modela.Status.SchemaVersion = version
+	modela.SetCondition(managementv1alpha1.SchemaMigrated, managementv1alpha1.ConditionTrue, "Migrated",
+		fmt.Sprintf("The schemas were migrated to version %s", version))
+	return ctrl.Result{}, nil
+}
+
+// reconcileDatabaseReplication applies the number of replicas to the highly available databases and checks the
+// health of their replication. Modela is degraded until every replica is healthy.
+func (r *ModelaReconciler) reconcileDatabaseReplication(ctx context.Context, modela *managementv1alpha1.Modela) (ctrl.Result, error) {
+	var databases []replicatedDatabase
+	for _, database := range []replicatedDatabase{components.NewPostgresDatabase(), components.NewMongoDatabase()} {
+		if installed, err := database.Installed(ctx); database.IsEnabled(*modela) && installed && err == nil {
+			databases = append(databases, database)
+		}
+	}
+	if !modela.Spec.Database.HA.Enabled || len(databases) == 0 {
+		if modela.GetCondition(managementv1alpha1.DatabaseReplicationHealthy) != nil {
+			modela.SetCondition(managementv1alpha1.DatabaseReplicationHealthy, managementv1alpha1.ConditionFalse, "Disabled", "")
+		}
+		return ctrl.Result{}, nil
+	}
+
+	var problems []string
+	for _, database := range databases {
+		if scaled, err := database.ScaleReplicas(ctx, modela); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to scale database", "component", reflect.TypeOf(database).Elem().Name())
+			return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, err
+		} else if scaled {
+			problems = append(problems, reflect.TypeOf(database).Elem().Name()+" is being scaled")
+			continue
+		}
+
+		problem, err := database.ReplicationHealth(ctx, modela)
+		if components.IsClientJobRunning(err) {
+			return waitForClientJob(ctx, err), nil
+		} else if err != nil {
+			modela.SetCondition(managementv1alpha1.DatabaseReplicationHealthy, managementv1alpha1.ConditionUnknown, "Unreachable", err.Error())
+			modela.Status.Phase = managementv1alpha1.ModelaPhaseDegraded
+			return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, nil
+		} else if problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+
+	if len(problems) > 0 {
+		modela.SetCondition(managementv1alpha1.DatabaseReplicationHealthy, managementv1alpha1.ConditionFalse, "ReplicationDegraded",
+			strings.Join(problems, "; "))
+		modela.Status.Phase = managementv1alpha1.ModelaPhaseDegraded
+		return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, nil
+	}
+	modela.SetCondition(managementv1alpha1.DatabaseReplicationHealthy, managementv1alpha1.ConditionTrue, "Replicating", "")
+	return ctrl.Result{}, nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ModelaReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).Named("modela-controller").
@@ -336,9 +531,64 @@ func (r *ModelaReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&rbacv1.ClusterRoleBinding{}).
 		Owns(&rbacv1.RoleBinding{}).
 		Owns(&networkingv1.Ingress{}).
+		Owns(&managementv1alpha1.ModelaBackup{}).
+		Watches(&source.Kind{Type: &v1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.mapSecret)).
 		Complete(r)
 }
 
+// mapSecret enqueues the Modela resources with a tenant whose passwords are stored in a Secret, or whose tenant
+// connections use the credentials of a system component stored in a Secret
+func (r *ModelaReconciler) mapSecret(obj client.Object) []reconcile.Request {
+	var modelas managementv1.ModelaList
+	if err := r.List(context.Background(), &modelas); err != nil {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, modela := range modelas.Items {
+		if len(modela.Spec.Tenants) > 0 && isConnectionSecret(&modela, obj) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&modela)})
+			continue
+		}
+		if modela.Namespace != obj.GetNamespace() {
+			continue
+		}
+		for _, tenant := range modela.Spec.Tenants {
+			if isPasswordSecret(tenant.Name, tenant.TenantConfig, obj.GetName()) {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&modela)})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+// isConnectionSecret returns true if a Secret holds the credentials of a system component used by tenant connections
+func isConnectionSecret(modela *managementv1.Modela, secret client.Object) bool {
+	if secret.GetNamespace() != "modela-system" {
+		return false
+	}
+	for _, name := range components.ConnectionSecrets(modela) {
+		if name == secret.GetName() {
+			return true
+		}
+	}
+	return false
+}
+
+// isPasswordSecret returns true if a Secret holds the password of the admin account or another account of a tenant
+func isPasswordSecret(tenant string, config managementv1.TenantConfig, secret string) bool {
+	for _, account := range config.Accounts {
+		if account.PasswordSecretRef != nil && account.PasswordSecretRef.Name == secret {
+			return true
+		}
+	}
+	if config.AdminPasswordSecretRef != nil {
+		return config.AdminPasswordSecretRef.Name == secret
+	}
+	return config.AdminPassword == nil && components.AdminPasswordSecretName(tenant) == secret
+}
+
 func (r *ModelaReconciler) updateFrontendConfig(configMap v1.ConfigMap) error {
 	var frontendDeployment appsv1.Deployment
 
@@ -473,30 +723,246 @@ func (r *ModelaReconciler) reconcileTenants(ctx context.Context, modela *managem
 		}
 	}
 
-	// Uninstall inactive tenants
-	for index, tenant := range modela.Status.Tenants {
-		if _, ok := tenants[tenant]; !ok {
-			// The tenant no longer exists in the spec, uninstall
-			tenant := components.NewTenant(tenant)
-			if result, _ := r.updatePhase(ctx, modela, managementv1alpha1.ModelaPhaseUninstalling); result.Requeue {
-				return result, nil
+	// Keep the admin passwords, accounts, labs, serving sites, quotas, connections, deletion policy and suspension
+	// of installed tenants in sync with their spec and the credentials of the system components
+	var wasSuspended = make(map[string]bool)
+	for _, tenant := range modela.Status.SuspendedTenants {
+		wasSuspended[tenant] = true
+	}
+
+	var suspended []string
+	var passwords []managementv1.TenantAdminPasswordStatus
+	var quotas []managementv1.TenantQuotaStatus
+	var connections []managementv1.TenantConnectionStatus
+	var degraded []string
+	for _, tenantSpec := range modela.Spec.Tenants {
+		tenant := components.NewTenant(tenantSpec.Name)
+		password, err := tenant.ApplyAdminPassword(ctx, modela, tenantSpec.TenantConfig, tenantAdminPassword(modela, tenant.Name))
+		if err != nil {
+			logger.Error(err, "Failed to apply tenant admin password", "name", tenant.Name)
+			return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, err
+		}
+		password.Tenant = tenant.Name
+		passwords = append(passwords, *password)
+
+		if err := tenant.ApplyAccounts(ctx, modela, tenantSpec.TenantConfig); err != nil {
+			logger.Error(err, "Failed to apply tenant accounts", "name", tenant.Name)
+			return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, err
+		}
+
+		if err := tenant.ApplyWorkspaces(ctx, modela, tenantSpec.TenantConfig); err != nil {
+			logger.Error(err, "Failed to apply tenant labs and serving sites", "name", tenant.Name)
+			return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, err
+		}
+
+		if err := tenant.ApplyQuota(ctx, modela, tenantSpec.Quota); err != nil {
+			logger.Error(err, "Failed to apply tenant quota", "name", tenant.Name)
+			return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, err
+		}
+
+		if err := tenant.ApplyDeletionPolicy(ctx, tenantSpec.DeletionPolicy); err != nil {
+			logger.Error(err, "Failed to apply tenant deletion policy", "name", tenant.Name)
+			return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, err
+		}
+
+		connection, err := tenant.SyncConnections(ctx, modela, tenantConnections(modela, tenant.Name))
+		if err != nil {
+			logger.Error(err, "Failed to sync tenant connections", "name", tenant.Name)
+			return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, err
+		}
+		connection.Tenant = tenant.Name
+		connections = append(connections, *connection)
+		if len(connection.Missing) > 0 {
+			degraded = append(degraded, fmt.Sprintf("%s (%s)", tenant.Name, strings.Join(connection.Missing, ", ")))
+		}
+
+		if tenantSpec.Suspended {
+			if err := tenant.Suspend(ctx, modela); err != nil {
+				logger.Error(err, "Failed to suspend tenant", "name", tenant.Name)
+				return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, err
 			}
-			err := tenant.Uninstall(ctx, modela)
-			if err != nil {
-				logger.Error(err, "Failed to uninstall tenant", "name", tenant.Name)
-				return ctrl.Result{
-					Requeue:      true,
-					RequeueAfter: 5 * time.Minute,
-				}, err
+			suspended = append(suspended, tenant.Name)
+		} else if wasSuspended[tenant.Name] {
+			if err := tenant.Resume(ctx, modela); err != nil {
+				logger.Error(err, "Failed to resume tenant", "name", tenant.Name)
+				return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, err
 			}
-			// Remove the tenant from the status
-			modela.Status.Tenants = append(modela.Status.Tenants[:index], modela.Status.Tenants[index+1:]...)
 		}
+
+		if status, err := tenant.QuotaStatus(ctx); err != nil {
+			return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, err
+		} else if status != nil {
+			status.Tenant = tenant.Name
+			quotas = append(quotas, *status)
+		}
+	}
+	modela.Status.SuspendedTenants = suspended
+	modela.Status.TenantAdminPasswords = passwords
+	modela.Status.TenantQuotas = quotas
+	modela.Status.TenantConnections = connections
+	if len(degraded) > 0 {
+		modela.SetCondition(managementv1.TenantsDegraded, managementv1.ConditionTrue, "ConnectionsMissing",
+			"Tenants with missing connections: "+strings.Join(degraded, "; "))
+	} else {
+		modela.SetCondition(managementv1.TenantsDegraded, managementv1.ConditionFalse, "ConnectionsReady", "")
+	}
+
+	// Remove inactive tenants. The namespace of a removed tenant is only deleted when its deletion policy is Delete
+	// or its deletion is confirmed, and is otherwise retained for the grace period.
+	confirmed := make(map[string]bool)
+	for _, tenant := range strings.Split(modela.Annotations[managementv1.ConfirmTenantDeletionAnnotation], ",") {
+		confirmed[strings.TrimSpace(tenant)] = true
+	}
+
+	var installed []string
+	for _, name := range modela.Status.Tenants {
+		if _, ok := tenants[name]; ok {
+			installed = append(installed, name)
+			continue
+		}
+
+		// The tenant no longer exists in the spec
+		tenant := components.NewTenant(name)
+		if result, _ := r.updatePhase(ctx, modela, managementv1alpha1.ModelaPhaseUninstalling); result.Requeue {
+			return result, nil
+		}
+		if _, err := tenant.Remove(ctx, modela, confirmed[name]); err != nil {
+			logger.Error(err, "Failed to remove tenant", "name", tenant.Name)
+			return ctrl.Result{
+				Requeue:      true,
+				RequeueAfter: 5 * time.Minute,
+			}, err
+		}
+	}
+	modela.Status.Tenants = installed
+
+	retained, err := components.ExpireRetainedTenants(ctx, modela)
+	if err != nil {
+		logger.Error(err, "Failed to expire retained tenants")
+		return ctrl.Result{Requeue: true, RequeueAfter: 5 * time.Minute}, err
+	}
+	modela.Status.RetainedTenants = retained
+
+	return ctrl.Result{}, nil
+}
+
+// tenantAdminPassword returns the admin password applied to a tenant of the Modela resource, if any
+func tenantAdminPassword(modela *managementv1.Modela, tenant string) *managementv1.TenantAdminPasswordStatus {
+	for i := range modela.Status.TenantAdminPasswords {
+		if modela.Status.TenantAdminPasswords[i].Tenant == tenant {
+			return &modela.Status.TenantAdminPasswords[i]
+		}
+	}
+	return nil
+}
+
+// tenantConnections returns the state of the connections of a tenant of the Modela resource, if any
+func tenantConnections(modela *managementv1.Modela, tenant string) *managementv1.TenantConnectionStatus {
+	for i := range modela.Status.TenantConnections {
+		if modela.Status.TenantConnections[i].Tenant == tenant {
+			return &modela.Status.TenantConnections[i]
+		}
+	}
+	return nil
+}
+
+// reconcileVaultSnapshots applies the snapshot schedule of Vault, and restores the snapshot requested through
+// the spec once for each new snapshot name
+func (r *ModelaReconciler) reconcileVaultSnapshots(ctx context.Context, modela *managementv1.Modela) (ctrl.Result, error) {
+	snapshots := components.NewVaultSnapshot()
+	if err := snapshots.Apply(ctx, modela); err != nil {
+		return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, err
+	}
+
+	snapshot := modela.Spec.Vault.HA.RestoreSnapshot
+	if !modela.Spec.Vault.Install || !modela.Spec.Vault.HA.Enabled || snapshot == "" ||
+		snapshot == modela.Status.RestoredVaultSnapshot {
+		return ctrl.Result{}, nil
 	}
 
+	restored, err := snapshots.Restore(ctx, modela, snapshot)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to restore Vault snapshot", "snapshot", snapshot)
+		return ctrl.Result{Requeue: true, RequeueAfter: time.Minute}, err
+	} else if !restored {
+		return ctrl.Result{Requeue: true, RequeueAfter: 10 * time.Second}, nil
+	}
+
+	modela.Status.RestoredVaultSnapshot = snapshot
 	return ctrl.Result{}, nil
 }
 
+// reconcileBackupSchedule applies the backup policy of the spec to the ModelaBackup owned by the Modela resource,
+// or removes the ModelaBackup when no policy is set. Removing it deletes its runs, but not their data.
+func (r *ModelaReconciler) reconcileBackupSchedule(ctx context.Context, modela *managementv1.Modela) (ctrl.Result, error) {
+	backup := &managementv1.ModelaBackup{ObjectMeta: metav1.ObjectMeta{Name: modela.Name, Namespace: modela.Namespace}}
+	if modela.Spec.Backup == nil {
+		if err := r.Get(ctx, client.ObjectKeyFromObject(backup), backup); err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		if !metav1.IsControlledBy(backup, modela) {
+			return ctrl.Result{}, nil
+		}
+		log.FromContext(ctx).Info("Removing backup schedule", "name", backup.Name)
+		return ctrl.Result{}, client.IgnoreNotFound(r.Delete(ctx, backup))
+	}
+
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, backup, func() error {
+		backup.Spec.ModelaRef = managementv1.ModelaReference{Name: modela.Name, Namespace: modela.Namespace}
+		backup.Spec.BackupPolicySpec = *modela.Spec.Backup
+		return controllerutil.SetControllerReference(modela, backup, r.Scheme)
+	}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to apply backup schedule")
+		return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// reconcileSecretRotation rotates each credential whose rotation interval has elapsed since its last rotation.
+// Credentials seen for the first time are recorded as rotated, as they were generated during installation.
+func (r *ModelaReconciler) reconcileSecretRotation(ctx context.Context, modela *managementv1.Modela) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	rotation := components.NewSecretRotation()
+
+	var requeueAfter time.Duration
+	for _, interval := range rotation.Intervals(*modela) {
+		now := metav1.Now()
+		var status *managementv1.SecretRotationStatus
+		for i := range modela.Status.SecretRotations {
+			if modela.Status.SecretRotations[i].Name == interval.Name {
+				status = &modela.Status.SecretRotations[i]
+			}
+		}
+
+		if status == nil {
+			modela.Status.SecretRotations = append(modela.Status.SecretRotations, managementv1.SecretRotationStatus{
+				Name:             interval.Name,
+				LastRotationTime: now,
+			})
+			status = &modela.Status.SecretRotations[len(modela.Status.SecretRotations)-1]
+		} else if !now.Before(&metav1.Time{Time: status.LastRotationTime.Add(interval.Interval.Duration)}) {
+			tenants, err := r.hostedTenants(ctx, modela)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if err := rotation.Rotate(ctx, modela, tenants, interval.Name); components.IsClientJobRunning(err) {
+				return waitForClientJob(ctx, err), nil
+			} else if err != nil {
+				logger.Error(err, "Failed to rotate credential", "name", interval.Name)
+				return ctrl.Result{Requeue: true, RequeueAfter: time.Minute}, err
+			}
+			status.LastRotationTime = now
+		}
+
+		next := status.LastRotationTime.Add(interval.Interval.Duration).Sub(now.Time)
+		if requeueAfter == 0 || next < requeueAfter {
+			requeueAfter = next
+		}
+	}
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
 // ModelaComponent defines the interface for system components that can be reconciled
 type ModelaComponent interface {
 	IsEnabled(modela managementv1.Modela) bool
@@ -508,6 +974,13 @@ type ModelaComponent interface {
 	GetInstallPhase() managementv1alpha1.ModelaPhase
 }
 
+// replicatedDatabase is a database component which can be installed in a highly available topology
+type replicatedDatabase interface {
+	ModelaComponent
+	ScaleReplicas(ctx context.Context, modela *managementv1.Modela) (bool, error)
+	ReplicationHealth(ctx context.Context, modela *managementv1.Modela) (string, error)
+}
+
 func (r *ModelaReconciler) reconcileComponent(ctx context.Context, component ModelaComponent, installed bool, modela *managementv1.Modela) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
@@ -588,6 +1061,136 @@ func (r *ModelaReconciler) reconcileApiGateway(ctx context.Context, modela *mana
 	return ctrl.Result{}, nil
 }
 
+// reconcileExternalServices runs the preflight checks of the external databases, object storage and Redis, which
+// must pass before the components which depend on them are installed
+func (r *ModelaReconciler) reconcileExternalServices(ctx context.Context, modela *managementv1alpha1.Modela) (ctrl.Result, error) {
+	externalRedis := modela.Spec.OnlineStore.Install && modela.Spec.OnlineStore.External != nil
+	if len(components.ExternalServices(modela)) == 0 && !externalRedis {
+		return ctrl.Result{}, nil
+	}
+
+	err := components.PreflightExternalServices(ctx, modela)
+	if err == nil && externalRedis {
+		err = components.NewOnlineStore().CheckExternalRedis(ctx, modela)
+	}
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Preflight check of external service failed")
+		modela.SetCondition(managementv1alpha1.ExternalServicesReachable, managementv1alpha1.ConditionFalse, "PreflightFailed", err.Error())
+		return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, nil
+	}
+
+	modela.SetCondition(managementv1alpha1.ExternalServicesReachable, managementv1alpha1.ConditionTrue, "PreflightPassed", "")
+	return ctrl.Result{}, nil
+}
+
+// reconcilePlatformConfig applies the platform configuration to the modela-config ConfigMap. An invalid
+// configuration is reported through the PlatformConfigured condition, and is not retried until the spec changes.
+func (r *ModelaReconciler) reconcilePlatformConfig(ctx context.Context, modela *managementv1alpha1.Modela) (ctrl.Result, error) {
+	if _, err := components.PlatformConfig(modela); err != nil {
+		modela.SetCondition(managementv1alpha1.PlatformConfigured, managementv1alpha1.ConditionFalse, "InvalidConfig", err.Error())
+		return ctrl.Result{}, nil
+	}
+
+	version, err := components.NewModelaSystem(modela.Spec.Distribution).ApplyPlatformConfig(ctx, modela)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to apply the platform configuration")
+		modela.SetCondition(managementv1alpha1.PlatformConfigured, managementv1alpha1.ConditionFalse, "Failed", err.Error())
+		return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, err
+	}
+
+	modela.Status.PlatformConfigVersion = version
+	modela.SetCondition(managementv1alpha1.PlatformConfigured, managementv1alpha1.ConditionTrue, "Applied", "")
+	return ctrl.Result{}, nil
+}
+
+// reconcilePgvector creates the pgvector extension in the databases of the tenants and verifies its version. The
+// bundled Postgres release is upgraded first when the pgvector image of the spec changes. The extension is
+// bootstrapped again when the image or the tenants change, or until the PgvectorReady condition is true.
+func (r *ModelaReconciler) reconcilePgvector(ctx context.Context, modela *managementv1alpha1.Modela) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	if !modela.Spec.Database.InstallPgvector {
+		modela.Status.Pgvector = nil
+		if modela.GetCondition(managementv1alpha1.PgvectorReady) != nil {
+			modela.SetCondition(managementv1alpha1.PgvectorReady, managementv1alpha1.ConditionFalse, "Disabled", "")
+		}
+		return ctrl.Result{}, nil
+	}
+
+	postgres := components.NewPostgresDatabase()
+	if postgres.IsEnabled(*modela) {
+		// The image of a Postgres which was not installed by Modela is not upgraded
+		var upgraded bool
+		if installed, err := postgres.Installed(ctx); installed && err == nil {
+			if upgraded, err = postgres.UpgradePgvectorImage(ctx, modela); err != nil {
+				logger.Error(err, "Failed to upgrade the pgvector image of Postgres")
+				modela.SetCondition(managementv1alpha1.PgvectorReady, managementv1alpha1.ConditionFalse, "UpgradeFailed", err.Error())
+				return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, err
+			}
+		}
+		if upgraded {
+			modela.SetCondition(managementv1alpha1.PgvectorReady, managementv1alpha1.ConditionUnknown, "Upgrading",
+				"Postgres is restarting with the image "+components.PgvectorImage(modela))
+			return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, nil
+		}
+		if ready, err := postgres.Ready(ctx); err != nil || !ready {
+			return ctrl.Result{Requeue: true, RequeueAfter: 10 * time.Second}, err
+		}
+	}
+
+	tenants, err := r.hostedTenants(ctx, modela)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	databases := components.PostgresDatabases(tenants)
+
+	// A server without the extension or with another version of it is not checked again until the image or the
+	// tenants change
+	status := modela.Status.Pgvector
+	condition := modela.GetCondition(managementv1alpha1.PgvectorReady)
+	if status != nil && condition != nil && reflect.DeepEqual(status.Databases, databases) &&
+		(!postgres.IsEnabled(*modela) || status.Image == components.PgvectorImage(modela)) &&
+		(condition.Status == managementv1alpha1.ConditionTrue || condition.Reason == components.PgvectorExtensionUnavailable ||
+			condition.Reason == components.PgvectorVersionMismatch) {
+		return ctrl.Result{}, nil
+	}
+
+	status, err = components.BootstrapPgvector(ctx, modela, databases)
+	if pgvectorErr, ok := err.(components.PgvectorError); ok {
+		modela.Status.Pgvector = status
+		modela.SetCondition(managementv1alpha1.PgvectorReady, managementv1alpha1.ConditionFalse, pgvectorErr.Reason, pgvectorErr.Message)
+		return ctrl.Result{}, nil
+	} else if components.IsClientJobRunning(err) {
+		return waitForClientJob(ctx, err), nil
+	} else if err != nil {
+		logger.Error(err, "Failed to bootstrap pgvector")
+		modela.SetCondition(managementv1alpha1.PgvectorReady, managementv1alpha1.ConditionFalse, "BootstrapFailed", err.Error())
+		return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, err
+	}
+
+	modela.Status.Pgvector = status
+	modela.SetCondition(managementv1alpha1.PgvectorReady, managementv1alpha1.ConditionTrue, "Bootstrapped",
+		fmt.Sprintf("pgvector %s is created in %d tenant databases", status.Version, len(status.Databases)))
+	return ctrl.Result{}, nil
+}
+
+// hostedTenants returns the names of the tenants hosted by the Modela resource, which are its installed tenants and
+// the ModelaTenants which reference it
+func (r *ModelaReconciler) hostedTenants(ctx context.Context, modela *managementv1alpha1.Modela) ([]string, error) {
+	tenants := append([]string{}, modela.Status.Tenants...)
+
+	var modelaTenants managementv1alpha1.ModelaTenantList
+	if err := r.List(ctx, &modelaTenants); err != nil {
+		return nil, err
+	}
+	for _, tenant := range modelaTenants.Items {
+		if ref := tenant.Spec.ModelaRef; ref.Name != "" && (ref.Name != modela.Name || ref.Namespace != modela.Namespace) {
+			continue
+		}
+		tenants = append(tenants, tenant.Name)
+	}
+	return tenants, nil
+}
+
 func (r *ModelaReconciler) reconcileControlPlane(ctx context.Context, modela *managementv1alpha1.Modela) (ctrl.Result, error) {
 	if modela.Spec.ControlPlane.Replicas == nil && modela.Spec.ControlPlane.Resources == nil {
 		return ctrl.Result{}, nil
@@ -0,0 +1,217 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"github.com/metaprov/modela-operator/controllers/components"
+	"github.com/metaprov/modelaapi/pkg/util"
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"reflect"
+	"sort"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+)
+
+// BackupLabel is the label of a ModelaBackupRun which contains the name of the ModelaBackup which scheduled it
+const BackupLabel = "management.modela.ai/backup"
+
+// ModelaBackupReconciler reconciles a ModelaBackup object
+type ModelaBackupReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=management.modela.ai,resources=modelabackups,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=management.modela.ai,resources=modelabackups/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=management.modela.ai,resources=modelabackups/finalizers,verbs=update
+
+// Reconcile creates a ModelaBackupRun each time the schedule of a ModelaBackup is due, unless a run is already in
+// progress, and prunes the runs which exceed the retention of the backup along with their data.
+func (r *ModelaBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var backup = new(managementv1.ModelaBackup)
+	if err := r.Get(ctx, req.NamespacedName, backup); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !backup.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	oldStatus := *backup.Status.DeepCopy()
+
+	result, err := r.reconcile(ctx, backup)
+	if err != nil {
+		logger.Error(err, "Failed to reconcile backup", "name", backup.Name)
+		backup.Status.FailureMessage = util.StrPtr(err.Error())
+		result = ctrl.Result{RequeueAfter: 30 * time.Second}
+	} else {
+		backup.Status.FailureMessage = nil
+	}
+
+	backup.Status.ObservedGeneration = backup.Generation
+	if !reflect.DeepEqual(backup.Status, oldStatus) {
+		if err := r.Status().Update(ctx, backup); err != nil {
+			logger.Error(err, "Failed to update backup status", "name", backup.Name)
+			return ctrl.Result{Requeue: true}, nil
+		}
+	}
+	return result, nil
+}
+
+func (r *ModelaBackupReconciler) reconcile(ctx context.Context, backup *managementv1.ModelaBackup) (ctrl.Result, error) {
+	schedule, err := cron.ParseStandard(backup.Spec.Schedule)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("invalid backup schedule %q: %v", backup.Spec.Schedule, err)
+	}
+
+	modela, err := getModela(ctx, r.Client, backup.Spec.ModelaRef)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var runs managementv1.ModelaBackupRunList
+	if err := r.List(ctx, &runs, client.InNamespace(backup.Namespace), client.MatchingLabels{BackupLabel: backup.Name}); err != nil {
+		return ctrl.Result{}, err
+	}
+	sort.Slice(runs.Items, func(i, j int) bool {
+		return runs.Items[j].CreationTimestamp.Before(&runs.Items[i].CreationTimestamp)
+	})
+
+	retained, err := r.prune(ctx, backup, modela, runs.Items)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	backup.Status.ActiveRun, backup.Status.Runs = "", nil
+	var lastSuccessful *metav1.Time
+	for _, run := range retained {
+		backup.Status.Runs = append(backup.Status.Runs, run.Name)
+		if !run.Finished() && backup.Status.ActiveRun == "" {
+			backup.Status.ActiveRun = run.Name
+		}
+		if run.Status.Phase == managementv1.BackupRunPhaseCompleted && lastSuccessful == nil {
+			lastSuccessful = run.Status.CompletedAt
+		}
+	}
+	if lastSuccessful != nil {
+		backup.Status.LastSuccessfulTime = lastSuccessful
+	}
+
+	now := time.Now()
+	last := backup.CreationTimestamp.Time
+	if backup.Status.LastScheduleTime != nil {
+		last = backup.Status.LastScheduleTime.Time
+	}
+	next := schedule.Next(last)
+	if backup.Spec.Suspended || now.Before(next) {
+		return ctrl.Result{RequeueAfter: next.Sub(now)}, nil
+	}
+
+	// A backup is only taken once the installation is ready and is not being restored, so that a missed schedule
+	// is caught up afterwards. The primary databases of a degraded installation can still be backed up.
+	if _, restoring := modela.Annotations[managementv1.RestoreInProgressAnnotation]; restoring ||
+		(modela.Status.Phase != managementv1.ModelaPhaseReady && modela.Status.Phase != managementv1.ModelaPhaseDegraded) {
+		log.FromContext(ctx).Info("Waiting for Modela to become ready before taking a backup", "backup", backup.Name)
+		return ctrl.Result{RequeueAfter: time.Minute}, nil
+	}
+
+	backup.Status.LastScheduleTime = &metav1.Time{Time: now}
+	if backup.Status.ActiveRun != "" {
+		log.FromContext(ctx).Info("Skipping scheduled backup, as a backup is in progress", "backup", backup.Name,
+			"run", backup.Status.ActiveRun)
+		return ctrl.Result{RequeueAfter: schedule.Next(now).Sub(now)}, nil
+	}
+
+	run := &managementv1.ModelaBackupRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", backup.Name, next.UTC().Format("20060102150405")),
+			Namespace: backup.Namespace,
+			Labels:    map[string]string{BackupLabel: backup.Name},
+		},
+		Spec: managementv1.ModelaBackupRunSpec{
+			BackupRef: backup.Name,
+			ModelaRef: managementv1.ModelaReference{Name: modela.Name, Namespace: modela.Namespace},
+			Target:    backup.Spec.Target,
+		},
+	}
+	if err := controllerutil.SetControllerReference(backup, run, r.Scheme); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := client.IgnoreAlreadyExists(r.Create(ctx, run)); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	log.FromContext(ctx).Info("Scheduled backup", "backup", backup.Name, "run", run.Name)
+	backup.Status.ActiveRun = run.Name
+	backup.Status.Runs = append([]string{run.Name}, backup.Status.Runs...)
+	return ctrl.Result{RequeueAfter: schedule.Next(now).Sub(now)}, nil
+}
+
+// prune deletes the finished runs, newest first, which exceed the number of retained backups or are older than the
+// maximum age, along with their data in the object storage. The last successful backup is always retained.
+func (r *ModelaBackupReconciler) prune(ctx context.Context, backup *managementv1.ModelaBackup, modela *managementv1.Modela,
+	runs []managementv1.ModelaBackupRun) ([]managementv1.ModelaBackupRun, error) {
+	retention := backup.Spec.Retention
+	maxBackups := int(retention.MaxBackups)
+	if maxBackups < 1 {
+		maxBackups = 7
+	}
+
+	var retained []managementv1.ModelaBackupRun
+	var lastSuccessful bool
+	for i, run := range runs {
+		expired := i >= maxBackups
+		if retention.MaxAge != nil && run.Status.CompletedAt != nil {
+			expired = expired || time.Since(run.Status.CompletedAt.Time) > retention.MaxAge.Duration
+		}
+		if !lastSuccessful && run.Status.Phase == managementv1.BackupRunPhaseCompleted {
+			lastSuccessful, expired = true, false
+		}
+
+		if !expired || !run.Finished() {
+			retained = append(retained, run)
+			continue
+		}
+
+		log.FromContext(ctx).Info("Pruning backup", "backup", backup.Name, "run", run.Name)
+		if err := components.NewBackup().Purge(ctx, modela, &run); err != nil {
+			return nil, err
+		}
+		if err := client.IgnoreNotFound(r.Delete(ctx, &run)); err != nil {
+			return nil, err
+		}
+	}
+	return retained, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ModelaBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).Named("modelabackup-controller").
+		For(&managementv1.ModelaBackup{}).
+		Owns(&managementv1.ModelaBackupRun{}).
+		Complete(r)
+}
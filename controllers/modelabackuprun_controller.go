@@ -0,0 +1,156 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"github.com/metaprov/modela-operator/controllers/components"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	"github.com/metaprov/modelaapi/pkg/util"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"reflect"
+	"strings"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+)
+
+// ModelaBackupRunReconciler reconciles a ModelaBackupRun object
+type ModelaBackupRunReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=management.modela.ai,resources=modelabackupruns,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=management.modela.ai,resources=modelabackupruns/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=management.modela.ai,resources=modelabackupruns/finalizers,verbs=update
+
+// Reconcile starts the backup job of a ModelaBackupRun, and reports the backup of each component until the job
+// has finished. The jobs run in the modela-system namespace, so they are polled rather than watched.
+func (r *ModelaBackupRunReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var run = new(managementv1.ModelaBackupRun)
+	if err := r.Get(ctx, req.NamespacedName, run); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !run.DeletionTimestamp.IsZero() || run.Finished() {
+		return ctrl.Result{}, nil
+	}
+	oldStatus := *run.Status.DeepCopy()
+
+	result, err := r.reconcile(ctx, run)
+	if err != nil {
+		logger.Error(err, "Failed to reconcile backup run", "name", run.Name)
+		run.Status.FailureMessage = util.StrPtr(err.Error())
+		result = ctrl.Result{RequeueAfter: 30 * time.Second}
+	}
+
+	if !reflect.DeepEqual(run.Status, oldStatus) {
+		if err := r.Status().Update(ctx, run); err != nil {
+			logger.Error(err, "Failed to update backup run status", "name", run.Name)
+			return ctrl.Result{Requeue: true}, nil
+		}
+	}
+	return result, nil
+}
+
+func (r *ModelaBackupRunReconciler) reconcile(ctx context.Context, run *managementv1.ModelaBackupRun) (ctrl.Result, error) {
+	backup := components.NewBackup()
+	if run.Status.Phase == "" || run.Status.Phase == managementv1.BackupRunPhasePending {
+		modela, err := getModela(ctx, r.Client, run.Spec.ModelaRef)
+		if err != nil {
+			run.Status.Phase = managementv1.BackupRunPhasePending
+			return ctrl.Result{}, err
+		}
+
+		if err := backup.Start(ctx, modela, run); err != nil {
+			run.Status.Phase = managementv1.BackupRunPhasePending
+			return ctrl.Result{}, err
+		}
+
+		now := metav1.Now()
+		run.Status.Phase = managementv1.BackupRunPhaseRunning
+		run.Status.StartedAt = &now
+		run.Status.Folder = backup.Folder(modela, run)
+		run.Status.Distribution = modela.Spec.Distribution
+		run.Status.FailureMessage = nil
+		run.Status.Components = nil
+		for _, component := range backup.Components(modela) {
+			run.Status.Components = append(run.Status.Components, managementv1.BackupComponentStatus{
+				Name:  component,
+				Phase: managementv1.BackupRunPhasePending,
+			})
+		}
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, nil
+	}
+
+	job, components, err := backup.Status(run)
+	if err != nil {
+		return ctrl.Result{}, err
+	} else if len(components) > 0 {
+		run.Status.Components = components
+	}
+
+	var failure string
+	if job == nil {
+		failure = fmt.Sprintf("The backup job %s no longer exists", backup.JobName(run))
+	} else if finished, condition := kube.JobFinished(job); !finished {
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, nil
+	} else if condition == batchv1.JobFailed {
+		var failed []string
+		for _, component := range run.Status.Components {
+			if component.Phase == managementv1.BackupRunPhaseFailed {
+				failed = append(failed, component.Name)
+			}
+		}
+		failure = fmt.Sprintf("The backup of %s failed, see the logs of job %s", strings.Join(failed, ", "), job.Name)
+		if len(failed) == 0 {
+			failure = fmt.Sprintf("The backup job %s failed", job.Name)
+		}
+	}
+
+	now := metav1.Now()
+	run.Status.CompletedAt = &now
+	if failure != "" {
+		log.FromContext(ctx).Info("Backup failed", "run", run.Name, "reason", failure)
+		run.Status.Phase = managementv1.BackupRunPhaseFailed
+		run.Status.FailureMessage = util.StrPtr(failure)
+	} else {
+		log.FromContext(ctx).Info("Backup completed", "run", run.Name, "folder", run.Status.Folder)
+		run.Status.Phase = managementv1.BackupRunPhaseCompleted
+		run.Status.FailureMessage = nil
+	}
+	if err := backup.Cleanup(run); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to delete the credentials of the backup job", "run", run.Name)
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ModelaBackupRunReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).Named("modelabackuprun-controller").
+		For(&managementv1.ModelaBackupRun{}).
+		Complete(r)
+}
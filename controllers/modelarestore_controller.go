@@ -0,0 +1,363 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/metaprov/modela-operator/controllers/components"
+	"github.com/metaprov/modelaapi/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"reflect"
+	"strings"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+)
+
+// RestoreFinalizer resumes the Modela installation paused by a ModelaRestore when the restore is deleted
+const RestoreFinalizer = "management.modela.ai/restore-finalizer"
+
+// ModelaRestoreReconciler reconciles a ModelaRestore object
+type ModelaRestoreReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=management.modela.ai,resources=modelarestores,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=management.modela.ai,resources=modelarestores/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=management.modela.ai,resources=modelarestores/finalizers,verbs=update
+
+// Reconcile validates the backup referenced by a ModelaRestore and, unless the restore is a dry-run, restores it in
+// order: the installation is paused once its prerequisites are installed, then Vault is restored and unsealed, then
+// the databases and the object storage are restored, and finally the tenants are re-applied before the
+// installation is resumed. Each step is reported through a condition.
+func (r *ModelaRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var restore = new(managementv1.ModelaRestore)
+	if err := r.Get(ctx, req.NamespacedName, restore); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !restore.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(restore, RestoreFinalizer) {
+			return ctrl.Result{}, nil
+		}
+		if modela, err := getModela(ctx, r.Client, restore.Spec.ModelaRef); err == nil {
+			if err := r.resume(ctx, restore, modela); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		controllerutil.RemoveFinalizer(restore, RestoreFinalizer)
+		return ctrl.Result{}, r.Update(ctx, restore)
+	}
+	if restore.Finished() {
+		return ctrl.Result{}, nil
+	}
+
+	if !restore.Spec.DryRun && !controllerutil.ContainsFinalizer(restore, RestoreFinalizer) {
+		controllerutil.AddFinalizer(restore, RestoreFinalizer)
+		if err := r.Update(ctx, restore); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	oldStatus := *restore.Status.DeepCopy()
+
+	result, err := r.reconcile(ctx, restore)
+	var failed *components.JobFailedError
+	if errors.As(err, &failed) {
+		r.fail(ctx, restore, err.Error())
+		result = ctrl.Result{}
+	} else if err != nil {
+		logger.Error(err, "Failed to reconcile restore", "name", restore.Name)
+		restore.Status.FailureMessage = util.StrPtr(err.Error())
+		result = ctrl.Result{RequeueAfter: 30 * time.Second}
+	}
+
+	restore.Status.ObservedGeneration = restore.Generation
+	if !reflect.DeepEqual(restore.Status, oldStatus) {
+		if err := r.Status().Update(ctx, restore); err != nil {
+			logger.Error(err, "Failed to update restore status", "name", restore.Name)
+			return ctrl.Result{Requeue: true}, nil
+		}
+	}
+	return result, nil
+}
+
+func (r *ModelaRestoreReconciler) reconcile(ctx context.Context, restore *managementv1.ModelaRestore) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	restorer := components.NewRestore()
+
+	modela, err := getModela(ctx, r.Client, restore.Spec.ModelaRef)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	source, err := r.source(ctx, restore)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if restore.Status.StartedAt == nil {
+		now := metav1.Now()
+		restore.Status.StartedAt = &now
+	}
+
+	if !restore.IsConditionTrue(managementv1.BackupValidated) {
+		restore.Status.Phase = managementv1.RestorePhaseValidating
+		backup, err := restorer.Validate(ctx, modela, source)
+		if err != nil {
+			restore.SetCondition(managementv1.BackupValidated, managementv1.ConditionFalse, "IntegrityCheckFailed", err.Error())
+			r.fail(ctx, restore, err.Error())
+			return ctrl.Result{}, nil
+		}
+		restore.Status.Backup = backup
+
+		running := modela.Status.InstalledVersion
+		if running == "" {
+			running = modela.Spec.Distribution
+		}
+		if err := components.CompatibleDistribution(backup.Distribution, running); err != nil {
+			restore.SetCondition(managementv1.BackupValidated, managementv1.ConditionFalse, "IncompatibleDistribution", err.Error())
+			r.fail(ctx, restore, err.Error())
+			return ctrl.Result{}, nil
+		}
+		restore.SetCondition(managementv1.BackupValidated, managementv1.ConditionTrue, "Validated",
+			fmt.Sprintf("The backup of distribution %s can be restored into distribution %s", backup.Distribution, running))
+
+		if restore.Spec.DryRun {
+			logger.Info("Validated backup for dry-run", "restore", restore.Name, "folder", source.Folder)
+			now := metav1.Now()
+			restore.Status.Phase = managementv1.RestorePhaseValidated
+			restore.Status.CompletedAt = &now
+			restore.Status.FailureMessage = nil
+			return ctrl.Result{}, nil
+		}
+	}
+	restore.Status.Phase = managementv1.RestorePhaseRestoring
+	restore.Status.FailureMessage = nil
+
+	if !restore.IsConditionTrue(managementv1.PrerequisitesInstalled) {
+		if current, ok := modela.Annotations[managementv1.RestoreInProgressAnnotation]; ok && current != restoreKey(restore) {
+			restore.SetCondition(managementv1.PrerequisitesInstalled, managementv1.ConditionFalse, "RestoreInProgress",
+				fmt.Sprintf("Waiting for restore %s to finish", current))
+			return ctrl.Result{RequeueAfter: time.Minute}, nil
+		} else if !ok && modela.Status.Phase != managementv1.ModelaPhaseReady {
+			restore.SetCondition(managementv1.PrerequisitesInstalled, managementv1.ConditionFalse, "WaitingForModela",
+				fmt.Sprintf("Waiting for Modela to become ready, currently %s", modela.Status.Phase))
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		}
+
+		if err := r.pause(ctx, restore, modela); err != nil {
+			return ctrl.Result{}, err
+		}
+		restore.SetCondition(managementv1.PrerequisitesInstalled, managementv1.ConditionTrue, "Paused",
+			"Modela is installed and paused for the restore")
+	}
+
+	if !restore.IsConditionTrue(managementv1.VaultRestored) {
+		if restore.Status.Backup.Contains(components.BackupComponentVaultSecrets) && modela.Spec.Vault.Install {
+			// The exported secrets are written into the running Vault, which remains unsealed
+			if restored, err := restorer.RestoreVaultSecrets(ctx, modela, restore, source); err != nil || !restored {
+				restore.SetCondition(managementv1.VaultRestored, managementv1.ConditionFalse, "Restoring",
+					"Waiting for the secrets of Vault to be restored")
+				return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+			}
+			restore.SetCondition(managementv1.VaultRestored, managementv1.ConditionTrue, "Restored",
+				"The secrets of Vault were restored")
+		} else if !restore.Status.Backup.Contains(components.BackupComponentVault) ||
+			!modela.Spec.Vault.Install || !modela.Spec.Vault.HA.Enabled {
+			restore.SetCondition(managementv1.VaultRestored, managementv1.ConditionTrue, "NotIncluded",
+				"The backup does not contain a snapshot of Vault, or Vault does not run in high-availability mode")
+		} else {
+			if restored, err := restorer.RestoreVault(ctx, modela, restore, source); err != nil || !restored {
+				restore.SetCondition(managementv1.VaultRestored, managementv1.ConditionFalse, "Restoring",
+					"Waiting for the snapshot of Vault to be restored")
+				return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+			}
+
+			if ref := restore.Spec.VaultUnsealKeysSecretRef; ref != nil {
+				if err := restorer.StoreUnsealKeys(modela, ref.Name); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+			result, err := components.NewAutoUnsealer(r.Client).Unseal(ctx, modela)
+			if err != nil || len(result.Sealed()) > 0 {
+				restore.SetCondition(managementv1.VaultRestored, managementv1.ConditionFalse, "WaitingForUnseal",
+					fmt.Sprintf("Waiting for the restored Vault servers to be unsealed: %s", strings.Join(result.Sealed(), ", ")))
+				return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+			}
+			restore.SetCondition(managementv1.VaultRestored, managementv1.ConditionTrue, "Restored",
+				"The snapshot of Vault was restored and Vault was unsealed")
+		}
+	}
+
+	if !restore.IsConditionTrue(managementv1.DatabasesRestored) {
+		var restored []string
+		for _, component := range restorer.Components(modela) {
+			if restore.Status.Backup.Contains(component) {
+				restored = append(restored, component)
+			}
+		}
+		if done, err := restorer.RestoreDatabases(ctx, modela, restore, source, restored); err != nil || !done {
+			restore.SetCondition(managementv1.DatabasesRestored, managementv1.ConditionFalse, "Restoring",
+				"Waiting for the databases and the object storage to be restored")
+			return ctrl.Result{RequeueAfter: 15 * time.Second}, err
+		}
+		restore.SetCondition(managementv1.DatabasesRestored, managementv1.ConditionTrue, "Restored",
+			"The databases and the object storage were restored")
+	}
+
+	if !restore.IsConditionTrue(managementv1.ResourcesRestored) {
+		namespaces, err := restorer.RestoreResources(ctx, modela, source, restore.Status.Backup, restore.Spec.Tenants)
+		if err != nil {
+			restore.SetCondition(managementv1.ResourcesRestored, managementv1.ConditionFalse, "ApplyFailed", err.Error())
+			return ctrl.Result{}, err
+		}
+		restore.Status.RestoredNamespaces = namespaces
+		restore.SetCondition(managementv1.ResourcesRestored, managementv1.ConditionTrue, "Restored",
+			fmt.Sprintf("The resources of %d namespaces were re-applied", len(namespaces)))
+	}
+
+	if err := r.resume(ctx, restore, modela); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := restorer.Cleanup(restore); err != nil {
+		logger.Error(err, "Failed to delete the credentials of the restore jobs", "restore", restore.Name)
+	}
+
+	logger.Info("Restore completed", "restore", restore.Name, "folder", source.Folder)
+	now := metav1.Now()
+	restore.Status.Phase = managementv1.RestorePhaseCompleted
+	restore.Status.CompletedAt = &now
+	return ctrl.Result{}, nil
+}
+
+// source returns the folder of the object storage which contains the restored backup
+func (r *ModelaRestoreReconciler) source(ctx context.Context, restore *managementv1.ModelaRestore) (components.RestoreSource, error) {
+	spec := restore.Spec.Source
+	if spec.BackupRunRef == "" {
+		return components.RestoreSource{Target: spec.Target, Folder: spec.Folder}, nil
+	}
+
+	var run managementv1.ModelaBackupRun
+	if err := r.Get(ctx, types.NamespacedName{Namespace: restore.Namespace, Name: spec.BackupRunRef}, &run); err != nil {
+		return components.RestoreSource{}, err
+	}
+	if run.Status.Phase != managementv1.BackupRunPhaseCompleted {
+		return components.RestoreSource{}, fmt.Errorf("the backup run %s has not completed", run.Name)
+	}
+	return components.RestoreSource{Target: run.Spec.Target, Folder: run.Status.Folder}, nil
+}
+
+// pause stops the reconciliation of the installation and its tenants, and scales down the deployments which write
+// to the databases
+func (r *ModelaRestoreReconciler) pause(ctx context.Context, restore *managementv1.ModelaRestore, modela *managementv1.Modela) error {
+	if modela.Annotations[managementv1.RestoreInProgressAnnotation] != restoreKey(restore) {
+		patch := client.MergeFrom(modela.DeepCopy())
+		if modela.Annotations == nil {
+			modela.Annotations = make(map[string]string)
+		}
+		modela.Annotations[managementv1.RestoreInProgressAnnotation] = restoreKey(restore)
+		if err := r.Patch(ctx, modela, patch); err != nil {
+			return err
+		}
+	}
+	return components.NewRestore().Pause(ctx)
+}
+
+// resume resumes the installation paused by a restore. The connections of the tenants are cleared from the status
+// of the installation and of its tenants, so that the credentials of the tenants are provisioned again into the
+// restored databases.
+func (r *ModelaRestoreReconciler) resume(ctx context.Context, restore *managementv1.ModelaRestore, modela *managementv1.Modela) error {
+	if modela.Annotations[managementv1.RestoreInProgressAnnotation] != restoreKey(restore) {
+		return nil
+	}
+	if err := components.NewRestore().Resume(ctx); err != nil {
+		return err
+	}
+
+	if restore.IsConditionTrue(managementv1.DatabasesRestored) {
+		var tenants managementv1.ModelaTenantList
+		if err := r.List(ctx, &tenants); err != nil {
+			return err
+		}
+		for i := range tenants.Items {
+			tenant := &tenants.Items[i]
+			if tenant.Status.Connections == nil {
+				continue
+			}
+			if ref := tenant.Spec.ModelaRef; ref.Name != "" && (ref.Name != modela.Name || ref.Namespace != modela.Namespace) {
+				continue
+			}
+			tenant.Status.Connections = nil
+			if err := r.Status().Update(ctx, tenant); err != nil {
+				return err
+			}
+		}
+
+		if modela.Status.TenantConnections != nil {
+			modela.Status.TenantConnections = nil
+			if err := r.Status().Update(ctx, modela); err != nil {
+				return err
+			}
+		}
+	}
+
+	patch := client.MergeFrom(modela.DeepCopy())
+	delete(modela.Annotations, managementv1.RestoreInProgressAnnotation)
+	return r.Patch(ctx, modela, patch)
+}
+
+// fail marks a restore as failed, and resumes the installation if it was paused by the restore
+func (r *ModelaRestoreReconciler) fail(ctx context.Context, restore *managementv1.ModelaRestore, message string) {
+	logger := log.FromContext(ctx)
+	logger.Info("Restore failed", "restore", restore.Name, "reason", message)
+
+	now := metav1.Now()
+	restore.Status.Phase = managementv1.RestorePhaseFailed
+	restore.Status.CompletedAt = &now
+	restore.Status.FailureMessage = util.StrPtr(message)
+
+	if modela, err := getModela(ctx, r.Client, restore.Spec.ModelaRef); err == nil {
+		if err := r.resume(ctx, restore, modela); err != nil {
+			logger.Error(err, "Failed to resume Modela after the restore failed", "restore", restore.Name)
+		}
+	}
+	if err := components.NewRestore().Cleanup(restore); err != nil {
+		logger.Error(err, "Failed to delete the credentials of the restore jobs", "restore", restore.Name)
+	}
+}
+
+// restoreKey returns the value of the RestoreInProgressAnnotation set by a restore
+func restoreKey(restore *managementv1.ModelaRestore) string {
+	return restore.Namespace + "/" + restore.Name
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ModelaRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).Named("modelarestore-controller").
+		For(&managementv1.ModelaRestore{}).
+		Complete(r)
+}
@@ -0,0 +1,393 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"github.com/metaprov/modela-operator/controllers/components"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	"github.com/metaprov/modela-operator/pkg/secrets"
+	"github.com/metaprov/modelaapi/pkg/util"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"reflect"
+	"strings"
+	"time"
+
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+)
+
+// TenantFinalizer is added to ModelaTenant resources so that the namespace and secrets of the tenant are removed
+// before the resource is deleted
+const TenantFinalizer = "management.modela.ai/tenant"
+
+// ModelaTenantReconciler reconciles a ModelaTenant object
+type ModelaTenantReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=management.modela.ai,resources=modelatenants,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=management.modela.ai,resources=modelatenants/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=management.modela.ai,resources=modelatenants/finalizers,verbs=update
+
+// Reconcile installs the namespace, secrets and manifests of a ModelaTenant into the Modela installation it
+// references, keeps its configuration in sync, and reports the readiness of the labs and serving sites of the tenant.
+func (r *ModelaTenantReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var tenant = new(managementv1.ModelaTenant)
+	if err := r.Get(ctx, req.NamespacedName, tenant); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	oldStatus := *tenant.Status.DeepCopy()
+
+	modela, err := r.getModela(ctx, tenant)
+	if !tenant.DeletionTimestamp.IsZero() {
+		return r.uninstall(ctx, tenant, modela)
+	}
+
+	var result ctrl.Result
+	if err != nil {
+		tenant.Status.Phase = managementv1.ModelaTenantPhasePending
+		tenant.Status.FailureMessage = util.StrPtr(err.Error())
+		tenant.SetCondition(managementv1.TenantInstalled, managementv1.ConditionFalse, "ModelaNotFound", err.Error())
+		result = ctrl.Result{RequeueAfter: 30 * time.Second}
+		goto updateStatus
+	}
+	if restore, ok := modela.Annotations[managementv1.RestoreInProgressAnnotation]; ok {
+		logger.Info("Reconciliation is paused while Modela is restored", "name", tenant.Name, "restore", restore)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	if !controllerutil.ContainsFinalizer(tenant, TenantFinalizer) {
+		controllerutil.AddFinalizer(tenant, TenantFinalizer)
+		if err := r.Update(ctx, tenant); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	result, err = r.install(ctx, tenant, modela)
+	if err != nil {
+		logger.Error(err, "Failed to install tenant", "name", tenant.Name)
+		tenant.Status.Phase = managementv1.ModelaTenantPhaseFailed
+		tenant.Status.FailureMessage = util.StrPtr(err.Error())
+		result = ctrl.Result{RequeueAfter: 10 * time.Second}
+		goto updateStatus
+	}
+	tenant.Status.FailureMessage = nil
+	if result.RequeueAfter > 0 {
+		goto updateStatus
+	}
+
+	result, err = r.reconcileConfig(ctx, tenant, modela)
+	if components.IsClientJobRunning(err) {
+		result, err = waitForClientJob(ctx, err), nil
+		goto updateStatus
+	} else if err != nil {
+		logger.Error(err, "Failed to apply tenant configuration", "name", tenant.Name)
+		tenant.Status.FailureMessage = util.StrPtr(err.Error())
+		result = ctrl.Result{RequeueAfter: 30 * time.Second}
+		goto updateStatus
+	} else if result.RequeueAfter > 0 {
+		goto updateStatus
+	}
+
+	result, err = r.reconcileReadiness(ctx, tenant, modela)
+
+updateStatus:
+	tenant.Status.ObservedGeneration = tenant.Generation
+	if !reflect.DeepEqual(tenant.Status, oldStatus) {
+		tenant.Status.LastUpdated = &metav1.Time{Time: time.Now()}
+		if err := r.Status().Update(ctx, tenant); err != nil {
+			logger.Error(err, "Failed to update tenant status", "name", tenant.Name)
+			return ctrl.Result{Requeue: true}, nil
+		}
+	}
+	return result, err
+}
+
+// getModela returns the Modela resource referenced by a tenant
+func (r *ModelaTenantReconciler) getModela(ctx context.Context, tenant *managementv1.ModelaTenant) (*managementv1.Modela, error) {
+	return getModela(ctx, r.Client, tenant.Spec.ModelaRef)
+}
+
+// getModela returns the Modela resource of a reference. When the reference has no name, it references the only
+// Modela resource in the namespace of the reference.
+func getModela(ctx context.Context, c client.Client, ref managementv1.ModelaReference) (*managementv1.Modela, error) {
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = "modela-system"
+	}
+
+	if ref.Name != "" {
+		var modela = new(managementv1.Modela)
+		if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, modela); err != nil {
+			return nil, errors.Wrapf(err, "Failed to get Modela %s/%s", namespace, ref.Name)
+		}
+		return modela, nil
+	}
+
+	var modelas managementv1.ModelaList
+	if err := c.List(ctx, &modelas, client.InNamespace(namespace)); err != nil {
+		return nil, errors.Wrap(err, "Failed to list Modela resources")
+	}
+	if len(modelas.Items) != 1 {
+		return nil, errors.Errorf("Expected a single Modela resource in namespace %s, found %d; set spec.modelaRef.name", namespace, len(modelas.Items))
+	}
+	return &modelas.Items[0], nil
+}
+
+// install installs the tenant once the Modela installation is ready
+func (r *ModelaTenantReconciler) install(ctx context.Context, tenant *managementv1.ModelaTenant, modela *managementv1.Modela) (ctrl.Result, error) {
+	for _, spec := range modela.Spec.Tenants {
+		if spec.Name == tenant.Name {
+			message := fmt.Sprintf("Tenant %s is managed by the tenants of Modela %s", tenant.Name, modela.Name)
+			tenant.SetCondition(managementv1.TenantInstalled, managementv1.ConditionFalse, "Conflict", message)
+			return ctrl.Result{}, errors.New(message)
+		}
+	}
+
+	if condition := tenant.GetCondition(managementv1.TenantInstalled); condition != nil && condition.Status == managementv1.ConditionTrue {
+		return ctrl.Result{}, nil
+	}
+
+	if modela.Status.Phase != managementv1.ModelaPhaseReady {
+		tenant.Status.Phase = managementv1.ModelaTenantPhasePending
+		tenant.SetCondition(managementv1.TenantInstalled, managementv1.ConditionFalse, "ModelaNotReady",
+			fmt.Sprintf("Waiting for Modela %s to become ready", modela.Name))
+		return ctrl.Result{RequeueAfter: 15 * time.Second}, nil
+	}
+
+	// The webhook rejects these tenants, but is not deployed with every installation of the operator
+	if err := r.validateNamespace(tenant); err != nil {
+		tenant.SetCondition(managementv1.TenantInstalled, managementv1.ConditionFalse, "InvalidName", err.Error())
+		return ctrl.Result{}, err
+	}
+
+	tenant.Status.Phase = managementv1.ModelaTenantPhaseInstalling
+	if err := components.NewTenant(tenant.Name).Install(ctx, modela, &managementv1.TenantSpec{
+		Name:         tenant.Name,
+		TenantConfig: tenant.Spec.TenantConfig,
+	}); components.IsClientJobRunning(err) {
+		return waitForClientJob(ctx, err), nil
+	} else if err != nil {
+		tenant.SetCondition(managementv1.TenantInstalled, managementv1.ConditionFalse, "InstallFailed", err.Error())
+		return ctrl.Result{}, err
+	}
+
+	tenant.SetCondition(managementv1.TenantInstalled, managementv1.ConditionTrue, "Installed", "")
+	return ctrl.Result{}, nil
+}
+
+// validateNamespace checks that the name of a tenant is not reserved, and that the namespace of the tenant does not
+// exist or was created by the operator
+func (r *ModelaTenantReconciler) validateNamespace(tenant *managementv1.ModelaTenant) error {
+	if err := managementv1.ValidateTenantName(tenant.Name); err != nil {
+		return err
+	}
+	namespace, err := kube.GetNamespace(tenant.Name)
+	if err != nil || namespace == nil {
+		return err
+	}
+	return managementv1.ValidateTenantNamespace(namespace)
+}
+
+// reconcileConfig keeps the admin password, accounts, labs, serving sites, quota, connections and suspension of the
+// tenant in sync with its spec
+func (r *ModelaTenantReconciler) reconcileConfig(ctx context.Context, tenant *managementv1.ModelaTenant, modela *managementv1.Modela) (ctrl.Result, error) {
+	component := components.NewTenant(tenant.Name)
+
+	var err error
+	if tenant.Status.AdminPassword, err = component.ApplyAdminPassword(ctx, modela, tenant.Spec.TenantConfig, tenant.Status.AdminPassword); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := component.ApplyAccounts(ctx, modela, tenant.Spec.TenantConfig); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := component.ApplyWorkspaces(ctx, modela, tenant.Spec.TenantConfig); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := component.ApplyQuota(ctx, modela, tenant.Spec.Quota); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := component.ApplyDeletionPolicy(ctx, tenant.Spec.DeletionPolicy); err != nil {
+		return ctrl.Result{}, err
+	}
+	if tenant.Status.Connections, err = component.SyncConnections(ctx, modela, tenant.Status.Connections); err != nil {
+		return ctrl.Result{}, err
+	}
+	if tenant.Status.Quota, err = component.QuotaStatus(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if tenant.Spec.Suspended {
+		if err := component.Suspend(ctx, modela); err != nil {
+			return ctrl.Result{}, err
+		}
+		tenant.Status.Phase = managementv1.ModelaTenantPhaseSuspended
+		tenant.SetCondition(managementv1.TenantSuspended, managementv1.ConditionTrue, "Suspended", "")
+		tenant.SetCondition(managementv1.TenantReady, managementv1.ConditionFalse, "Suspended", "The tenant is suspended")
+		return ctrl.Result{RequeueAfter: 5 * time.Minute}, nil
+	}
+
+	if condition := tenant.GetCondition(managementv1.TenantSuspended); condition != nil && condition.Status == managementv1.ConditionTrue {
+		if err := component.Resume(ctx, modela); err != nil {
+			return ctrl.Result{}, err
+		}
+		tenant.SetCondition(managementv1.TenantSuspended, managementv1.ConditionFalse, "Resumed", "")
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// reconcileReadiness records the ready labs and serving sites of the tenant and the locations of its secrets
+func (r *ModelaTenantReconciler) reconcileReadiness(ctx context.Context, tenant *managementv1.ModelaTenant, modela *managementv1.Modela) (ctrl.Result, error) {
+	component := components.NewTenant(tenant.Name)
+
+	store, err := secrets.GetSecretStore(modela)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	tenant.Status.SecretPaths = nil
+	for _, key := range component.SecretKeys(modela, tenant.Spec.TenantConfig) {
+		tenant.Status.SecretPaths = append(tenant.Status.SecretPaths, store.Location(key))
+	}
+
+	if tenant.Status.ReadyLabs, err = component.ReadyLabs(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+	if tenant.Status.ReadyServingSites, err = component.ReadyServingSites(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if ready, err := component.Ready(ctx); !ready {
+		message := "Resources of the tenant are missing"
+		if err != nil && !errors.Is(err, managementv1.ComponentMissingResourcesError) {
+			message = err.Error()
+		}
+		tenant.Status.Phase = managementv1.ModelaTenantPhaseInstalling
+		tenant.SetCondition(managementv1.TenantReady, managementv1.ConditionFalse, "MissingResources", message)
+		// Reinstall the manifests of the tenant on the next reconciliation
+		tenant.SetCondition(managementv1.TenantInstalled, managementv1.ConditionFalse, "MissingResources", message)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	if len(tenant.Status.ReadyLabs) == 0 || len(tenant.Status.ReadyServingSites) == 0 {
+		tenant.Status.Phase = managementv1.ModelaTenantPhaseInstalling
+		tenant.SetCondition(managementv1.TenantReady, managementv1.ConditionFalse, "WaitingForWorkloads",
+			"Waiting for the labs and serving sites of the tenant to become ready")
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	if connections := tenant.Status.Connections; connections != nil && len(connections.Missing) > 0 {
+		message := "Missing connections: " + strings.Join(connections.Missing, ", ")
+		tenant.Status.Phase = managementv1.ModelaTenantPhaseDegraded
+		tenant.SetCondition(managementv1.TenantDegraded, managementv1.ConditionTrue, "ConnectionsMissing", message)
+		tenant.SetCondition(managementv1.TenantReady, managementv1.ConditionFalse, "ConnectionsMissing", message)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+	tenant.SetCondition(managementv1.TenantDegraded, managementv1.ConditionFalse, "ConnectionsReady", "")
+
+	tenant.Status.Phase = managementv1.ModelaTenantPhaseReady
+	tenant.SetCondition(managementv1.TenantReady, managementv1.ConditionTrue, "Ready", "")
+	return ctrl.Result{RequeueAfter: 5 * time.Minute}, nil
+}
+
+// uninstall removes the namespace and secrets of a deleted tenant and releases its finalizer
+func (r *ModelaTenantReconciler) uninstall(ctx context.Context, tenant *managementv1.ModelaTenant, modela *managementv1.Modela) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	if !controllerutil.ContainsFinalizer(tenant, TenantFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if modela != nil {
+		if tenant.Status.Phase != managementv1.ModelaTenantPhaseUninstalling {
+			tenant.Status.Phase = managementv1.ModelaTenantPhaseUninstalling
+			if err := r.Status().Update(ctx, tenant); err != nil {
+				return ctrl.Result{Requeue: true}, nil
+			}
+		}
+
+		// The namespace is retained unless the deletion policy of the tenant is Delete or its deletion is confirmed
+		confirmed := tenant.Spec.DeletionPolicy == managementv1.TenantDeletionPolicyDelete ||
+			tenant.Annotations[managementv1.ConfirmDeletionAnnotation] == "true"
+		_, err := components.NewTenant(tenant.Name).Remove(ctx, modela, confirmed)
+		if components.IsClientJobRunning(err) {
+			return waitForClientJob(ctx, err), nil
+		} else if err != nil && !k8serr.IsNotFound(err) {
+			logger.Error(err, "Failed to remove tenant", "name", tenant.Name)
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+		}
+	}
+
+	controllerutil.RemoveFinalizer(tenant, TenantFinalizer)
+	return ctrl.Result{}, r.Update(ctx, tenant)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ModelaTenantReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).Named("modelatenant-controller").
+		For(&managementv1.ModelaTenant{}).
+		Watches(&source.Kind{Type: &v1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.mapSecret)).
+		Complete(r)
+}
+
+// mapSecret enqueues the tenants whose passwords are stored in a Secret, and all tenants of a Modela resource when
+// the Secret holds the credentials of a system component used by their connections
+func (r *ModelaTenantReconciler) mapSecret(obj client.Object) []reconcile.Request {
+	var tenants managementv1.ModelaTenantList
+	if err := r.List(context.Background(), &tenants); err != nil {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, tenant := range tenants.Items {
+		namespace := tenant.Spec.ModelaRef.Namespace
+		if namespace == "" {
+			namespace = "modela-system"
+		}
+		if namespace == obj.GetNamespace() && isPasswordSecret(tenant.Name, tenant.Spec.TenantConfig, obj.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&tenant)})
+			continue
+		}
+
+		modela, err := getModela(context.Background(), r.Client, tenant.Spec.ModelaRef)
+		if err != nil {
+			continue
+		}
+		if isConnectionSecret(modela, obj) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&tenant)})
+		}
+	}
+	return requests
+}
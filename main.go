@@ -90,6 +90,37 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = (&controllers.ModelaTenantReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ModelaTenant")
+		os.Exit(1)
+	}
+
+	if err = (&controllers.ModelaBackupReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ModelaBackup")
+		os.Exit(1)
+	}
+
+	if err = (&controllers.ModelaBackupRunReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ModelaBackupRun")
+		os.Exit(1)
+	}
+	if err = (&controllers.ModelaRestoreReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ModelaRestore")
+		os.Exit(1)
+	}
+
 	//+kubebuilder:scaffold:builder
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
@@ -100,11 +131,13 @@ func main() {
 		os.Exit(1)
 	}
 
-	setupLog.Info("starting manager")
-	signalContext := ctrl.SetupSignalHandler()
-	go components.StartAutoUnseal(signalContext)
+	if err := mgr.Add(components.NewAutoUnsealer(mgr.GetClient())); err != nil {
+		setupLog.Error(err, "unable to set up Vault auto-unseal")
+		os.Exit(1)
+	}
 
-	if err := mgr.Start(signalContext); err != nil {
+	setupLog.Info("starting manager")
+	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
@@ -267,26 +267,14 @@ func (chart *HelmChart) Install(ctx context.Context) error {
 
 func (chart *HelmChart) Upgrade(ctx context.Context) error {
 	logger := log.FromContext(ctx)
-
-	logger.Info("Enter upgrade")
-
-	err := chart.Load(ctx)
-	if err != nil {
-		logger.Error(err, "failed to load chart")
-		return errors.Wrapf(err, "failed to load chart")
-	}
-	// Check if resource already exists
-	existingRelease, err := chart.Get(ctx)
-	if existingRelease != nil {
-		return errors.Wrapf(err, "release '%s' already exists in namespace '%s'", existingRelease.Name, existingRelease.Namespace)
-	}
+	logger.Info("Upgrading Helm Chart", "release", chart.ReleaseName, "namespace", chart.Namespace, "name", chart.Name)
 
 	can, err := chart.CanInstall(ctx)
 	if err != nil {
-		return errors.Wrapf(err, "failed to check if chart is installed '%s'", existingRelease.Namespace)
+		return errors.Wrapf(err, "failed to check if chart is installable '%s'", chart.Name)
 	}
 	if !can {
-		return errors.Wrapf(err, "release at '%s' is not installable", chart.Name)
+		return errors.Errorf("release at '%s' is not installable", chart.Name)
 	}
 
 	config, err := chart.GetConfig()
@@ -309,6 +297,7 @@ func (chart *HelmChart) Upgrade(ctx context.Context) error {
 		inst.DryRun = chart.DryRun
 		inst.CreateNamespace = chart.CreateNamespace
 		inst.Version = chart.ChartVersion
+		inst.PostRenderer = LabelPostRenderer{map[string]string{"app.kubernetes.io/created-by": "modela-operator"}}
 
 		_, err = inst.Run(chart.chart, chart.Values)
 		if err != nil {
@@ -320,12 +309,15 @@ func (chart *HelmChart) Upgrade(ctx context.Context) error {
 		if inst.Version == "" && inst.Devel {
 			inst.Version = ">0.0.0-0"
 		}
+		inst.Namespace = chart.Namespace
 		inst.DryRun = chart.DryRun
 		inst.Version = chart.ChartVersion
+		inst.ReuseValues = true
+		inst.PostRenderer = LabelPostRenderer{map[string]string{"app.kubernetes.io/created-by": "modela-operator"}}
 
 		_, err = inst.Run(chart.ReleaseName, chart.chart, chart.Values)
 		if err != nil {
-			return fmt.Errorf("failed to run install due to %s", err)
+			return fmt.Errorf("failed to run upgrade due to %s", err)
 		}
 		return nil
 	}
@@ -377,6 +369,17 @@ func InstallChart(ctx context.Context, name, ns, releaseName string, values map[
 	return nil
 }
 
+// UpgradeChart upgrades a release with a set of values, which are merged with the values of the last release
+func UpgradeChart(ctx context.Context, name, ns, releaseName string, values map[string]interface{}) error {
+	chart := NewHelmChart(name, ns, releaseName, false)
+	chart.Values = values
+
+	if err := chart.Upgrade(ctx); err != nil {
+		return errors.Wrapf(err, "Error upgrading chart %s", name)
+	}
+	return nil
+}
+
 func UninstallChart(ctx context.Context, name, ns, releaseName string, values map[string]interface{}) error {
 	chart := NewHelmChart(name, ns, releaseName, false)
 	chart.ReleaseName = releaseName
@@ -4,7 +4,9 @@ import (
 	"encoding/base64"
 	"github.com/Masterminds/goutils"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
+	"strconv"
 	"strings"
 )
 
@@ -140,12 +142,18 @@ func (mi ManagedImageFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error)
 	return nodes, nil
 }
 
-type JwtSecretFilter struct{}
+// JwtSecretFilter sets the secret of the modela-auth-token Secret. A random secret is generated if Secret is empty.
+type JwtSecretFilter struct {
+	Secret string
+}
 
 func (j JwtSecretFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 	for _, node := range nodes {
 		if node.GetName() == "modela-auth-token" {
-			str, _ := goutils.RandomAlphaNumeric(32)
+			str := j.Secret
+			if str == "" {
+				str, _ = goutils.RandomAlphaNumeric(32)
+			}
 			b64 := base64.StdEncoding.EncodeToString([]byte(str))
 			_ = node.PipeE(yaml.Lookup("data", "jwt-secret"), yaml.Set(yaml.NewStringRNode(b64)))
 		}
@@ -153,39 +161,63 @@ func (j JwtSecretFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 	return nodes, nil
 }
 
+// ExternalServiceAnnotation marks the resources which are rendered against an external service in place of a bundled chart
+const ExternalServiceAnnotation = "management.modela.ai/external-service"
+
+// RedisSecretFilter renders the redis-secret used by the online store to connect to Redis. The address is only set
+// for an external Redis, in which case the secret is annotated as external.
 type RedisSecretFilter struct {
+	Address  string
+	Username string
 	Password string
+	TLS      bool
 }
 
 func (r RedisSecretFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 	for _, node := range nodes {
-		if node.GetName() == "redis-secret" {
-			_ = node.PipeE(
-				yaml.Lookup("data", "redis-password"),
-				yaml.Set(yaml.NewStringRNode(base64.StdEncoding.EncodeToString([]byte(r.Password)))),
-			)
+		if node.GetName() != "redis-secret" {
+			continue
+		}
+
+		values := map[string]string{"redis-password": r.Password}
+		if r.Address != "" {
+			values["redis-address"] = r.Address
+			values["redis-username"] = r.Username
+			values["redis-tls"] = strconv.FormatBool(r.TLS)
+			if err := node.PipeE(yaml.SetAnnotation(ExternalServiceAnnotation, "true")); err != nil {
+				return nil, err
+			}
+		}
+
+		for key, value := range values {
+			if err := node.PipeE(yaml.LookupCreate(yaml.MappingNode, "stringData"),
+				yaml.SetField(key, yaml.NewStringRNode(value))); err != nil {
+				return nil, err
+			}
 		}
 	}
 
 	return nodes, nil
 }
 
+// ModelaConfigFilter sets the keys of the platform configuration in the data of the modela-config ConfigMap
 type ModelaConfigFilter struct {
-	VaultAddress   string
-	VaultMountPath string
+	Data map[string]string
 }
 
 func (m ModelaConfigFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 	for _, node := range nodes {
-		if node.GetName() == "modela-config" {
-			_ = node.PipeE(
-				yaml.Lookup("spec", "vaultAddress"),
-				yaml.Set(yaml.NewStringRNode(m.VaultAddress)),
-			)
-			_ = node.PipeE(
-				yaml.Lookup("spec", "vaultMountPath"),
-				yaml.Set(yaml.NewStringRNode(m.VaultMountPath)),
-			)
+		if node.GetKind() != "ConfigMap" || node.GetName() != "modela-config" {
+			continue
+		}
+		data, err := node.Pipe(yaml.LookupCreate(yaml.MappingNode, "data"))
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range m.Data {
+			if err := data.PipeE(yaml.SetField(key, yaml.NewStringRNode(value))); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -233,9 +265,18 @@ func (o SkipCertManagerFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error
 	return outNodes, nil
 }
 
+// ConnectionFilter renders the connections and virtual buckets of a tenant for the system components in use
 type ConnectionFilter struct {
 	PgvectorEnabled bool
 	MongoEnabled    bool
+	// ObjectStorageProvider overrides the provider of the object storage connection, such as aws or gcp
+	ObjectStorageProvider string
+	// ObjectStorageRegion is set as the region of the virtual buckets
+	ObjectStorageRegion string
+	// SSL contains the names of the connections to components which are accessed over TLS
+	SSL []string
+	// MongoReplicaSet is the name of the replica set of MongoDB, which is set as an option of the MongoDB connection
+	MongoReplicaSet string
 }
 
 func (cf ConnectionFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
@@ -247,6 +288,339 @@ func (cf ConnectionFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 		if node.GetName() == "mongodb-connection" && !cf.MongoEnabled {
 			continue
 		}
+
+		if node.GetKind() == "Connection" {
+			if node.GetName() == "minio-connection" && cf.ObjectStorageProvider != "" {
+				if err := node.PipeE(yaml.LookupCreate(yaml.MappingNode, "spec"),
+					yaml.SetField("provider", yaml.NewStringRNode(cf.ObjectStorageProvider))); err != nil {
+					return nil, err
+				}
+			}
+			if node.GetName() == "mongodb-connection" && cf.MongoReplicaSet != "" {
+				if err := node.PipeE(yaml.LookupCreate(yaml.MappingNode, "spec", "options"),
+					yaml.SetField("replicaSet", yaml.NewStringRNode(cf.MongoReplicaSet))); err != nil {
+					return nil, err
+				}
+			}
+			for _, name := range cf.SSL {
+				if node.GetName() == name {
+					if err := node.PipeE(yaml.LookupCreate(yaml.MappingNode, "spec", "options"),
+						yaml.SetField("ssl", yaml.NewStringRNode("true"))); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+
+		if node.GetKind() == "VirtualBucket" && cf.ObjectStorageRegion != "" {
+			if err := node.PipeE(yaml.LookupCreate(yaml.MappingNode, "spec"),
+				yaml.SetField("region", yaml.NewStringRNode(cf.ObjectStorageRegion))); err != nil {
+				return nil, err
+			}
+		}
+
+		outNodes = append(outNodes, node)
+	}
+
+	return outNodes, nil
+}
+
+const (
+	// DeclarativeAccountLabel marks the accounts rendered from the accounts of a tenant spec
+	DeclarativeAccountLabel = "management.modela.ai/declarative-account"
+	// PasswordVersionAnnotation records the version of the password applied to a declarative account
+	PasswordVersionAnnotation = "management.modela.ai/password-version"
+	// RoleClassAnnotation records the role class granted to a declarative account
+	RoleClassAnnotation = "management.modela.ai/role-class"
+	// DeclarativeWorkspaceLabel marks the labs and serving sites rendered from the labs and serving sites of a
+	// tenant spec
+	DeclarativeWorkspaceLabel = "management.modela.ai/declarative-workspace"
+	// WorkspaceVersionAnnotation records the version of the spec applied to a declarative lab or serving site
+	WorkspaceVersionAnnotation = "management.modela.ai/workspace-version"
+	// WorkloadClassesAnnotation records the workload classes of a declarative lab or serving site, which are also set
+	// in the workloadClasses field of its spec
+	WorkloadClassesAnnotation = "management.modela.ai/workload-classes"
+	// DefaultWorkspaceAnnotation indicates if a declarative lab or serving site is the default of the tenant
+	DefaultWorkspaceAnnotation = "management.modela.ai/default"
+)
+
+// TenantAccount is an account rendered by the AccountFilter
+type TenantAccount struct {
+	Username        string
+	Email           string
+	FirstName       string
+	LastName        string
+	RoleClass       string
+	PasswordVersion string
+}
+
+// AccountFilter renders an Account for each account of a tenant, based on the default admin account of the tenant
+// manifests, and grants each account its role class through the permissions of the Tenant
+type AccountFilter struct {
+	Accounts []TenantAccount
+}
+
+func (af AccountFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	var template *yaml.RNode
+	for _, node := range nodes {
+		switch node.GetKind() {
+		case "Account":
+			if node.GetName() == "admin" {
+				template = node
+			}
+		case "Tenant":
+			accounts, err := node.Pipe(yaml.LookupCreate(yaml.SequenceNode, "spec", "permissions", "accounts"))
+			if err != nil {
+				return nil, err
+			}
+			for _, account := range af.Accounts {
+				roles := yaml.NewRNode(&yaml.Node{Kind: yaml.SequenceNode})
+				if err := roles.PipeE(yaml.Append(yaml.NewMapRNode(&map[string]string{"name": account.RoleClass}).YNode())); err != nil {
+					return nil, err
+				}
+				entry := yaml.NewMapRNode(&map[string]string{"accountName": account.Username})
+				if err := entry.PipeE(yaml.SetField("roles", roles)); err != nil {
+					return nil, err
+				}
+				if err := accounts.PipeE(yaml.Append(entry.YNode())); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if template == nil {
+		return nodes, nil
+	}
+
+	for _, account := range af.Accounts {
+		node := template.Copy()
+		if err := node.SetName(account.Username); err != nil {
+			return nil, err
+		}
+		if err := node.PipeE(yaml.SetLabel(DeclarativeAccountLabel, "true")); err != nil {
+			return nil, err
+		}
+		if err := node.PipeE(yaml.SetAnnotation(PasswordVersionAnnotation, account.PasswordVersion)); err != nil {
+			return nil, err
+		}
+		if err := node.PipeE(yaml.SetAnnotation(RoleClassAnnotation, account.RoleClass)); err != nil {
+			return nil, err
+		}
+
+		spec, err := node.Pipe(yaml.LookupCreate(yaml.MappingNode, "spec"))
+		if err != nil {
+			return nil, err
+		}
+		if err := spec.PipeE(yaml.SetField("admin", yaml.NewScalarRNode("false"))); err != nil {
+			return nil, err
+		}
+		for field, value := range map[string]string{
+			"username":  account.Username,
+			"email":     account.Email,
+			"firstName": account.FirstName,
+			"lastName":  account.LastName,
+		} {
+			if value == "" {
+				_ = spec.PipeE(yaml.Clear(field))
+				continue
+			}
+			if err := spec.PipeE(yaml.SetField(field, yaml.NewStringRNode(value))); err != nil {
+				return nil, err
+			}
+		}
+		nodes = append(nodes, node)
+	}
+
+	return nodes, nil
+}
+
+// KindFilter only keeps the resources of the given kinds, or removes them if Exclude is set
+type KindFilter struct {
+	Kinds   []string
+	Exclude bool
+}
+
+func (kf KindFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	var outNodes []*yaml.RNode
+	for _, node := range nodes {
+		var matched bool
+		for _, kind := range kf.Kinds {
+			if node.GetKind() == kind {
+				matched = true
+				break
+			}
+		}
+		if matched != kf.Exclude {
+			outNodes = append(outNodes, node)
+		}
+	}
+
+	return outNodes, nil
+}
+
+// TenantWorkspace is a lab or serving site rendered by the WorkspaceFilter
+type TenantWorkspace struct {
+	Name            string
+	Default         bool
+	WorkloadClasses []string
+	MaxCPU          *resource.Quantity
+	MaxMemory       *resource.Quantity
+	MaxPods         *int32
+	FQDN            string
+	Version         string
+}
+
+// WorkspaceFilter renders a Lab and a ServingSite for each lab and serving site of a tenant, based on the default
+// lab and serving site of the tenant manifests, and points the Tenant and DataProduct at the default lab and serving
+// site. The manifests are left untouched when no labs or serving sites are given.
+type WorkspaceFilter struct {
+	Labs         []TenantWorkspace
+	ServingSites []TenantWorkspace
+}
+
+func (wf WorkspaceFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	var err error
+	if nodes, err = renderWorkspaces(nodes, "Lab", wf.Labs); err != nil {
+		return nil, err
+	}
+	if nodes, err = renderWorkspaces(nodes, "ServingSite", wf.ServingSites); err != nil {
+		return nil, err
+	}
+
+	lab, site := DefaultWorkspace(wf.Labs), DefaultWorkspace(wf.ServingSites)
+	for _, node := range nodes {
+		if node.GetKind() != "Tenant" && node.GetKind() != "DataProduct" {
+			continue
+		}
+		if lab != nil {
+			if err := node.PipeE(
+				yaml.LookupCreate(yaml.ScalarNode, "spec", "defaultLabName"),
+				yaml.Set(yaml.NewStringRNode(lab.Name))); err != nil {
+				return nil, err
+			}
+		}
+		if site != nil {
+			if err := node.PipeE(
+				yaml.LookupCreate(yaml.ScalarNode, "spec", "defaultServingSiteName"),
+				yaml.Set(yaml.NewStringRNode(site.Name))); err != nil {
+				return nil, err
+			}
+		}
+		if node.GetKind() != "DataProduct" {
+			continue
+		}
+		if lab != nil && len(lab.WorkloadClasses) > 0 {
+			if err := node.PipeE(
+				yaml.LookupCreate(yaml.ScalarNode, "spec", "trainingResources", "workloadName"),
+				yaml.Set(yaml.NewStringRNode(lab.WorkloadClasses[0]))); err != nil {
+				return nil, err
+			}
+		}
+		if site != nil && len(site.WorkloadClasses) > 0 {
+			if err := node.PipeE(
+				yaml.LookupCreate(yaml.ScalarNode, "spec", "servingResources", "workloadName"),
+				yaml.Set(yaml.NewStringRNode(site.WorkloadClasses[0]))); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return nodes, nil
+}
+
+// DefaultWorkspace returns the workspace marked as the default, or the first workspace if none is marked
+func DefaultWorkspace(workspaces []TenantWorkspace) *TenantWorkspace {
+	for i := range workspaces {
+		if workspaces[i].Default {
+			return &workspaces[i]
+		}
+	}
+	if len(workspaces) > 0 {
+		return &workspaces[0]
+	}
+	return nil
+}
+
+// renderWorkspaces replaces the resources of a kind with a copy of the first resource for each workspace
+func renderWorkspaces(nodes []*yaml.RNode, kind string, workspaces []TenantWorkspace) ([]*yaml.RNode, error) {
+	if len(workspaces) == 0 {
+		return nodes, nil
+	}
+
+	var template *yaml.RNode
+	var outNodes []*yaml.RNode
+	for _, node := range nodes {
+		if node.GetKind() != kind {
+			outNodes = append(outNodes, node)
+		} else if template == nil {
+			template = node
+		}
+	}
+	if template == nil {
+		return nodes, nil
+	}
+
+	defaultWorkspace := DefaultWorkspace(workspaces)
+	for i, workspace := range workspaces {
+		node := template.Copy()
+		if err := node.SetName(workspace.Name); err != nil {
+			return nil, err
+		}
+		if err := node.PipeE(yaml.SetLabel(DeclarativeWorkspaceLabel, "true")); err != nil {
+			return nil, err
+		}
+		// Each setter returns the field it sets, so the setters cannot be chained in a single pipe
+		for _, annotation := range [][2]string{
+			{WorkspaceVersionAnnotation, workspace.Version},
+			{WorkloadClassesAnnotation, strings.Join(workspace.WorkloadClasses, ",")},
+			{DefaultWorkspaceAnnotation, strconv.FormatBool(&workspaces[i] == defaultWorkspace)},
+		} {
+			if err := node.PipeE(yaml.SetAnnotation(annotation[0], annotation[1])); err != nil {
+				return nil, err
+			}
+		}
+
+		limits := map[string]string{}
+		if workspace.MaxCPU != nil {
+			limits["maxCpu"] = workspace.MaxCPU.String()
+		}
+		if workspace.MaxMemory != nil {
+			limits["maxMem"] = workspace.MaxMemory.String()
+		}
+		if workspace.MaxPods != nil {
+			limits["maxPods"] = strconv.Itoa(int(*workspace.MaxPods))
+		}
+		if len(limits) > 0 {
+			spec, err := node.Pipe(yaml.LookupCreate(yaml.MappingNode, "spec", "limits"))
+			if err != nil {
+				return nil, err
+			}
+			if err := spec.PipeE(yaml.SetField("enabled", yaml.NewScalarRNode("true"))); err != nil {
+				return nil, err
+			}
+			for field, value := range limits {
+				if err := spec.PipeE(yaml.SetField(field, yaml.NewScalarRNode(value))); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if len(workspace.WorkloadClasses) > 0 {
+			if err := node.PipeE(
+				yaml.LookupCreate(yaml.MappingNode, "spec"),
+				yaml.SetField("workloadClasses", yaml.NewListRNode(workspace.WorkloadClasses...))); err != nil {
+				return nil, err
+			}
+		}
+
+		if workspace.FQDN != "" {
+			if err := node.PipeE(
+				yaml.LookupCreate(yaml.ScalarNode, "spec", "ingress", "fqdn"),
+				yaml.Set(yaml.NewStringRNode(workspace.FQDN))); err != nil {
+				return nil, err
+			}
+		}
 		outNodes = append(outNodes, node)
 	}
 
@@ -0,0 +1,85 @@
+package kube
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// ApplyResourceQuota creates or updates a resource quota
+func ApplyResourceQuota(quota *v1.ResourceQuota) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	quotas := clientSet.CoreV1().ResourceQuotas(quota.Namespace)
+	existing, err := quotas.Get(context.Background(), quota.Name, metav1.GetOptions{})
+	if k8serr.IsNotFound(err) {
+		if _, err := quotas.Create(context.Background(), quota, metav1.CreateOptions{}); err != nil {
+			return errors.Wrapf(err, "Failed to create resource quota %s", quota.Name)
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	existing.Labels = quota.Labels
+	existing.Spec = quota.Spec
+	if _, err := quotas.Update(context.Background(), existing, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "Failed to update resource quota %s", quota.Name)
+	}
+	return nil
+}
+
+// GetResourceQuota returns a resource quota, or nil if it does not exist
+func GetResourceQuota(ns string, name string) (*v1.ResourceQuota, error) {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	quota, err := clientSet.CoreV1().ResourceQuotas(ns).Get(context.Background(), name, metav1.GetOptions{})
+	if k8serr.IsNotFound(err) {
+		return nil, nil
+	}
+	return quota, err
+}
+
+// DeleteResourceQuota deletes a resource quota, if it exists
+func DeleteResourceQuota(ns string, name string) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	err := clientSet.CoreV1().ResourceQuotas(ns).Delete(context.Background(), name, metav1.DeleteOptions{})
+	if err != nil && !k8serr.IsNotFound(err) {
+		return errors.Wrapf(err, "Failed to delete resource quota %s", name)
+	}
+	return nil
+}
+
+// ApplyLimitRange creates or updates a limit range
+func ApplyLimitRange(limitRange *v1.LimitRange) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	limitRanges := clientSet.CoreV1().LimitRanges(limitRange.Namespace)
+	existing, err := limitRanges.Get(context.Background(), limitRange.Name, metav1.GetOptions{})
+	if k8serr.IsNotFound(err) {
+		if _, err := limitRanges.Create(context.Background(), limitRange, metav1.CreateOptions{}); err != nil {
+			return errors.Wrapf(err, "Failed to create limit range %s", limitRange.Name)
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	existing.Labels = limitRange.Labels
+	existing.Spec = limitRange.Spec
+	if _, err := limitRanges.Update(context.Background(), existing, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "Failed to update limit range %s", limitRange.Name)
+	}
+	return nil
+}
+
+// DeleteLimitRange deletes a limit range, if it exists
+func DeleteLimitRange(ns string, name string) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	err := clientSet.CoreV1().LimitRanges(ns).Delete(context.Background(), name, metav1.DeleteOptions{})
+	if err != nil && !k8serr.IsNotFound(err) {
+		return errors.Wrapf(err, "Failed to delete limit range %s", name)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package kube
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// ApplyClusterRole creates or updates a cluster role
+func ApplyClusterRole(role *rbacv1.ClusterRole) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	roles := clientSet.RbacV1().ClusterRoles()
+	existing, err := roles.Get(context.Background(), role.Name, metav1.GetOptions{})
+	if k8serr.IsNotFound(err) {
+		if _, err := roles.Create(context.Background(), role, metav1.CreateOptions{}); err != nil {
+			return errors.Wrapf(err, "Failed to create cluster role %s", role.Name)
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	existing.Labels = role.Labels
+	existing.Rules = role.Rules
+	if _, err := roles.Update(context.Background(), existing, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "Failed to update cluster role %s", role.Name)
+	}
+	return nil
+}
+
+// ApplyClusterRoleBinding creates or updates a cluster role binding. The role of an existing binding cannot change,
+// so the binding is recreated when it references another role.
+func ApplyClusterRoleBinding(binding *rbacv1.ClusterRoleBinding) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	bindings := clientSet.RbacV1().ClusterRoleBindings()
+	existing, err := bindings.Get(context.Background(), binding.Name, metav1.GetOptions{})
+	if err == nil && existing.RoleRef != binding.RoleRef {
+		if err := bindings.Delete(context.Background(), binding.Name, metav1.DeleteOptions{}); err != nil {
+			return errors.Wrapf(err, "Failed to delete cluster role binding %s", binding.Name)
+		}
+		err = k8serr.NewNotFound(rbacv1.Resource("clusterrolebindings"), binding.Name)
+	}
+	if k8serr.IsNotFound(err) {
+		if _, err := bindings.Create(context.Background(), binding, metav1.CreateOptions{}); err != nil {
+			return errors.Wrapf(err, "Failed to create cluster role binding %s", binding.Name)
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	existing.Labels = binding.Labels
+	existing.Subjects = binding.Subjects
+	if _, err := bindings.Update(context.Background(), existing, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "Failed to update cluster role binding %s", binding.Name)
+	}
+	return nil
+}
@@ -3,12 +3,16 @@ package kube
 import (
 	"context"
 	"io/ioutil"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/dynamic"
 
 	"k8s.io/kubectl/pkg/cmd/apply"
 	k8sdelete "k8s.io/kubectl/pkg/cmd/delete"
@@ -121,6 +125,35 @@ func ApplyYaml(yaml string) error {
 
 }
 
+// DeleteCollection deletes the resources of a custom resource type in a namespace which match a label selector.
+// Resource types which are not installed on the cluster are ignored.
+func DeleteCollection(resource schema.GroupVersionResource, ns string, selector string) error {
+	dynamicClient := dynamic.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	err := dynamicClient.Resource(resource).Namespace(ns).DeleteCollection(context.Background(), metav1.DeleteOptions{},
+		metav1.ListOptions{LabelSelector: selector})
+	if err != nil && !k8serr.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// DeleteResource deletes a custom resource, if it exists
+func DeleteResource(resource schema.GroupVersionResource, ns string, name string) error {
+	dynamicClient := dynamic.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	err := dynamicClient.Resource(resource).Namespace(ns).Delete(context.Background(), name, metav1.DeleteOptions{})
+	if err != nil && !k8serr.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// PatchResource applies a JSON merge patch to a custom resource
+func PatchResource(resource schema.GroupVersionResource, ns string, name string, patch []byte) error {
+	dynamicClient := dynamic.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	_, err := dynamicClient.Resource(resource).Namespace(ns).Patch(context.Background(), name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
 func ApplyUrlKustomize(url string) error {
 	f := util.NewFactory(RESTClientGetter{RestConfig: ctrl.GetConfigOrDie()})
 	mapper, err := f.ToRESTMapper()
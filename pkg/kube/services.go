@@ -16,6 +16,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sort"
 	"strings"
 	"time"
 
@@ -64,6 +65,16 @@ func IsPodRunning(ns string, prefix string) (bool, error) {
 	return false, nil
 }
 
+// ListPods returns the pods in a namespace which match a label selector
+func ListPods(ns string, selector string) ([]v1.Pod, error) {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	pods, err := clientSet.CoreV1().Pods(ns).List(context.Background(), metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
 func IsDeploymentCreatedByModela(ns string, name string) (bool, error) {
 	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
 	deployment, err := clientSet.AppsV1().Deployments(ns).Get(context.Background(), name, metav1.GetOptions{})
@@ -145,6 +156,35 @@ func IsNamespaceCreatedByOperator(name string, operator string) (bool, error) {
 	return false, nil
 }
 
+// GetNamespace returns a namespace, or nil if it does not exist
+func GetNamespace(name string) (*v1.Namespace, error) {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	namespace, err := clientSet.CoreV1().Namespaces().Get(context.Background(), name, metav1.GetOptions{})
+	if k8serr.IsNotFound(err) {
+		return nil, nil
+	}
+	return namespace, err
+}
+
+// UpdateNamespace updates the metadata of a namespace
+func UpdateNamespace(namespace *v1.Namespace) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	if _, err := clientSet.CoreV1().Namespaces().Update(context.Background(), namespace, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "Failed to update namespace %s", namespace.Name)
+	}
+	return nil
+}
+
+// ListNamespaces returns the namespaces which match a label selector
+func ListNamespaces(selector string) ([]v1.Namespace, error) {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	namespaces, err := clientSet.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	return namespaces.Items, nil
+}
+
 func DeleteNamespace(name string) error {
 	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
 	err := clientSet.CoreV1().Namespaces().Delete(context.Background(), name, metav1.DeleteOptions{})
@@ -155,6 +195,77 @@ func DeleteNamespace(name string) error {
 }
 
 func CreateOrUpdateSecret(ns string, name string, values map[string]string) error {
+	return CreateOrUpdateLabeledSecret(ns, name, nil, values)
+}
+
+// ManagedKeysAnnotation records the keys of a ConfigMap set by UpdateConfigMapData, so that the keys which are no
+// longer given can be removed
+const ManagedKeysAnnotation = "management.modela.ai/managed-keys"
+
+// UpdateConfigMapData sets the given keys in the data of a ConfigMap, and removes the keys set by a previous update
+// which are no longer given. It returns true if the data of the ConfigMap was changed.
+func UpdateConfigMapData(ns string, name string, values map[string]string) (bool, error) {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	configMap, err := clientSet.CoreV1().ConfigMaps(ns).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+
+	changed, keysChanged := updateManagedData(configMap, values)
+	if !changed && !keysChanged {
+		return false, nil
+	}
+
+	if _, err := clientSet.CoreV1().ConfigMaps(ns).Update(context.Background(), configMap, metav1.UpdateOptions{}); err != nil {
+		return false, errors.Errorf("Failed to update config map %s, err: %s", name, err)
+	}
+	return changed, nil
+}
+
+// updateManagedData sets the given keys in the data of a ConfigMap and removes the managed keys which are no longer
+// given. It returns whether the data changed, and whether the managed keys changed.
+func updateManagedData(configMap *v1.ConfigMap, values map[string]string) (bool, bool) {
+	var changed bool
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string)
+	}
+	for k, v := range values {
+		if current, ok := configMap.Data[k]; !ok || current != v {
+			configMap.Data[k] = v
+			changed = true
+		}
+	}
+
+	if managed := configMap.Annotations[ManagedKeysAnnotation]; managed != "" {
+		for _, k := range strings.Split(managed, ",") {
+			if _, ok := values[k]; ok {
+				continue
+			}
+			if _, ok := configMap.Data[k]; ok {
+				delete(configMap.Data, k)
+				changed = true
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	managed := strings.Join(keys, ",")
+	if configMap.Annotations[ManagedKeysAnnotation] == managed {
+		return changed, false
+	}
+	if configMap.Annotations == nil {
+		configMap.Annotations = make(map[string]string)
+	}
+	configMap.Annotations[ManagedKeysAnnotation] = managed
+	return changed, true
+}
+
+// CreateOrUpdateLabeledSecret creates or updates a secret, adding the given labels to it
+func CreateOrUpdateLabeledSecret(ns string, name string, labels map[string]string, values map[string]string) error {
 	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
 	secret, err := clientSet.CoreV1().Secrets(ns).Get(context.Background(), name, metav1.GetOptions{})
 	if k8serr.IsNotFound(err) {
@@ -162,6 +273,7 @@ func CreateOrUpdateSecret(ns string, name string, values map[string]string) erro
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      name,
 				Namespace: ns,
+				Labels:    labels,
 			},
 			StringData: values,
 		}
@@ -172,9 +284,18 @@ func CreateOrUpdateSecret(ns string, name string, values map[string]string) erro
 	} else if err != nil {
 		return errors.Errorf("Error getting namespace %s, err: %s", name, err)
 	} else {
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
 		for k, v := range values {
 			secret.Data[k] = []byte(v)
 		}
+		for k, v := range labels {
+			if secret.Labels == nil {
+				secret.Labels = make(map[string]string)
+			}
+			secret.Labels[k] = v
+		}
 		_, err = clientSet.CoreV1().Secrets(ns).Update(context.Background(), secret, metav1.UpdateOptions{})
 		if err != nil {
 			return errors.Errorf("Failed to create namespace %s, err: %s", name, err)
@@ -183,6 +304,49 @@ func CreateOrUpdateSecret(ns string, name string, values map[string]string) erro
 	return nil
 }
 
+// DeleteSecretKeys removes keys from the data of a secret, if it exists
+func DeleteSecretKeys(ns string, name string, keys ...string) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	secret, err := clientSet.CoreV1().Secrets(ns).Get(context.Background(), name, metav1.GetOptions{})
+	if k8serr.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var changed bool
+	for _, key := range keys {
+		if _, ok := secret.Data[key]; ok {
+			delete(secret.Data, key)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if _, err := clientSet.CoreV1().Secrets(ns).Update(context.Background(), secret, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "Failed to update secret %s", name)
+	}
+	return nil
+}
+
+// VerifySecretWritable checks that a secret may be created or updated by the operator, through a dry run of the request
+func VerifySecretWritable(ns string, name string) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	secret, err := clientSet.CoreV1().Secrets(ns).Get(context.Background(), name, metav1.GetOptions{})
+	if k8serr.IsNotFound(err) {
+		_, err = clientSet.CoreV1().Secrets(ns).Create(context.Background(), &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+		}, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+	} else if err == nil {
+		_, err = clientSet.CoreV1().Secrets(ns).Update(context.Background(), secret, metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}})
+	}
+	if err != nil {
+		return errors.Wrapf(err, "Secret %s/%s is not writable", ns, name)
+	}
+	return nil
+}
+
 func DeleteSecret(ns string, name string) error {
 	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
 	err := clientSet.CoreV1().Secrets(ns).Delete(context.Background(), name, metav1.DeleteOptions{})
@@ -192,6 +356,13 @@ func DeleteSecret(ns string, name string) error {
 	return err
 }
 
+// DeleteSecrets deletes the secrets in a namespace which match a label selector
+func DeleteSecrets(ns string, selector string) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	return clientSet.CoreV1().Secrets(ns).DeleteCollection(context.Background(), metav1.DeleteOptions{},
+		metav1.ListOptions{LabelSelector: selector})
+}
+
 func GetSecret(ns string, name string) (*v1.Secret, error) {
 	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
 	return clientSet.CoreV1().Secrets(ns).Get(context.Background(), name, metav1.GetOptions{})
@@ -261,3 +432,90 @@ func (p RESTClientGetter) ToRESTMapper() (meta.RESTMapper, error) {
 
 	return nil, fmt.Errorf("no restmapper")
 }
+
+// ListLabs returns the Labs in a namespace
+func ListLabs(ns string) ([]infra.Lab, error) {
+	k8sClient, err := client.New(config.GetConfigOrDie(), client.Options{
+		Scheme: ClientScheme,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var labs infra.LabList
+	if err := k8sClient.List(context.Background(), &labs, client.InNamespace(ns)); err != nil {
+		return nil, err
+	}
+	return labs.Items, nil
+}
+
+// ListServingSites returns the ServingSites in a namespace
+func ListServingSites(ns string) ([]infra.ServingSite, error) {
+	k8sClient, err := client.New(config.GetConfigOrDie(), client.Options{
+		Scheme: ClientScheme,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var sites infra.ServingSiteList
+	if err := k8sClient.List(context.Background(), &sites, client.InNamespace(ns)); err != nil {
+		return nil, err
+	}
+	return sites.Items, nil
+}
+
+// ListAccounts returns the Accounts in a namespace which match a label selector
+func ListAccounts(ns string, selector map[string]string) ([]infra.Account, error) {
+	k8sClient, err := client.New(config.GetConfigOrDie(), client.Options{
+		Scheme: ClientScheme,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var accounts infra.AccountList
+	if err := k8sClient.List(context.Background(), &accounts, client.InNamespace(ns), client.MatchingLabels(selector)); err != nil {
+		return nil, err
+	}
+	return accounts.Items, nil
+}
+
+// DeleteAccount deletes an Account, if it exists
+func DeleteAccount(ns string, name string) error {
+	k8sClient, err := client.New(config.GetConfigOrDie(), client.Options{
+		Scheme: ClientScheme,
+	})
+	if err != nil {
+		return err
+	}
+
+	account := &infra.Account{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name}}
+	return client.IgnoreNotFound(k8sClient.Delete(context.Background(), account))
+}
+
+// DeleteLab deletes a Lab, if it exists
+func DeleteLab(ns string, name string) error {
+	k8sClient, err := client.New(config.GetConfigOrDie(), client.Options{
+		Scheme: ClientScheme,
+	})
+	if err != nil {
+		return err
+	}
+
+	lab := &infra.Lab{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name}}
+	return client.IgnoreNotFound(k8sClient.Delete(context.Background(), lab))
+}
+
+// DeleteServingSite deletes a ServingSite, if it exists
+func DeleteServingSite(ns string, name string) error {
+	k8sClient, err := client.New(config.GetConfigOrDie(), client.Options{
+		Scheme: ClientScheme,
+	})
+	if err != nil {
+		return err
+	}
+
+	site := &infra.ServingSite{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name}}
+	return client.IgnoreNotFound(k8sClient.Delete(context.Background(), site))
+}
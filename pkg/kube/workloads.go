@@ -0,0 +1,421 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const restartPatchTemplate = `{"spec":{"template":{"metadata":{"annotations":{"kubectl.kubernetes.io/restartedAt":"%s"}}}}}`
+
+// RestartDeployment triggers a rolling restart of a deployment, in the same way as `kubectl rollout restart`
+func RestartDeployment(ns string, name string) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	patch := fmt.Sprintf(restartPatchTemplate, time.Now().Format(time.RFC3339))
+	_, err := clientSet.AppsV1().Deployments(ns).Patch(context.Background(), name, types.StrategicMergePatchType,
+		[]byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "Failed to restart deployment %s", name)
+	}
+	return nil
+}
+
+// RestartStatefulSet triggers a rolling restart of a stateful set
+func RestartStatefulSet(ns string, name string) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	patch := fmt.Sprintf(restartPatchTemplate, time.Now().Format(time.RFC3339))
+	_, err := clientSet.AppsV1().StatefulSets(ns).Patch(context.Background(), name, types.StrategicMergePatchType,
+		[]byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "Failed to restart stateful set %s", name)
+	}
+	return nil
+}
+
+// RestartReleaseWorkloads triggers a rolling restart of all deployments and stateful sets of a Helm release
+func RestartReleaseWorkloads(ns string, release string) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	selector := metav1.ListOptions{LabelSelector: "app.kubernetes.io/instance=" + release}
+
+	deployments, err := clientSet.AppsV1().Deployments(ns).List(context.Background(), selector)
+	if err != nil {
+		return err
+	}
+	for _, deployment := range deployments.Items {
+		if err := RestartDeployment(ns, deployment.Name); err != nil {
+			return err
+		}
+	}
+
+	statefulSets, err := clientSet.AppsV1().StatefulSets(ns).List(context.Background(), selector)
+	if err != nil {
+		return err
+	}
+	for _, statefulSet := range statefulSets.Items {
+		if err := RestartStatefulSet(ns, statefulSet.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExecCommand runs a command inside the container of a pod and returns its standard output
+func ExecCommand(ns string, pod string, container string, command []string) (string, error) {
+	return ExecCommandWithInput(ns, pod, container, command, "")
+}
+
+// ExecCommandWithInput runs a command inside the container of a pod with the given standard input, and returns its
+// standard output. Unlike the arguments of the command, the input is not recorded by the audit log of the API server.
+func ExecCommandWithInput(ns string, pod string, container string, command []string, input string) (string, error) {
+	config := ctrl.GetConfigOrDie()
+	clientSet := kubernetes.NewForConfigOrDie(config)
+	request := clientSet.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod).
+		Namespace(ns).
+		SubResource("exec").
+		VersionedParams(&v1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdin:     input != "",
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(config, "POST", request.URL())
+	if err != nil {
+		return "", err
+	}
+
+	var stdout, stderr bytes.Buffer
+	options := remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr}
+	if input != "" {
+		options.Stdin = strings.NewReader(input)
+	}
+	if err := executor.Stream(options); err != nil {
+		return stdout.String(), errors.Wrapf(err, "Failed to execute command in pod %s: %s", pod, stderr.String())
+	}
+	return stdout.String(), nil
+}
+
+// ApplyCronJob creates a cron job, or replaces the spec of the cron job if it already exists
+func ApplyCronJob(cronJob *batchv1.CronJob) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	cronJobs := clientSet.BatchV1().CronJobs(cronJob.Namespace)
+	existing, err := cronJobs.Get(context.Background(), cronJob.Name, metav1.GetOptions{})
+	if k8serr.IsNotFound(err) {
+		if _, err := cronJobs.Create(context.Background(), cronJob, metav1.CreateOptions{}); err != nil {
+			return errors.Wrapf(err, "Failed to create cron job %s", cronJob.Name)
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	existing.Labels = cronJob.Labels
+	existing.Spec = cronJob.Spec
+	if _, err := cronJobs.Update(context.Background(), existing, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "Failed to update cron job %s", cronJob.Name)
+	}
+	return nil
+}
+
+// DeleteCronJob deletes a cron job, if it exists
+func DeleteCronJob(ns string, name string) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	err := clientSet.BatchV1().CronJobs(ns).Delete(context.Background(), name, metav1.DeleteOptions{})
+	if err != nil && !k8serr.IsNotFound(err) {
+		return errors.Wrapf(err, "Failed to delete cron job %s", name)
+	}
+	return nil
+}
+
+// GetJob returns a job, or nil if the job does not exist
+func GetJob(ns string, name string) (*batchv1.Job, error) {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	job, err := clientSet.BatchV1().Jobs(ns).Get(context.Background(), name, metav1.GetOptions{})
+	if k8serr.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// CreateJob creates a job. Jobs are immutable, so existing jobs are left unchanged.
+func CreateJob(job *batchv1.Job) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	_, err := clientSet.BatchV1().Jobs(job.Namespace).Create(context.Background(), job, metav1.CreateOptions{})
+	if err != nil && !k8serr.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "Failed to create job %s", job.Name)
+	}
+	return nil
+}
+
+// DeleteJob deletes a job and its pods, if the job exists
+func DeleteJob(ns string, name string) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	propagation := metav1.DeletePropagationBackground
+	err := clientSet.BatchV1().Jobs(ns).Delete(context.Background(), name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil && !k8serr.IsNotFound(err) {
+		return errors.Wrapf(err, "Failed to delete job %s", name)
+	}
+	return nil
+}
+
+// JobFinished returns if a job has completed or failed
+func JobFinished(job *batchv1.Job) (bool, batchv1.JobConditionType) {
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) && condition.Status == v1.ConditionTrue {
+			return true, condition.Type
+		}
+	}
+	return false, ""
+}
+
+// CreateServiceAccount creates a service account, if it does not exist
+func CreateServiceAccount(ns string, name string, labels map[string]string) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	_, err := clientSet.CoreV1().ServiceAccounts(ns).Create(context.Background(), &v1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns, Labels: labels},
+	}, metav1.CreateOptions{})
+	if err != nil && !k8serr.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "Failed to create service account %s", name)
+	}
+	return nil
+}
+
+// CreatePersistentVolumeClaim creates a persistent volume claim, if it does not exist
+func CreatePersistentVolumeClaim(claim *v1.PersistentVolumeClaim) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	_, err := clientSet.CoreV1().PersistentVolumeClaims(claim.Namespace).Create(context.Background(), claim, metav1.CreateOptions{})
+	if err != nil && !k8serr.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "Failed to create persistent volume claim %s", claim.Name)
+	}
+	return nil
+}
+
+// SuspendedReplicasAnnotation records the replicas of a workload, or the suspend flag of a cron job, before it
+// was suspended by the operator
+const SuspendedReplicasAnnotation = "management.modela.ai/suspended-replicas"
+
+// SuspendWorkloads scales all deployments and stateful sets of a namespace to zero and suspends its cron jobs. The
+// previous state of each workload is recorded in an annotation, which is read by ResumeWorkloads.
+func SuspendWorkloads(ns string) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	ctx := context.Background()
+
+	deployments, err := clientSet.AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, deployment := range deployments.Items {
+		if !suspendReplicas(&deployment.ObjectMeta, &deployment.Spec.Replicas) {
+			continue
+		}
+		if _, err := clientSet.AppsV1().Deployments(ns).Update(ctx, &deployment, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "Failed to suspend deployment %s", deployment.Name)
+		}
+	}
+
+	statefulSets, err := clientSet.AppsV1().StatefulSets(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, statefulSet := range statefulSets.Items {
+		if !suspendReplicas(&statefulSet.ObjectMeta, &statefulSet.Spec.Replicas) {
+			continue
+		}
+		if _, err := clientSet.AppsV1().StatefulSets(ns).Update(ctx, &statefulSet, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "Failed to suspend stateful set %s", statefulSet.Name)
+		}
+	}
+
+	cronJobs, err := clientSet.BatchV1().CronJobs(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, cronJob := range cronJobs.Items {
+		if !suspendCronJob(&cronJob.ObjectMeta, &cronJob.Spec.Suspend) {
+			continue
+		}
+		if _, err := clientSet.BatchV1().CronJobs(ns).Update(ctx, &cronJob, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "Failed to suspend cron job %s", cronJob.Name)
+		}
+	}
+
+	return nil
+}
+
+// ResumeWorkloads restores the workloads of a namespace suspended by SuspendWorkloads to their previous state
+func ResumeWorkloads(ns string) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	ctx := context.Background()
+
+	deployments, err := clientSet.AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, deployment := range deployments.Items {
+		if !resumeReplicas(&deployment.ObjectMeta, &deployment.Spec.Replicas) {
+			continue
+		}
+		if _, err := clientSet.AppsV1().Deployments(ns).Update(ctx, &deployment, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "Failed to resume deployment %s", deployment.Name)
+		}
+	}
+
+	statefulSets, err := clientSet.AppsV1().StatefulSets(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, statefulSet := range statefulSets.Items {
+		if !resumeReplicas(&statefulSet.ObjectMeta, &statefulSet.Spec.Replicas) {
+			continue
+		}
+		if _, err := clientSet.AppsV1().StatefulSets(ns).Update(ctx, &statefulSet, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "Failed to resume stateful set %s", statefulSet.Name)
+		}
+	}
+
+	cronJobs, err := clientSet.BatchV1().CronJobs(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, cronJob := range cronJobs.Items {
+		if !resumeCronJob(&cronJob.ObjectMeta, &cronJob.Spec.Suspend) {
+			continue
+		}
+		if _, err := clientSet.BatchV1().CronJobs(ns).Update(ctx, &cronJob, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "Failed to resume cron job %s", cronJob.Name)
+		}
+	}
+
+	return nil
+}
+
+// SuspendDeployment scales a single deployment to zero in the same way as SuspendWorkloads, if it exists
+func SuspendDeployment(ns string, name string) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	deployment, err := clientSet.AppsV1().Deployments(ns).Get(context.Background(), name, metav1.GetOptions{})
+	if k8serr.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !suspendReplicas(&deployment.ObjectMeta, &deployment.Spec.Replicas) {
+		return nil
+	}
+	if _, err := clientSet.AppsV1().Deployments(ns).Update(context.Background(), deployment, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "Failed to suspend deployment %s", name)
+	}
+	return nil
+}
+
+// ResumeDeployment restores a deployment suspended by SuspendDeployment to its previous replicas, if it exists
+func ResumeDeployment(ns string, name string) error {
+	clientSet := kubernetes.NewForConfigOrDie(ctrl.GetConfigOrDie())
+	deployment, err := clientSet.AppsV1().Deployments(ns).Get(context.Background(), name, metav1.GetOptions{})
+	if k8serr.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !resumeReplicas(&deployment.ObjectMeta, &deployment.Spec.Replicas) {
+		return nil
+	}
+	if _, err := clientSet.AppsV1().Deployments(ns).Update(context.Background(), deployment, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "Failed to resume deployment %s", name)
+	}
+	return nil
+}
+
+// suspendReplicas records the replicas of a workload and scales it to zero. Unset replicas are recorded as an empty
+// annotation. It returns false if the workload is already suspended.
+func suspendReplicas(meta *metav1.ObjectMeta, replicas **int32) bool {
+	if _, ok := meta.Annotations[SuspendedReplicasAnnotation]; ok && *replicas != nil && **replicas == 0 {
+		return false
+	}
+
+	if _, ok := meta.Annotations[SuspendedReplicasAnnotation]; !ok {
+		previous := ""
+		if *replicas != nil {
+			previous = strconv.Itoa(int(**replicas))
+		}
+		if meta.Annotations == nil {
+			meta.Annotations = make(map[string]string)
+		}
+		meta.Annotations[SuspendedReplicasAnnotation] = previous
+	}
+
+	zero := int32(0)
+	*replicas = &zero
+	return true
+}
+
+// resumeReplicas restores the replicas recorded by suspendReplicas. It returns false if the workload is not suspended.
+func resumeReplicas(meta *metav1.ObjectMeta, replicas **int32) bool {
+	previous, ok := meta.Annotations[SuspendedReplicasAnnotation]
+	if !ok {
+		return false
+	}
+
+	*replicas = nil
+	if count, err := strconv.Atoi(previous); err == nil {
+		restored := int32(count)
+		*replicas = &restored
+	}
+	delete(meta.Annotations, SuspendedReplicasAnnotation)
+	return true
+}
+
+// suspendCronJob records the suspend flag of a cron job and suspends it. An unset flag is recorded as an empty
+// annotation. It returns false if the cron job is already suspended by the operator.
+func suspendCronJob(meta *metav1.ObjectMeta, suspend **bool) bool {
+	if _, ok := meta.Annotations[SuspendedReplicasAnnotation]; ok {
+		return false
+	}
+
+	previous := ""
+	if *suspend != nil {
+		previous = strconv.FormatBool(**suspend)
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	meta.Annotations[SuspendedReplicasAnnotation] = previous
+
+	suspended := true
+	*suspend = &suspended
+	return true
+}
+
+// resumeCronJob restores the suspend flag recorded by suspendCronJob. It returns false if the cron job is not
+// suspended by the operator.
+func resumeCronJob(meta *metav1.ObjectMeta, suspend **bool) bool {
+	previous, ok := meta.Annotations[SuspendedReplicasAnnotation]
+	if !ok {
+		return false
+	}
+
+	*suspend = nil
+	if suspended, err := strconv.ParseBool(previous); err == nil {
+		*suspend = &suspended
+	}
+	delete(meta.Annotations, SuspendedReplicasAnnotation)
+	return true
+}
@@ -0,0 +1,110 @@
+package kube
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+	"testing"
+)
+
+func int32Ptr(value int32) *int32 {
+	return &value
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
+
+func TestSuspendAndResumeReplicas(t *testing.T) {
+	for name, test := range map[string]struct {
+		annotations map[string]string
+		replicas    *int32
+		suspended   bool
+		resumed     *int32
+	}{
+		"scaled workload": {replicas: int32Ptr(3), suspended: true, resumed: int32Ptr(3)},
+		"nil replicas":    {suspended: true},
+		"scaled to zero":  {replicas: int32Ptr(0), suspended: true, resumed: int32Ptr(0)},
+		"other annotations": {
+			annotations: map[string]string{"app": "modela"},
+			replicas:    int32Ptr(2),
+			suspended:   true,
+			resumed:     int32Ptr(2),
+		},
+		"already suspended": {
+			annotations: map[string]string{SuspendedReplicasAnnotation: "2"},
+			replicas:    int32Ptr(0),
+			resumed:     int32Ptr(2),
+		},
+		"scaled up while suspended": {
+			annotations: map[string]string{SuspendedReplicasAnnotation: "2"},
+			replicas:    int32Ptr(1),
+			suspended:   true,
+			resumed:     int32Ptr(2),
+		},
+	} {
+		meta := metav1.ObjectMeta{Annotations: test.annotations}
+		replicas := test.replicas
+		if suspended := suspendReplicas(&meta, &replicas); suspended != test.suspended {
+			t.Errorf("%s: suspendReplicas returned %t, expected %t", name, suspended, test.suspended)
+		}
+		if replicas == nil || *replicas != 0 {
+			t.Errorf("%s: the replicas of the suspended workload are %v, expected 0", name, replicas)
+		}
+		if suspended := suspendReplicas(&meta, &replicas); suspended {
+			t.Errorf("%s: suspendReplicas suspended the workload twice", name)
+		}
+
+		if resumed := resumeReplicas(&meta, &replicas); !resumed {
+			t.Errorf("%s: resumeReplicas did not resume the workload", name)
+		}
+		if !reflect.DeepEqual(replicas, test.resumed) {
+			t.Errorf("%s: the replicas of the resumed workload are %v, expected %v", name, replicas, test.resumed)
+		}
+		if _, ok := meta.Annotations[SuspendedReplicasAnnotation]; ok {
+			t.Errorf("%s: the annotation of the resumed workload was not removed", name)
+		}
+		if resumed := resumeReplicas(&meta, &replicas); resumed {
+			t.Errorf("%s: resumeReplicas resumed the workload twice", name)
+		}
+	}
+}
+
+func TestSuspendAndResumeCronJob(t *testing.T) {
+	for name, test := range map[string]struct {
+		annotations map[string]string
+		suspend     *bool
+		suspended   bool
+		resumed     *bool
+	}{
+		"running cron job":  {suspend: boolPtr(false), suspended: true, resumed: boolPtr(false)},
+		"nil suspend":       {suspended: true},
+		"already suspended": {suspend: boolPtr(true), suspended: true, resumed: boolPtr(true)},
+		"suspended by the operator": {
+			annotations: map[string]string{SuspendedReplicasAnnotation: "false"},
+			suspend:     boolPtr(true),
+			resumed:     boolPtr(false),
+		},
+	} {
+		meta := metav1.ObjectMeta{Annotations: test.annotations}
+		suspend := test.suspend
+		if suspended := suspendCronJob(&meta, &suspend); suspended != test.suspended {
+			t.Errorf("%s: suspendCronJob returned %t, expected %t", name, suspended, test.suspended)
+		}
+		if suspend == nil || !*suspend {
+			t.Errorf("%s: the suspended cron job is not suspended", name)
+		}
+		if suspended := suspendCronJob(&meta, &suspend); suspended {
+			t.Errorf("%s: suspendCronJob suspended the cron job twice", name)
+		}
+
+		if resumed := resumeCronJob(&meta, &suspend); !resumed {
+			t.Errorf("%s: resumeCronJob did not resume the cron job", name)
+		}
+		if !reflect.DeepEqual(suspend, test.resumed) {
+			t.Errorf("%s: the suspend flag of the resumed cron job is %v, expected %v", name, suspend, test.resumed)
+		}
+		if _, ok := meta.Annotations[SuspendedReplicasAnnotation]; ok {
+			t.Errorf("%s: the annotation of the resumed cron job was not removed", name)
+		}
+	}
+}
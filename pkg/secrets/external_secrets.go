@@ -0,0 +1,185 @@
+package secrets
+
+import (
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"path"
+	"sigs.k8s.io/yaml"
+	"time"
+)
+
+var (
+	pushSecretResource     = schema.GroupVersionResource{Group: "external-secrets.io", Version: "v1alpha1", Resource: "pushsecrets"}
+	externalSecretResource = schema.GroupVersionResource{Group: "external-secrets.io", Version: "v1beta1", Resource: "externalsecrets"}
+)
+
+// ExternalSecretsStore stores secrets in an external provider through the External Secrets Operator. Each secret is
+// written to a source Secret in the modela-system namespace, pushed to the provider by a PushSecret, and
+// synchronized into the namespace of its tenant by an ExternalSecret. The provider remains the source of truth
+// for the workloads, while the source Secrets are only read by the operator.
+type ExternalSecretsStore struct {
+	Operator        string
+	StoreName       string
+	RemotePrefix    string
+	RefreshInterval time.Duration
+}
+
+func NewExternalSecretsStore(operator string, spec managementv1.ExternalSecretsStoreSpec) *ExternalSecretsStore {
+	store := &ExternalSecretsStore{
+		Operator:        operator,
+		StoreName:       spec.ClusterSecretStoreName,
+		RemotePrefix:    spec.RemotePrefix,
+		RefreshInterval: time.Hour,
+	}
+	if store.RemotePrefix == "" {
+		store.RemotePrefix = "modela"
+	}
+	if spec.RefreshInterval != nil {
+		store.RefreshInterval = spec.RefreshInterval.Duration
+	}
+	return store
+}
+
+func (e ExternalSecretsStore) Type() managementv1.SecretStoreType {
+	return managementv1.SecretStoreExternalSecrets
+}
+
+// ApplyTenant is a no-op, as the ExternalSecrets of a tenant are created in the namespace of the tenant
+func (e ExternalSecretsStore) ApplyTenant(_ string) error {
+	return nil
+}
+
+func (e ExternalSecretsStore) ApplySecret(key string, value map[string]interface{}) error {
+	namespace, name := SecretLocation(key)
+	sourceName := sourceSecretName(namespace, name)
+
+	values := stringValues(value)
+	labels := map[string]string{"management.modela.ai/operator": e.Operator}
+	if namespace != SystemNamespace {
+		labels["management.modela.ai/tenant"] = namespace
+	}
+	if err := kube.CreateOrUpdateLabeledSecret(SystemNamespace, sourceName, labels, values); err != nil {
+		return err
+	}
+
+	for _, object := range e.resources(key, values) {
+		out, err := yaml.Marshal(object)
+		if err != nil {
+			return err
+		}
+		if err := kube.ApplyYaml(string(out)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resources returns the PushSecret of a secret, and the ExternalSecret which synchronizes it into the namespace of
+// its tenant
+func (e ExternalSecretsStore) resources(key string, values map[string]string) []map[string]interface{} {
+	namespace, name := SecretLocation(key)
+	sourceName := sourceSecretName(namespace, name)
+	remoteKey := path.Join(e.RemotePrefix, key)
+	storeRef := map[string]interface{}{"name": e.StoreName, "kind": "ClusterSecretStore"}
+	// The properties are sorted, so that the resources are only changed when the properties of the secret change
+	var pushData, data []interface{}
+	for _, property := range sets.StringKeySet(values).List() {
+		pushData = append(pushData, map[string]interface{}{
+			"match": map[string]interface{}{
+				"secretKey": property,
+				"remoteRef": map[string]interface{}{"remoteKey": remoteKey, "property": property},
+			},
+		})
+		data = append(data, map[string]interface{}{
+			"secretKey": property,
+			"remoteRef": map[string]interface{}{"key": remoteKey, "property": property},
+		})
+	}
+
+	objects := []map[string]interface{}{
+		{
+			"apiVersion": "external-secrets.io/v1alpha1",
+			"kind":       "PushSecret",
+			"metadata":   e.objectMeta(sourceName, SystemNamespace, namespace),
+			"spec": map[string]interface{}{
+				"refreshInterval": e.RefreshInterval.String(),
+				"deletionPolicy":  "Delete",
+				"secretStoreRefs": []interface{}{storeRef},
+				"selector":        map[string]interface{}{"secret": map[string]interface{}{"name": sourceName}},
+				"data":            pushData,
+			},
+		},
+	}
+
+	// Secrets of the system namespace are already available to the workloads through the source Secret
+	if namespace != SystemNamespace {
+		objects = append(objects, map[string]interface{}{
+			"apiVersion": "external-secrets.io/v1beta1",
+			"kind":       "ExternalSecret",
+			"metadata":   e.objectMeta(name, namespace, namespace),
+			"spec": map[string]interface{}{
+				"refreshInterval": e.RefreshInterval.String(),
+				"secretStoreRef":  storeRef,
+				"target":          map[string]interface{}{"name": name, "creationPolicy": "Owner"},
+				"data":            data,
+			},
+		})
+	}
+
+	return objects
+}
+
+// DeleteSecret deletes the PushSecret of a secret, which removes the secret from the external provider, along
+// with its source Secret and ExternalSecret
+func (e ExternalSecretsStore) DeleteSecret(key string) error {
+	namespace, name := SecretLocation(key)
+	sourceName := sourceSecretName(namespace, name)
+	if namespace != SystemNamespace {
+		if err := kube.DeleteResource(externalSecretResource, namespace, name); err != nil {
+			return err
+		}
+	}
+
+	if err := kube.DeleteResource(pushSecretResource, SystemNamespace, sourceName); err != nil {
+		return err
+	}
+	return kube.DeleteSecret(SystemNamespace, sourceName)
+}
+
+func (e ExternalSecretsStore) Location(key string) string {
+	return path.Join(e.RemotePrefix, key)
+}
+
+// SuspendTenant is a no-op
+func (e ExternalSecretsStore) SuspendTenant(_ string) error {
+	return nil
+}
+
+// DeleteTenant deletes the PushSecrets of a tenant, which removes the secrets of the tenant from the external
+// provider, along with their source Secrets. The ExternalSecrets are removed with the namespace of the tenant.
+func (e ExternalSecretsStore) DeleteTenant(tenant string) error {
+	selector := "management.modela.ai/tenant=" + tenant
+	if err := kube.DeleteCollection(pushSecretResource, SystemNamespace, selector); err != nil {
+		return err
+	}
+	return kube.DeleteSecrets(SystemNamespace, selector)
+}
+
+// sourceSecretName returns the name of the source Secret of a secret, which is suffixed with the tenant of the secret
+func sourceSecretName(namespace string, name string) string {
+	if namespace == SystemNamespace {
+		return name
+	}
+	return name + "-" + namespace
+}
+
+func (e ExternalSecretsStore) objectMeta(name string, namespace string, tenant string) map[string]interface{} {
+	labels := map[string]interface{}{"management.modela.ai/operator": e.Operator}
+	if tenant != SystemNamespace {
+		labels["management.modela.ai/tenant"] = tenant
+	}
+	return map[string]interface{}{"name": name, "namespace": namespace, "labels": labels}
+}
@@ -0,0 +1,39 @@
+package secrets
+
+import (
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+	"reflect"
+	"sigs.k8s.io/yaml"
+	"testing"
+)
+
+func TestExternalSecretsResourcesAreStable(t *testing.T) {
+	store := NewExternalSecretsStore("modela", managementv1.ExternalSecretsStoreSpec{ClusterSecretStoreName: "vault"})
+	values := map[string]string{"username": "u", "password": "p", "host": "h", "port": "5432", "database": "d"}
+
+	first, err := yaml.Marshal(store.resources("tenant/modela/connections/postgres", values))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		next, err := yaml.Marshal(store.resources("tenant/modela/connections/postgres", values))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(next) != string(first) {
+			t.Fatalf("The resources of the same secret changed between renders:\n%s\n%s", first, next)
+		}
+	}
+
+	resources := store.resources("tenant/modela/connections/postgres", values)
+	if len(resources) != 2 {
+		t.Fatalf("Expected a PushSecret and an ExternalSecret, got %d resources", len(resources))
+	}
+	var properties []string
+	for _, item := range resources[1]["spec"].(map[string]interface{})["data"].([]interface{}) {
+		properties = append(properties, item.(map[string]interface{})["secretKey"].(string))
+	}
+	if expected := []string{"database", "host", "password", "port", "username"}; !reflect.DeepEqual(properties, expected) {
+		t.Errorf("The properties of the ExternalSecret are %v, expected %v", properties, expected)
+	}
+}
@@ -0,0 +1,59 @@
+package secrets
+
+import (
+	"fmt"
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+	"github.com/metaprov/modela-operator/pkg/kube"
+)
+
+// KubernetesStore stores secrets as Kubernetes Secrets in the namespace of their tenant. It requires no additional
+// infrastructure, and is intended for development clusters where Vault is not installed.
+type KubernetesStore struct{}
+
+func (k KubernetesStore) Type() managementv1.SecretStoreType {
+	return managementv1.SecretStoreKubernetes
+}
+
+// ApplyTenant is a no-op, as the secrets of a tenant are only accessible from the namespace of the tenant
+func (k KubernetesStore) ApplyTenant(_ string) error {
+	return nil
+}
+
+// ApplySecret stores a secret, labelling the secrets of a tenant with the tenant so that they can be deleted with it
+func (k KubernetesStore) ApplySecret(key string, value map[string]interface{}) error {
+	namespace, name := SecretLocation(key)
+	var labels map[string]string
+	if namespace != SystemNamespace {
+		labels = map[string]string{"management.modela.ai/tenant": namespace}
+	}
+	return kube.CreateOrUpdateLabeledSecret(namespace, name, labels, stringValues(value))
+}
+
+func (k KubernetesStore) DeleteSecret(key string) error {
+	namespace, name := SecretLocation(key)
+	return kube.DeleteSecret(namespace, name)
+}
+
+func (k KubernetesStore) Location(key string) string {
+	namespace, name := SecretLocation(key)
+	return namespace + "/" + name
+}
+
+// SuspendTenant is a no-op
+func (k KubernetesStore) SuspendTenant(_ string) error {
+	return nil
+}
+
+// DeleteTenant deletes the secrets of a tenant from the namespace of the tenant, which is not deleted when the
+// tenant is retained
+func (k KubernetesStore) DeleteTenant(tenant string) error {
+	return kube.DeleteSecrets(tenant, "management.modela.ai/tenant="+tenant)
+}
+
+func stringValues(value map[string]interface{}) map[string]string {
+	values := make(map[string]string, len(value))
+	for k, v := range value {
+		values[k] = fmt.Sprint(v)
+	}
+	return values
+}
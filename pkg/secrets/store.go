@@ -0,0 +1,58 @@
+package secrets
+
+import (
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// SystemNamespace is the namespace which holds the secrets that do not belong to a tenant
+const SystemNamespace = "modela-system"
+
+// SecretStore stores the secrets generated by the Modela Operator. Secrets are identified by a key; the keys of
+// tenant secrets have the form tenant/<tenant>/<path>, such as tenant/modela/connections/minio-connection.
+type SecretStore interface {
+	// Type returns the type of the store, which is passed to the Modela workloads through the modela-config ConfigMap
+	Type() managementv1.SecretStoreType
+	// ApplyTenant grants the workloads of a tenant access to the secrets of the tenant
+	ApplyTenant(tenant string) error
+	// ApplySecret creates or updates a secret
+	ApplySecret(key string, value map[string]interface{}) error
+	// DeleteSecret deletes a secret, if it exists
+	DeleteSecret(key string) error
+	// Location returns where the secret identified by a key is stored, such as the path of the secret in Vault
+	Location(key string) string
+	// SuspendTenant revokes the access of the workloads of a tenant without removing its secrets. ApplyTenant
+	// grants the access again. Stores whose secrets are only read by the workloads of the tenant need not revoke
+	// anything, as the workloads are stopped while the tenant is suspended.
+	SuspendTenant(tenant string) error
+	// DeleteTenant removes the secrets of a tenant and revokes the access of its workloads
+	DeleteTenant(tenant string) error
+}
+
+// GetSecretStore returns the secret store selected by a Modela installation
+func GetSecretStore(modela *managementv1.Modela) (SecretStore, error) {
+	spec := modela.Spec.SecretStore
+	switch spec.Type {
+	case "", managementv1.SecretStoreVault:
+		return VaultStore{Modela: modela}, nil
+	case managementv1.SecretStoreKubernetes:
+		return KubernetesStore{}, nil
+	case managementv1.SecretStoreExternalSecrets:
+		if spec.ExternalSecrets == nil || spec.ExternalSecrets.ClusterSecretStoreName == "" {
+			return nil, errors.New("the ExternalSecrets secret store requires the name of a ClusterSecretStore")
+		}
+		return NewExternalSecretsStore(modela.Name, *spec.ExternalSecrets), nil
+	}
+	return nil, errors.Errorf("unknown secret store %s", spec.Type)
+}
+
+// SecretLocation returns the namespace and name of the Kubernetes Secret which holds a secret. Tenant secrets are
+// located in the namespace of their tenant, and all other secrets in the modela-system namespace.
+func SecretLocation(key string) (string, string) {
+	namespace, path := SystemNamespace, key
+	if parts := strings.SplitN(key, "/", 3); len(parts) == 3 && parts[0] == "tenant" {
+		namespace, path = parts[1], parts[2]
+	}
+	return namespace, "modela-" + strings.ReplaceAll(strings.Trim(path, "/"), "/", "-")
+}
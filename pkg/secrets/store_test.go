@@ -0,0 +1,17 @@
+package secrets
+
+import "testing"
+
+func TestSecretLocation(t *testing.T) {
+	for key, expected := range map[string][2]string{
+		"jwt-secret":                                 {"modela-system", "modela-jwt-secret"},
+		"tenant/modela/accounts/admin":               {"modela", "modela-accounts-admin"},
+		"tenant/modela/connections/minio-connection": {"modela", "modela-connections-minio-connection"},
+		"tenant/modela/api-key-secret":               {"modela", "modela-api-key-secret"},
+	} {
+		namespace, name := SecretLocation(key)
+		if namespace != expected[0] || name != expected[1] {
+			t.Errorf("SecretLocation(%q) = %s/%s, expected %s/%s", key, namespace, name, expected[0], expected[1])
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package secrets
+
+import (
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+	"github.com/metaprov/modela-operator/pkg/vault"
+	"path"
+	"strings"
+)
+
+// VaultStore stores secrets in the KVv2 secret engine of the Vault used by a Modela installation. The workloads of
+// each tenant are granted access to the secrets of the tenant through a dedicated role.
+type VaultStore struct {
+	Modela *managementv1.Modela
+}
+
+func (v VaultStore) Type() managementv1.SecretStoreType {
+	return managementv1.SecretStoreVault
+}
+
+func (v VaultStore) ApplyTenant(tenant string) error {
+	return vault.ApplyTenantRole(v.Modela, tenant)
+}
+
+func (v VaultStore) ApplySecret(key string, value map[string]interface{}) error {
+	return vault.ApplySecret(v.Modela, key, value)
+}
+
+func (v VaultStore) DeleteSecret(key string) error {
+	return vault.DeleteSecret(v.Modela, key)
+}
+
+func (v VaultStore) Location(key string) string {
+	return path.Join(strings.Trim(v.Modela.Spec.Vault.MountPath, "/"), "data", key)
+}
+
+func (v VaultStore) SuspendTenant(tenant string) error {
+	return vault.DeleteTenantRole(v.Modela, tenant)
+}
+
+func (v VaultStore) DeleteTenant(tenant string) error {
+	if err := vault.DeleteTenantRole(v.Modela, tenant); err != nil {
+		return err
+	}
+	return vault.DestroyTenantSecrets(v.Modela, tenant)
+}
@@ -0,0 +1,234 @@
+package vault
+
+import (
+	"bytes"
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"fmt"
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+	"github.com/metaprov/modela-operator/pkg/kube"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/openpgp"
+	pgparmor "golang.org/x/crypto/openpgp/armor"
+	// RIPEMD160 is the hash assumed by OpenPGP for public keys which do not declare preferred hashes
+	_ "golang.org/x/crypto/ripemd160"
+	"io"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"sort"
+	"strings"
+)
+
+var (
+	// UnsealKeysUnavailableError is returned by a KeyCustodian when the keys it holds cannot be read by the operator
+	UnsealKeysUnavailableError = errors.New("vault unseal keys are not retrievable by the operator")
+)
+
+// KeyCustodian stores the key shares produced when a Vault server is initialized
+type KeyCustodian interface {
+	// StoreKeys persists the key shares. When using the transit seal, the shares are the recovery keys
+	StoreKeys(keys []string) error
+	// UnsealKeys returns the stored key shares which can be used to unseal Vault. A nil slice indicates
+	// that Vault does not need to be unsealed by the operator.
+	UnsealKeys() ([]string, error)
+	// Verify checks that key shares can be stored, so that Vault is only initialized when its keys will not be lost
+	Verify() error
+}
+
+// GetKeyCustodian returns the KeyCustodian of the key custody backend specified by a Modela resource
+func GetKeyCustodian(modela *managementv1.Modela) (KeyCustodian, error) {
+	custody := modela.Spec.Vault.KeyCustody
+	switch custody.Backend {
+	case "", managementv1.VaultKeyCustodyKubernetesSecret:
+		return SecretKeyCustodian{Namespace: "modela-system", Name: "vault-keys"}, nil
+	case managementv1.VaultKeyCustodySealedFile:
+		if custody.SealedFile == nil || len(custody.SealedFile.PublicKeys) == 0 {
+			return nil, errors.New("sealed file key custody requires at least one public key")
+		}
+		name := custody.SealedFile.SecretName
+		if name == "" {
+			name = "vault-sealed-keys"
+		}
+		return SealedFileKeyCustodian{
+			Namespace:  "modela-system",
+			Name:       name,
+			Encryption: custody.SealedFile.Encryption,
+			PublicKeys: custody.SealedFile.PublicKeys,
+		}, nil
+	case managementv1.VaultKeyCustodyTransit:
+		if custody.Transit == nil {
+			return nil, errors.New("transit key custody requires a transit configuration")
+		}
+		return TransitKeyCustodian{
+			RecoveryKeys: SecretKeyCustodian{Namespace: "modela-system", Name: "vault-recovery-keys"},
+		}, nil
+	}
+	return nil, errors.Errorf("unknown key custody backend %s", custody.Backend)
+}
+
+// SecretKeyCustodian stores key shares in plaintext inside a Kubernetes secret
+type SecretKeyCustodian struct {
+	Namespace string
+	Name      string
+}
+
+func (s SecretKeyCustodian) StoreKeys(keys []string) error {
+	values := make(map[string]string)
+	for i, key := range keys {
+		values[fmt.Sprintf("key-%d", i)] = key
+	}
+	if len(keys) > 0 {
+		// Retain the legacy key for installations which expect a single key share
+		values["key"] = keys[0]
+	}
+
+	if err := kube.CreateOrUpdateSecret(s.Namespace, s.Name, values); err != nil {
+		return errors.Wrap(err, "Failed to create Vault keys secret")
+	}
+	return nil
+}
+
+func (s SecretKeyCustodian) Verify() error {
+	return kube.VerifySecretWritable(s.Namespace, s.Name)
+}
+
+func (s SecretKeyCustodian) UnsealKeys() ([]string, error) {
+	secret, err := kube.GetSecret(s.Namespace, s.Name)
+	if k8serr.IsNotFound(err) {
+		return nil, UnsealKeysUnavailableError
+	} else if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range secret.Data {
+		if strings.HasPrefix(name, "key-") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var keys []string
+	for _, name := range names {
+		keys = append(keys, string(secret.Data[name]))
+	}
+	if len(keys) == 0 {
+		if key, ok := secret.Data["key"]; ok {
+			keys = append(keys, string(key))
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, UnsealKeysUnavailableError
+	}
+	return keys, nil
+}
+
+// SealedFileKeyCustodian encrypts key shares to a set of age or PGP public keys and stores the sealed file
+// inside a Kubernetes secret. The operator is unable to decrypt the file, so Vault must be unsealed manually.
+type SealedFileKeyCustodian struct {
+	Namespace  string
+	Name       string
+	Encryption managementv1.SealedFileEncryption
+	PublicKeys []string
+}
+
+func (s SealedFileKeyCustodian) StoreKeys(keys []string) error {
+	var buf bytes.Buffer
+	filename, err := s.seal(&buf, []byte(strings.Join(keys, "\n")+"\n"))
+	if err != nil {
+		return errors.Wrap(err, "Failed to seal Vault keys")
+	}
+
+	if err := kube.CreateOrUpdateSecret(s.Namespace, s.Name, map[string]string{filename: buf.String()}); err != nil {
+		return errors.Wrap(err, "Failed to create Vault sealed keys secret")
+	}
+	return nil
+}
+
+// Verify parses the public keys by sealing an empty file to them, and checks that the sealed file can be stored
+func (s SealedFileKeyCustodian) Verify() error {
+	if _, err := s.seal(io.Discard, nil); err != nil {
+		return errors.Wrap(err, "Invalid public keys of the sealed file key custody")
+	}
+	return kube.VerifySecretWritable(s.Namespace, s.Name)
+}
+
+// seal encrypts the plaintext to the public keys, and returns the name of the sealed file
+func (s SealedFileKeyCustodian) seal(out io.Writer, plaintext []byte) (string, error) {
+	switch s.Encryption {
+	case "", managementv1.SealedFileEncryptionAge:
+		return "vault-keys.age", s.sealAge(out, plaintext)
+	case managementv1.SealedFileEncryptionPGP:
+		return "vault-keys.asc", s.sealPGP(out, plaintext)
+	}
+	return "", errors.Errorf("unknown sealed file encryption %s", s.Encryption)
+}
+
+func (s SealedFileKeyCustodian) UnsealKeys() ([]string, error) {
+	return nil, UnsealKeysUnavailableError
+}
+
+func (s SealedFileKeyCustodian) sealAge(out io.Writer, plaintext []byte) error {
+	recipients, err := age.ParseRecipients(strings.NewReader(strings.Join(s.PublicKeys, "\n")))
+	if err != nil {
+		return err
+	}
+
+	armorWriter := armor.NewWriter(out)
+	writer, err := age.Encrypt(armorWriter, recipients...)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(plaintext); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return armorWriter.Close()
+}
+
+func (s SealedFileKeyCustodian) sealPGP(out io.Writer, plaintext []byte) error {
+	var entities openpgp.EntityList
+	for _, key := range s.PublicKeys {
+		keyRing, err := openpgp.ReadArmoredKeyRing(strings.NewReader(key))
+		if err != nil {
+			return err
+		}
+		entities = append(entities, keyRing...)
+	}
+
+	armorWriter, err := pgparmor.Encode(out, "PGP MESSAGE", nil)
+	if err != nil {
+		return err
+	}
+	writer, err := openpgp.Encrypt(armorWriter, entities, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(plaintext); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return armorWriter.Close()
+}
+
+// TransitKeyCustodian is used when Vault is sealed through the transit engine of another Vault server. Vault
+// unseals itself in this mode, so only the recovery keys produced by the initialization are stored.
+type TransitKeyCustodian struct {
+	RecoveryKeys KeyCustodian
+}
+
+func (t TransitKeyCustodian) StoreKeys(keys []string) error {
+	return t.RecoveryKeys.StoreKeys(keys)
+}
+
+func (t TransitKeyCustodian) Verify() error {
+	return t.RecoveryKeys.Verify()
+}
+
+func (t TransitKeyCustodian) UnsealKeys() ([]string, error) {
+	return nil, nil
+}
@@ -0,0 +1,125 @@
+package vault
+
+import (
+	"bytes"
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+	"golang.org/x/crypto/openpgp"
+	pgparmor "golang.org/x/crypto/openpgp/armor"
+	"io"
+	"strings"
+	"testing"
+)
+
+const sealedKeys = "key-0\nkey-1\nkey-2\n"
+
+func TestSealAge(t *testing.T) {
+	first, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	custodian := SealedFileKeyCustodian{PublicKeys: []string{first.Recipient().String(), second.Recipient().String()}}
+
+	var sealed bytes.Buffer
+	filename, err := custodian.seal(&sealed, []byte(sealedKeys))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filename != "vault-keys.age" {
+		t.Errorf("seal returned the file name %s, expected vault-keys.age", filename)
+	}
+
+	// Each recipient can decrypt the sealed file on its own
+	for _, identity := range []*age.X25519Identity{first, second} {
+		reader, err := age.Decrypt(armor.NewReader(bytes.NewReader(sealed.Bytes())), identity)
+		if err != nil {
+			t.Fatal(err)
+		}
+		plaintext, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(plaintext) != sealedKeys {
+			t.Errorf("Decrypted %q, expected %q", plaintext, sealedKeys)
+		}
+	}
+
+	other, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := age.Decrypt(armor.NewReader(bytes.NewReader(sealed.Bytes())), other); err == nil {
+		t.Error("The sealed file was decrypted by an identity which is not a recipient")
+	}
+}
+
+func TestSealPGP(t *testing.T) {
+	entity, err := openpgp.NewEntity("Vault Operator", "", "ops@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var publicKey bytes.Buffer
+	writer, err := pgparmor.Encode(&publicKey, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.Serialize(writer); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	custodian := SealedFileKeyCustodian{Encryption: managementv1.SealedFileEncryptionPGP, PublicKeys: []string{publicKey.String()}}
+
+	var sealed bytes.Buffer
+	filename, err := custodian.seal(&sealed, []byte(sealedKeys))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filename != "vault-keys.asc" {
+		t.Errorf("seal returned the file name %s, expected vault-keys.asc", filename)
+	}
+
+	block, err := pgparmor.Decode(bytes.NewReader(sealed.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := openpgp.ReadMessage(block.Body, openpgp.EntityList{entity}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := io.ReadAll(message.UnverifiedBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != sealedKeys {
+		t.Errorf("Decrypted %q, expected %q", plaintext, sealedKeys)
+	}
+}
+
+func TestVerifyRejectsInvalidPublicKeys(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, custodian := range map[string]SealedFileKeyCustodian{
+		"malformed age key": {PublicKeys: []string{"age1invalid"}},
+		"valid and malformed age keys": {
+			PublicKeys: []string{identity.Recipient().String(), "ssh-rsa AAAA"},
+		},
+		"age key as PGP key": {
+			Encryption: managementv1.SealedFileEncryptionPGP,
+			PublicKeys: []string{identity.Recipient().String()},
+		},
+		"unknown encryption": {Encryption: "rot13", PublicKeys: []string{identity.Recipient().String()}},
+	} {
+		if err := custodian.Verify(); err == nil || !strings.Contains(err.Error(), "Invalid public keys") {
+			t.Errorf("Verify accepted the %s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/vault/api"
+	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
+	"github.com/pkg/errors"
+	"path"
+	"strings"
+)
+
+// TenantPolicyName is the name of the policy granted to the workloads of every tenant
+const TenantPolicyName = "modela-tenant"
+
+// TenantRolePrefix is the prefix of the names of the Kubernetes auth roles of the tenants
+const TenantRolePrefix = "modela-tenant-"
+
+// TenantPolicyTemplate grants the workloads of a tenant access to the secrets of the tenant. The path of the tenant
+// is taken from the namespace of the service account which logged in through the Kubernetes auth method, whose
+// mount accessor is the second argument.
+const TenantPolicyTemplate = `
+path "%[1]s/data/tenant/{{identity.entity.aliases.%[2]s.metadata.service_account_namespace}}/*" {
+  capabilities = ["create", "read", "update", "patch", "delete", "list"]
+}
+
+path "%[1]s/metadata/tenant/{{identity.entity.aliases.%[2]s.metadata.service_account_namespace}}/*" {
+  capabilities = ["read", "list"]
+}
+`
+
+// TenantRoleName returns the name of the Kubernetes auth role of a tenant
+func TenantRoleName(tenant string) string {
+	return TenantRolePrefix + tenant
+}
+
+// ApplyTenantRole creates the Kubernetes auth role of a tenant, which grants the tenant policy to the service
+// accounts of the tenant namespace
+func ApplyTenantRole(modela *managementv1.Modela, tenant string) error {
+	client, err := GetAuthenticatedClient(modela)
+	if err != nil {
+		return errors.Wrapf(err, "failed to apply vault role for tenant %s", tenant)
+	}
+
+	if _, err := client.Logical().Write("/auth/kubernetes/role/"+TenantRoleName(tenant), map[string]interface{}{
+		"bound_service_account_names":      []string{"*"},
+		"bound_service_account_namespaces": []string{tenant},
+		"token_policies":                   []string{TenantPolicyName},
+	}); err != nil {
+		return errors.Wrapf(err, "failed to apply vault role for tenant %s", tenant)
+	}
+
+	return nil
+}
+
+// DeleteTenantRole revokes the Kubernetes auth role of a tenant. The secrets of the tenant are retained, and
+// ApplyTenantRole restores the role.
+func DeleteTenantRole(modela *managementv1.Modela, tenant string) error {
+	client, err := GetAuthenticatedClient(modela)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete vault role for tenant %s", tenant)
+	}
+
+	if _, err := client.Logical().Delete("/auth/kubernetes/role/" + TenantRoleName(tenant)); err != nil {
+		return errors.Wrapf(err, "failed to delete vault role for tenant %s", tenant)
+	}
+
+	return nil
+}
+
+// DestroyTenantSecrets permanently deletes every version of every secret stored under the path of a tenant
+func DestroyTenantSecrets(modela *managementv1.Modela, tenant string) error {
+	client, err := GetAuthenticatedClient(modela)
+	if err != nil {
+		return errors.Wrapf(err, "failed to destroy vault secrets for tenant %s", tenant)
+	}
+
+	mount := strings.Trim(modela.Spec.Vault.MountPath, "/")
+	if err := destroySecrets(client, mount, fmt.Sprintf("tenant/%s/", tenant)); err != nil {
+		return errors.Wrapf(err, "failed to destroy vault secrets for tenant %s", tenant)
+	}
+
+	return nil
+}
+
+func destroySecrets(client *api.Client, mount string, prefix string) error {
+	secret, err := client.Logical().List(path.Join(mount, "metadata", prefix))
+	if err != nil {
+		return err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil
+	}
+
+	keys, _ := secret.Data["keys"].([]interface{})
+	for _, k := range keys {
+		key := prefix + fmt.Sprint(k)
+		if strings.HasSuffix(key, "/") {
+			if err := destroySecrets(client, mount, key); err != nil {
+				return err
+			}
+		} else if err := client.KVv2(mount).DeleteMetadata(context.Background(), key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -2,17 +2,58 @@ package vault
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
 	"fmt"
 	"github.com/hashicorp/vault/api"
 	"github.com/hashicorp/vault/api/auth/kubernetes"
 	managementv1 "github.com/metaprov/modela-operator/api/v1alpha1"
 	"github.com/metaprov/modela-operator/pkg/kube"
 	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+	v1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"net/http"
+	"sync"
+	"time"
 )
 
+const (
+	// ServiceHost is the host of the Vault service installed by the Modela Operator
+	ServiceHost = "modela-vault.modela-system.svc.cluster.local:8200"
+
+	// TLSSecretName is the name of the Secret which contains the certificate issued to the Vault service installed
+	// by the Modela Operator
+	TLSSecretName = "modela-vault-tls"
+)
+
+// Address returns the address of the Vault server used by a Modela installation
+func Address(modela *managementv1.Modela) string {
+	if modela.Spec.Vault.VaultAddress != nil && *modela.Spec.Vault.VaultAddress != "" {
+		return *modela.Spec.Vault.VaultAddress
+	}
+
+	if modela.Spec.Vault.TLS.Enabled {
+		return "https://" + ServiceHost
+	}
+	return "http://" + ServiceHost
+}
+
+// GetUnauthenticatedClientInCluster returns a client for the Vault service installed by the Modela Operator. If the
+// certificate of the service has been issued, the client connects over TLS and verifies the server with its CA.
 func GetUnauthenticatedClientInCluster() (*api.Client, error) {
 	config := api.DefaultConfig()
-	config.Address = "http://modela-vault.modela-system.svc.cluster.local:8200"
+	config.Address = "http://" + ServiceHost
+	if secret, err := kube.GetSecret("modela-system", TLSSecretName); err == nil {
+		config.Address = "https://" + ServiceHost
+		if err := config.ConfigureTLS(&api.TLSConfig{CACertBytes: secret.Data["ca.crt"]}); err != nil {
+			return nil, err
+		}
+	} else if !k8serr.IsNotFound(err) {
+		return nil, err
+	}
+
 	client, err := api.NewClient(config)
 	if err != nil {
 		return nil, err
@@ -27,63 +68,278 @@ func GetUnauthenticatedClientInCluster() (*api.Client, error) {
 }
 
 func GetUnauthenticatedClient(modela *managementv1.Modela) (*api.Client, error) {
-	var address string
-	if modela.Spec.Vault.VaultAddress == nil || *modela.Spec.Vault.VaultAddress == "" {
-		address = "http://modela-vault.modela-system.svc.cluster.local:8200"
-	} else {
-		address = *modela.Spec.Vault.VaultAddress
+	client, err := newClient(modela, Address(modela))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = client.Sys().Health()
+	if err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// GetUnauthenticatedServerClients returns a client for each server of the Vault used by a Modela installation,
+// keyed by the name of the server. When Vault is installed by the Modela Operator, each pod of the Vault stateful
+// set is addressed individually through the internal headless service, as every server of an HA deployment must
+// be unsealed separately. External Vault servers are addressed through the configured address only.
+func GetUnauthenticatedServerClients(modela *managementv1.Modela) (map[string]*api.Client, error) {
+	address := Address(modela)
+	if modela.Spec.Vault.VaultAddress != nil && *modela.Spec.Vault.VaultAddress != "" {
+		client, err := newClient(modela, address)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]*api.Client{address: client}, nil
+	}
+
+	pods, err := kube.ListPods("modela-system", "app.kubernetes.io/instance=modela-vault,component=server")
+	if err != nil {
+		return nil, err
 	}
 
+	scheme := "http://"
+	if modela.Spec.Vault.TLS.Enabled {
+		scheme = "https://"
+	}
+
+	clients := make(map[string]*api.Client)
+	for _, pod := range pods {
+		if pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+		client, err := newClient(modela, fmt.Sprintf("%s%s.modela-vault-internal.modela-system.svc:8200", scheme, pod.Name))
+		if err != nil {
+			return nil, err
+		}
+		clients[pod.Name] = client
+	}
+
+	return clients, nil
+}
+
+// newClient creates a client for an address, configured with the TLS settings and namespace of a Modela installation
+func newClient(modela *managementv1.Modela, address string) (*api.Client, error) {
 	config := api.DefaultConfig()
 	config.Address = address
-	client, err := api.NewClient(config)
+
+	tlsConfig, clientCert, err := tlsSettings(modela)
 	if err != nil {
 		return nil, err
 	}
+	if err := config.ConfigureTLS(tlsConfig); err != nil {
+		return nil, errors.Wrap(err, "Failed to configure Vault TLS")
+	}
+	if clientCert != nil {
+		transport := config.HttpClient.Transport.(*http.Transport)
+		transport.TLSClientConfig.Certificates = []tls.Certificate{*clientCert}
+	}
 
-	_, err = client.Sys().Health()
+	client, err := api.NewClient(config)
 	if err != nil {
 		return nil, err
 	}
 
+	if modela.Spec.Vault.Namespace != "" {
+		client.SetNamespace(modela.Spec.Vault.Namespace)
+	}
+
 	return client, nil
 }
 
+// CASecretRef returns the key of the Secret in the modela-system namespace which holds the CA bundle of Vault, or nil
+// if the certificate of Vault is verified against the system roots. The CA of the certificate issued to the bundled
+// Vault is used when none is referenced.
+func CASecretRef(modela *managementv1.Modela) *v1.SecretKeySelector {
+	spec := modela.Spec.Vault.TLS
+	if spec.CASecretRef == nil && spec.Enabled && modela.Spec.Vault.Install &&
+		(modela.Spec.Vault.VaultAddress == nil || *modela.Spec.Vault.VaultAddress == "") {
+		return &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: TLSSecretName}, Key: "ca.crt"}
+	}
+	return spec.CASecretRef
+}
+
+// tlsSettings loads the CA bundle and client certificate referenced by the TLS configuration of a Modela installation
+func tlsSettings(modela *managementv1.Modela) (*api.TLSConfig, *tls.Certificate, error) {
+	spec := modela.Spec.Vault.TLS
+	tlsConfig := &api.TLSConfig{TLSServerName: spec.ServerName, Insecure: spec.InsecureSkipVerify}
+
+	if caRef := CASecretRef(modela); caRef != nil {
+		secret, err := kube.GetSecret("modela-system", caRef.Name)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "Failed to get Vault CA secret %s", caRef.Name)
+		}
+		ca, ok := secret.Data[caRef.Key]
+		if !ok {
+			return nil, nil, errors.Errorf("Vault CA secret %s has no key %s", caRef.Name, caRef.Key)
+		}
+		tlsConfig.CACertBytes = ca
+	}
+
+	if spec.ClientCertificateSecretRef == nil {
+		return tlsConfig, nil, nil
+	}
+
+	secret, err := kube.GetSecret("modela-system", spec.ClientCertificateSecretRef.Name)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Failed to get Vault client certificate secret %s", spec.ClientCertificateSecretRef.Name)
+	}
+	clientCert, err := tls.X509KeyPair(secret.Data[v1.TLSCertKey], secret.Data[v1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Failed to load Vault client certificate %s", spec.ClientCertificateSecretRef.Name)
+	}
+
+	return tlsConfig, &clientCert, nil
+}
+
+// cacheKey identifies the cached client of a Modela installation. It changes whenever the address, namespace or
+// TLS material used to reach Vault changes, so that rotated certificates are picked up by a new client.
+func cacheKey(modela *managementv1.Modela) (string, error) {
+	tlsConfig, clientCert, err := tlsSettings(modela)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	hash.Write(tlsConfig.CACertBytes)
+	if clientCert != nil {
+		for _, cert := range clientCert.Certificate {
+			hash.Write(cert)
+		}
+	}
+	return fmt.Sprintf("%s|%s|%s|%t|%x", Address(modela), modela.Spec.Vault.Namespace, tlsConfig.TLSServerName,
+		tlsConfig.Insecure, hash.Sum(nil)), nil
+}
+
+// OperatorRole is the Kubernetes auth role used by the Modela Operator to authenticate with Vault
+const OperatorRole = "modela-operator"
+
+// cachedClient is a client authenticated through the Kubernetes auth method, whose token is renewed in the
+// background until it reaches its maximum TTL
+type cachedClient struct {
+	client  *api.Client
+	watcher *api.LifetimeWatcher
+}
+
+// loginTimeout bounds the login of the operator, so that an unresponsive Vault does not block its callers
+const loginTimeout = 30 * time.Second
+
+var (
+	clientCacheLock sync.Mutex
+	clientCache     = make(map[string]*cachedClient)
+	// clientLogins shares a single login between the concurrent callers of each cache key
+	clientLogins singleflight.Group
+)
+
 func GetAuthenticatedClient(modela *managementv1.Modela) (*api.Client, error) {
-	var address string
-	if modela.Spec.Vault.VaultAddress == nil || *modela.Spec.Vault.VaultAddress == "" {
-		address = "http://modela-vault.modela-system.svc.cluster.local:8200"
-	} else {
-		address = *modela.Spec.Vault.VaultAddress
+	key, err := cacheKey(modela)
+	if err != nil {
+		return nil, err
 	}
 
-	config := api.DefaultConfig()
-	config.Address = address
-	client, err := api.NewClient(config)
+	clientCacheLock.Lock()
+	cached, ok := clientCache[key]
+	clientCacheLock.Unlock()
+	if ok {
+		return cached.client, nil
+	}
+
+	client, err, _ := clientLogins.Do(key, func() (interface{}, error) {
+		return login(modela, key)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return client.(*api.Client), nil
+}
+
+// login authenticates a new client with the role of the operator and caches it. The cache lock is only held once
+// the client has logged in.
+func login(modela *managementv1.Modela, key string) (*api.Client, error) {
+	clientCacheLock.Lock()
+	cached, ok := clientCache[key]
+	clientCacheLock.Unlock()
+	if ok {
+		return cached.client, nil
+	}
+
+	client, err := newClient(modela, Address(modela))
 	if err != nil {
 		return nil, err
 	}
 
-	auth, err := kubernetes.NewKubernetesAuth("modela")
+	auth, err := kubernetes.NewKubernetesAuth(OperatorRole)
 	if err != nil {
 		return nil, err
 	}
 
-	// Skip Kubernetes authentication if we already have a root token on our cluster
-	if exists, err := kube.IsNamespaceCreated("modela-system"); exists && err == nil {
-		if secret, err := kube.GetSecret("modela-system", "vault-root-token"); err == nil {
-			if token, ok := secret.Data["token"]; ok {
-				client.SetToken(string(token))
-				return client, nil
+	ctx, cancel := context.WithTimeout(context.Background(), loginTimeout)
+	defer cancel()
+	secret, err := client.Auth().Login(ctx, auth)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to log in to Vault with role %s", OperatorRole)
+	}
+
+	cached = &cachedClient{client: client}
+	if secret != nil && secret.Auth != nil && secret.Auth.Renewable {
+		watcher, err := client.NewLifetimeWatcher(&api.LifetimeWatcherInput{Secret: secret})
+		if err != nil {
+			return nil, err
+		}
+		cached.watcher = watcher
+	}
+
+	clientCacheLock.Lock()
+	clientCache[key] = cached
+	clientCacheLock.Unlock()
+	if cached.watcher != nil {
+		go cached.watcher.Start()
+		go watchToken(key, cached)
+	}
+	return client, nil
+}
+
+// watchToken evicts a cached client once its token can no longer be renewed, so the next caller logs in again
+func watchToken(key string, cached *cachedClient) {
+	for {
+		select {
+		case err := <-cached.watcher.DoneCh():
+			if err != nil {
+				klog.ErrorS(err, "Failed to renew Vault token")
 			}
+			evictClient(key, cached)
+			return
+		case <-cached.watcher.RenewCh():
 		}
 	}
+}
 
-	if _, err := client.Auth().Login(context.Background(), auth); err != nil {
-		return nil, err
+// evictClient removes a client from the cache and stops the renewal of its token
+func evictClient(key string, cached *cachedClient) {
+	clientCacheLock.Lock()
+	defer clientCacheLock.Unlock()
+	if clientCache[key] == cached {
+		delete(clientCache, key)
+	}
+	if cached.watcher != nil {
+		cached.watcher.Stop()
 	}
+}
 
-	return client, nil
+// InvalidateAuthenticatedClient discards a cached client, forcing the next caller to log in again
+func InvalidateAuthenticatedClient(client *api.Client) {
+	clientCacheLock.Lock()
+	defer clientCacheLock.Unlock()
+	for key, cached := range clientCache {
+		if cached.client == client {
+			delete(clientCache, key)
+			if cached.watcher != nil {
+				cached.watcher.Stop()
+			}
+		}
+	}
 }
 
 func ApplySecret(modela *managementv1.Modela, key string, value map[string]interface{}) error {
@@ -94,8 +350,25 @@ func ApplySecret(modela *managementv1.Modela, key string, value map[string]inter
 
 	kv := client.KVv2(modela.Spec.Vault.MountPath)
 	if _, err = kv.Put(context.Background(), key, value); err != nil {
+		// The token may have been revoked; log in again on the next attempt
+		InvalidateAuthenticatedClient(client)
 		return errors.Wrap(err, fmt.Sprintf("failed to apply vault secret %s", key))
 	}
 
 	return nil
 }
+
+// DeleteSecret permanently deletes all versions of a secret
+func DeleteSecret(modela *managementv1.Modela, key string) error {
+	client, err := GetAuthenticatedClient(modela)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to delete vault secret %s", key))
+	}
+
+	if err := client.KVv2(modela.Spec.Vault.MountPath).DeleteMetadata(context.Background(), key); err != nil {
+		InvalidateAuthenticatedClient(client)
+		return errors.Wrap(err, fmt.Sprintf("failed to delete vault secret %s", key))
+	}
+
+	return nil
+}